@@ -8,8 +8,10 @@ import (
 	"github.com/go-dev-frame/sponge/pkg/logger"
 	"github.com/go-dev-frame/sponge/pkg/tracer"
 
+	"admin/internal/cache"
 	"admin/internal/config"
 	"admin/internal/database"
+	"admin/internal/task"
 )
 
 // Close releasing resources after service exit
@@ -21,13 +23,19 @@ func Close(servers []app.IServer) []app.Close {
 		closes = append(closes, s.Stop)
 	}
 
+	// stop scheduled tasks
+	closes = append(closes, task.Stop)
+
 	// close database
 	closes = append(closes, func() error {
 		return database.CloseDB()
 	})
 
+	// stop the cache invalidation bus
+	closes = append(closes, cache.CloseInvalidationBus)
+
 	// close redis
-	if config.Get().App.CacheType == "redis" {
+	if cacheType := config.Get().App.CacheType; cacheType == "redis" || cacheType == "tiered" || config.Get().App.CacheInvalidation {
 		closes = append(closes, func() error {
 			return database.CloseRedis()
 		})
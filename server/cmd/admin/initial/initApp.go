@@ -4,6 +4,7 @@
 package initial
 
 import (
+	"context"
 	"flag"
 	"strconv"
 
@@ -12,8 +13,12 @@ import (
 	"github.com/go-dev-frame/sponge/pkg/tracer"
 
 	"admin/configs"
+	"admin/internal/cache"
 	"admin/internal/config"
+	"admin/internal/dao"
 	"admin/internal/database"
+	"admin/internal/pkg/menumanifest"
+	"admin/internal/task"
 )
 
 var (
@@ -42,7 +47,7 @@ func InitApp() {
 	if err != nil {
 		panic(err)
 	}
-	logger.Debug(config.Show())
+	logger.Debug(config.Show(`"signKey"`, `"secretKey"`))
 	logger.Info("[logger] was initialized")
 
 	// initializing tracing
@@ -75,6 +80,45 @@ func InitApp() {
 	if cfg.App.CacheType != "" {
 		logger.Infof("[%s] was initialized", cfg.App.CacheType)
 	}
+	// the bus keeps the memory caches of the instances coherent, e.g. the local tier of the tiered cache and the enums
+	if cache.InvalidationEnabled(cfg.App.CacheType, cfg.App.CacheInvalidation) {
+		bus := cache.NewInvalidationBus(database.GetRedisCli(), cache.InvalidationChannel)
+		bus.Run()
+		cache.SetInvalidationBus(bus)
+		logger.Info("[cache invalidation bus] was initialized")
+	}
+	// the database dictionaries are merged into the code enums
+	cache.SetEnumDictLoader(dao.NewDictDao(database.GetDB()).GetOptions)
+	if cfg.App.SyncMenus {
+		syncMenus()
+	}
+	database.InitStorage()
+	logger.Infof("[%s storage] was initialized", cfg.Storage.Type)
+	// refuse to start with a missing or weak key rather than failing at the first download
+	if _, err = database.GetSignKey(); err != nil {
+		panic(err.Error())
+	}
+
+	// scheduled tasks
+	if err := task.Run(); err != nil {
+		panic("task.Run error: " + err.Error())
+	}
+	logger.Info("[scheduled tasks] were started")
+}
+
+// syncMenus upsert the menus shipped with the binary
+func syncMenus() {
+	manifest, err := menumanifest.Embedded()
+	if err != nil {
+		panic("menumanifest.Embedded error: " + err.Error())
+	}
+	iDao := dao.NewMenuDao(database.GetDB(), cache.NewMenuCache(database.GetCacheType()))
+	report, err := iDao.Import(context.Background(), manifest, false)
+	if err != nil {
+		panic("sync menus error: " + err.Error())
+	}
+	logger.Info("[menus] were synced", logger.Int("created", len(report.Created)),
+		logger.Int("updated", len(report.Updated)), logger.Int("unchanged", report.Unchanged))
 }
 
 func initConfig() {
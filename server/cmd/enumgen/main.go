@@ -0,0 +1,69 @@
+// Package main generates enum.json and the typescript enums of the frontend from internal/constant/enum,
+// it is run by go generate in internal/constant/enum:
+//
+//	go generate ./internal/constant/enum
+//
+// it exits with 1 if a constant has no label comment. with -embed the json is also written into the enum
+// package, build with -tags enumembed to embed it instead of reading enum.json from the working directory.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"admin/internal/pkg/enumgen"
+	"admin/internal/pkg/util"
+)
+
+func main() {
+	dir := flag.String("dir", "internal/constant/enum", "directory of the enum files")
+	jsonFile := flag.String("json", "enum.json", "output json file, empty to skip")
+	tsFile := flag.String("ts", "", "output typescript file, empty to skip")
+	embed := flag.Bool("embed", false, "also write the json into the enum directory for go:embed")
+	flag.Parse()
+
+	if err := run(*dir, *jsonFile, *tsFile, *embed); err != nil {
+		fmt.Fprintln(os.Stderr, "enumgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, jsonFile, tsFile string, embed bool) error {
+	if _, err := os.Stat(dir); err != nil {
+		return err
+	}
+	enums := enumgen.Enums(util.EnumChangeDict(dir))
+	if len(enums) == 0 {
+		return fmt.Errorf("no enum is found in %s", dir)
+	}
+	if err := enums.Validate(); err != nil {
+		return err
+	}
+
+	data, err := enums.JSON()
+	if err != nil {
+		return err
+	}
+	if jsonFile != "" {
+		if err = enumgen.WriteFile(jsonFile, data); err != nil {
+			return err
+		}
+	}
+	if embed {
+		if err = enumgen.WriteFile(filepath.Join(dir, "enum.json"), data); err != nil {
+			return err
+		}
+	}
+	if tsFile != "" {
+		ts, err := enums.TypeScript("server/internal/constant/enum")
+		if err != nil {
+			return err
+		}
+		if err = enumgen.WriteFile(tsFile, ts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Package main lists every route with its auth mode and the perm it requires, the same report as
+// GET /api/v1/permission/routes. the routes are built from the configuration and the perms of the
+// menus are read from the database:
+//
+//	go run ./cmd/routeperm -c configs/admin.yml
+//
+// with -strict it exits with 1 if a route is not declared in the routers or a declared rule has no route.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/gin-gonic/gin"
+
+	"admin/configs"
+	"admin/internal/config"
+	"admin/internal/dao"
+	"admin/internal/database"
+	"admin/internal/pkg/routeperm"
+	"admin/internal/routers"
+	"admin/internal/types"
+)
+
+func main() {
+	configFile := flag.String("c", configs.Path("admin.yml"), "configuration file")
+	format := flag.String("format", "text", "output format, text or json")
+	strict := flag.Bool("strict", false, "exit with 1 if a route is not declared or a declared rule has no route")
+	flag.Parse()
+
+	report, err := run(*configFile)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "routeperm:", err)
+		os.Exit(1)
+	}
+	if *format == "json" {
+		err = writeJSON(os.Stdout, report)
+	} else {
+		err = writeText(os.Stdout, report)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "routeperm:", err)
+		os.Exit(1)
+	}
+	if *strict && (len(report.Undeclared) > 0 || len(report.StaleRules) > 0) {
+		os.Exit(1)
+	}
+}
+
+func run(configFile string) (*types.RoutePermReport, error) {
+	if err := config.Init(configFile); err != nil {
+		return nil, err
+	}
+	// only the routes are built, the cache is not used
+	database.InitCache("")
+	gin.SetMode(gin.ReleaseMode)
+	r := routers.NewRouter()
+	defer func() { _ = database.CloseDB() }()
+
+	perms, err := dao.NewMenuDao(database.GetDB(), nil).Perms(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	return routeperm.Report(r.Routes(), perms), nil
+}
+
+func writeJSON(w io.Writer, report *types.RoutePermReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+func writeText(w io.Writer, report *types.RoutePermReport) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "METHOD\tPATH\tAUTH\tPERM\t")
+	for _, item := range report.Routes {
+		auth, perm := item.Auth, item.Perm
+		if auth == "" {
+			auth = "undeclared"
+		}
+		if perm != "" && !item.PermInMenus {
+			perm += " (no menu)"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", item.Method, item.Path, auth, perm)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	writeItems(w, "routes without a perm", report.WithoutPerm)
+	writeItems(w, "undeclared routes", report.Undeclared)
+	writeItems(w, "declared rules without a route", report.StaleRules)
+	writePerms(w, "perms required by the routes but not in the menus", report.MissingPerms)
+	writePerms(w, "perms of the menus not required by any route", report.UnusedPerms)
+	return nil
+}
+
+func writeItems(w io.Writer, title string, items []*types.RoutePermItem) {
+	fmt.Fprintf(w, "\n%s: %d\n", title, len(items))
+	for _, item := range items {
+		fmt.Fprintf(w, "  %s %s\n", item.Method, item.Path)
+	}
+}
+
+func writePerms(w io.Writer, title string, perms []string) {
+	fmt.Fprintf(w, "\n%s: %d\n", title, len(perms))
+	for _, perm := range perms {
+		fmt.Fprintf(w, "  %s\n", perm)
+	}
+}
@@ -0,0 +1,268 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"admin/internal/database"
+	"admin/internal/pkg/confval"
+	"admin/internal/types"
+)
+
+// cache namespaces which can be administrated
+const (
+	NamespacePlatform = "platform"
+	NamespaceRole     = "role"
+	NamespaceMenu     = "menu"
+	NamespaceMenuTree = "menuTree"
+	NamespaceRoleMenu = "roleMenu"
+	NamespaceConfig   = "config"
+	NamespaceEnum     = "enum"
+)
+
+// Namespace a group of cache keys sharing a prefix
+type Namespace struct {
+	Name       string
+	Prefix     string
+	MemoryOnly bool // kept in memory whatever the cache type is
+
+	redact func(value interface{}) // mask the sensitive fields of the decoded json values before they are shown
+}
+
+// Namespaces all the namespaces of the cache administration api
+var Namespaces = []*Namespace{
+	{Name: NamespacePlatform, Prefix: platformCachePrefixKey, redact: redactFields("password")},
+	{Name: NamespaceRole, Prefix: roleCachePrefixKey},
+	{Name: NamespaceMenu, Prefix: menuCachePrefixKey},
+	{Name: NamespaceMenuTree, Prefix: menuTreeCachePrefixKey},
+	{Name: NamespaceRoleMenu, Prefix: roleMenuCachePrefixKey},
+	{Name: NamespaceConfig, Prefix: configCachePrefixKey, redact: redactSecretConfig},
+	{Name: NamespaceEnum, Prefix: enumCachePrefixKey, MemoryOnly: true},
+}
+
+// GetNamespace get a namespace by name
+func GetNamespace(name string) (*Namespace, bool) {
+	for _, ns := range Namespaces {
+		if ns.Name == name {
+			return ns, true
+		}
+	}
+	return nil, false
+}
+
+// KeyInspector reads and deletes the raw keys of the cache for the cache administration api
+type KeyInspector interface {
+	// Listable whether the keys can be listed, the memory cache can not
+	Listable() bool
+	// Scan count the keys with the prefix and return at most limit of them as samples
+	Scan(ctx context.Context, prefix string, limit int) (int64, []*types.CacheEntry, error)
+	// Get a key, database.ErrCacheNotFound if it does not exist
+	Get(ctx context.Context, key string) (*types.CacheEntry, error)
+	// Del delete keys, the other instances are told to evict them too
+	Del(ctx context.Context, keys ...string) error
+	// DelPrefix delete all keys with the prefix, the memory cache is cleared completely because it can not be listed
+	DelPrefix(ctx context.Context, prefix string) (int64, error)
+}
+
+// NewKeyInspector new an inspector of the keys of the namespace, the sensitive fields of the values are masked
+func NewKeyInspector(cacheType *database.CacheType, ns *Namespace) KeyInspector {
+	cType := strings.ToLower(cacheType.CType)
+	if !ns.MemoryOnly && (cType == "redis" || cType == "tiered") {
+		return &redisInspector{cacheType: cacheType, redact: ns.redact}
+	}
+	return &memoryInspector{redact: ns.redact}
+}
+
+type redisInspector struct {
+	cacheType *database.CacheType
+	redact    func(value interface{})
+}
+
+func (r *redisInspector) Listable() bool {
+	return true
+}
+
+func (r *redisInspector) Scan(ctx context.Context, prefix string, limit int) (int64, []*types.CacheEntry, error) {
+	var count int64
+	var samples []*types.CacheEntry
+	iter := r.cacheType.Rdb.Scan(ctx, 0, prefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		count++
+		if len(samples) < limit {
+			entry, err := r.Get(ctx, iter.Val())
+			if err == nil {
+				samples = append(samples, entry)
+			}
+		}
+	}
+	return count, samples, iter.Err()
+}
+
+func (r *redisInspector) Get(ctx context.Context, key string) (*types.CacheEntry, error) {
+	data, err := r.cacheType.Rdb.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	ttl, _ := r.cacheType.Rdb.TTL(ctx, key).Result()
+	return newCacheEntry(key, data, ttl, r.redact), nil
+}
+
+func (r *redisInspector) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := r.cacheType.Rdb.Del(ctx, keys...).Err(); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		tieredL1.del(key)
+	}
+	publishInvalidation(ctx, keys...)
+	return nil
+}
+
+func (r *redisInspector) DelPrefix(ctx context.Context, prefix string) (int64, error) {
+	var deleted int64
+	var keys []string
+	iter := r.cacheType.Rdb.Scan(ctx, 0, prefix+"*", 1000).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) >= 1000 {
+			if err := r.Del(ctx, keys...); err != nil {
+				return deleted, err
+			}
+			deleted += int64(len(keys))
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, err
+	}
+	if err := r.Del(ctx, keys...); err != nil {
+		return deleted, err
+	}
+	return deleted + int64(len(keys)), nil
+}
+
+type memoryInspector struct {
+	redact func(value interface{})
+}
+
+func (m *memoryInspector) Listable() bool {
+	return false
+}
+
+func (m *memoryInspector) Scan(_ context.Context, _ string, _ int) (int64, []*types.CacheEntry, error) {
+	return -1, nil, nil
+}
+
+func (m *memoryInspector) Get(_ context.Context, key string) (*types.CacheEntry, error) {
+	client := cache.GetGlobalMemoryCli()
+	data, ok := client.Get(key)
+	if !ok {
+		return nil, database.ErrCacheNotFound
+	}
+	dataBytes, _ := data.([]byte)
+	ttl, _ := client.GetTTL(key)
+	return newCacheEntry(key, dataBytes, ttl, m.redact), nil
+}
+
+func (m *memoryInspector) Del(ctx context.Context, keys ...string) error {
+	client := cache.GetGlobalMemoryCli()
+	for _, key := range keys {
+		client.Del(key)
+	}
+	publishInvalidation(ctx, keys...)
+	return nil
+}
+
+func (m *memoryInspector) DelPrefix(ctx context.Context, _ string) (int64, error) {
+	flushMemory()
+	if bus := invalidationBus; bus != nil {
+		if err := bus.PublishFlush(ctx); err != nil {
+			logger.Warn("publish cache flush error", logger.Err(err))
+		}
+	}
+	return -1, nil
+}
+
+// publish the deleted keys if the invalidation bus is set, the other instances keep the stale values
+// until they expire if it fails
+func publishInvalidation(ctx context.Context, keys ...string) {
+	if bus := invalidationBus; bus != nil && len(keys) > 0 {
+		if err := bus.Publish(ctx, keys...); err != nil {
+			logger.Warn("publish cache invalidation error", logger.Err(err), logger.Any("keys", keys))
+		}
+	}
+}
+
+func newCacheEntry(key string, data []byte, ttl time.Duration, redact func(value interface{})) *types.CacheEntry {
+	entry := &types.CacheEntry{Key: key}
+	if ttl > 0 {
+		entry.TTL = int64(ttl / time.Second)
+	}
+	switch {
+	case bytes.Equal(data, cache.NotFoundPlaceholderBytes):
+		entry.Placeholder = true
+	case json.Valid(data):
+		entry.Value = redactValue(data, redact)
+	default:
+		entry.Value, _ = json.Marshal(string(data))
+	}
+	return entry
+}
+
+// redactValue mask the sensitive fields of a json value, the value is dropped if it can not be masked
+func redactValue(data []byte, redact func(value interface{})) json.RawMessage {
+	if redact == nil {
+		return data
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil
+	}
+	redact(value)
+	masked, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	return masked
+}
+
+// redactFields mask the fields of the json objects by name at any depth
+func redactFields(names ...string) func(value interface{}) {
+	var redact func(value interface{})
+	redact = func(value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for field, child := range v {
+				if slices.Contains(names, field) {
+					v[field] = confval.SecretMask
+					continue
+				}
+				redact(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				redact(child)
+			}
+		}
+	}
+	return redact
+}
+
+// redactSecretConfig mask the value of a config of the secret type
+func redactSecretConfig(value interface{}) {
+	if v, ok := value.(map[string]interface{}); ok && v["type"] == string(confval.Secret) {
+		v["value"] = confval.SecretMask
+	}
+}
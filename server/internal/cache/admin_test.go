@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/stretchr/testify/assert"
+
+	"admin/internal/database"
+	"admin/internal/model"
+	"admin/internal/pkg/confval"
+)
+
+func TestKeyInspector(t *testing.T) {
+	c := gotest.NewCache(map[string]interface{}{})
+	defer c.Close()
+	cacheType := &database.CacheType{CType: "redis", Rdb: c.RedisClient}
+
+	roleCache := NewRoleCache(cacheType)
+	for i := uint64(1); i <= 3; i++ {
+		role := &model.Role{Name: "role"}
+		role.ID = i
+		assert.NoError(t, roleCache.Set(c.Ctx, i, role, time.Minute))
+	}
+	assert.NoError(t, roleCache.SetPlaceholder(c.Ctx, 4))
+	assert.NoError(t, NewRoleMenuCache(cacheType).Set(c.Ctx, 1, &model.RoleMenu{RoleID: 1}, time.Minute))
+
+	roleNs, _ := GetNamespace(NamespaceRole)
+	iInspector := NewKeyInspector(cacheType, roleNs)
+	assert.True(t, iInspector.Listable())
+	count, samples, err := iInspector.Scan(c.Ctx, roleCachePrefixKey, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), count) // roleMenu keys are not in the role namespace
+	assert.Len(t, samples, 2)
+
+	entry, err := iInspector.Get(c.Ctx, "role:4")
+	assert.NoError(t, err)
+	assert.True(t, entry.Placeholder)
+	entry, err = iInspector.Get(c.Ctx, "role:1")
+	assert.NoError(t, err)
+	assert.Contains(t, string(entry.Value), `"name":"role"`)
+	assert.Greater(t, entry.TTL, int64(0))
+
+	assert.NoError(t, iInspector.Del(c.Ctx, "role:1"))
+	_, err = iInspector.Get(c.Ctx, "role:1")
+	assert.ErrorIs(t, err, database.ErrCacheNotFound)
+
+	deleted, err := iInspector.DelPrefix(c.Ctx, roleCachePrefixKey)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	count, _, err = iInspector.Scan(c.Ctx, roleMenuCachePrefixKey, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	// the memory cache can not be listed
+	iInspector = NewKeyInspector(&database.CacheType{CType: "memory"}, roleNs)
+	assert.False(t, iInspector.Listable())
+	role := &model.Role{Name: "memory"}
+	role.ID = 5
+	assert.NoError(t, NewRoleCache(&database.CacheType{CType: "memory"}).Set(c.Ctx, 5, role, time.Minute))
+	entry, err = iInspector.Get(c.Ctx, "role:5")
+	assert.NoError(t, err)
+	assert.Contains(t, string(entry.Value), `"name":"memory"`)
+	assert.NoError(t, iInspector.Del(c.Ctx, "role:5"))
+	_, err = iInspector.Get(c.Ctx, "role:5")
+	assert.ErrorIs(t, err, database.ErrCacheNotFound)
+}
+
+func TestKeyInspector_redact(t *testing.T) {
+	c := gotest.NewCache(map[string]interface{}{})
+	defer c.Close()
+	cacheType := &database.CacheType{CType: "redis", Rdb: c.RedisClient}
+
+	platform := &model.Platform{Username: "admin", Password: "$2a$10$hash"}
+	platform.ID = 1
+	assert.NoError(t, NewPlatformCache(cacheType).Set(c.Ctx, 1, platform, time.Minute))
+	platformNs, _ := GetNamespace(NamespacePlatform)
+	entry, err := NewKeyInspector(cacheType, platformNs).Get(c.Ctx, "platform:1")
+	assert.NoError(t, err)
+	assert.Contains(t, string(entry.Value), `"username":"admin"`)
+	assert.Contains(t, string(entry.Value), `"password":"`+confval.SecretMask+`"`)
+	assert.NotContains(t, string(entry.Value), "$2a$10$hash")
+	_, samples, err := NewKeyInspector(cacheType, platformNs).Scan(c.Ctx, platformNs.Prefix, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, samples, 1) {
+		assert.NotContains(t, string(samples[0].Value), "$2a$10$hash")
+	}
+
+	configCache := NewConfigCache(cacheType)
+	secret := &model.Config{Key: "smtpPassword", Value: "p@ss", Type: confval.Secret}
+	secret.ID = 1
+	plain := &model.Config{Key: "siteName", Value: "Admin", Type: confval.String}
+	plain.ID = 2
+	assert.NoError(t, configCache.Set(c.Ctx, 1, secret, time.Minute))
+	assert.NoError(t, configCache.Set(c.Ctx, 2, plain, time.Minute))
+	configNs, _ := GetNamespace(NamespaceConfig)
+	entry, err = NewKeyInspector(cacheType, configNs).Get(c.Ctx, "config:1")
+	assert.NoError(t, err)
+	assert.Contains(t, string(entry.Value), `"value":"`+confval.SecretMask+`"`)
+	assert.NotContains(t, string(entry.Value), "p@ss")
+	entry, err = NewKeyInspector(cacheType, configNs).Get(c.Ctx, "config:2")
+	assert.NoError(t, err)
+	assert.Contains(t, string(entry.Value), `"value":"Admin"`)
+}
@@ -56,8 +56,13 @@ func NewConfigCache(cacheType *database.CacheType) ConfigCache {
 			return &model.Config{}
 		})
 		return &configCache{cache: c}
+	case "tiered":
+		c := newTieredCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &model.Config{}
+		})
+		return &configCache{cache: c}
 	case "memory":
-		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+		c := newMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
 			return &model.Config{}
 		})
 		return &configCache{cache: c}
@@ -2,9 +2,12 @@ package cache
 
 import (
 	"admin/internal/config"
+	"admin/internal/constant/enum"
+	"admin/internal/database"
 	"admin/internal/pkg/util"
 	"admin/internal/types"
 	"context"
+	"errors"
 	"os"
 	"path"
 	"path/filepath"
@@ -14,6 +17,7 @@ import (
 
 	"github.com/go-dev-frame/sponge/pkg/cache"
 	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/logger"
 )
 
 const (
@@ -34,6 +38,21 @@ type EnumCache interface {
 
 	GetAll(ctx context.Context) map[string][]*types.Options
 	GetLabel(ctx context.Context, key string, value interface{}) string
+
+	// Reload load the code enums and the database dictionaries again and tell the other instances to reload
+	Reload(ctx context.Context) error
+	// IsCodeEnum whether the key is an enum of constant/enum, they are read only
+	IsCodeEnum(key string) bool
+}
+
+// EnumDictLoader load the options of the database dictionaries, the key of the map is the dictionary code
+type EnumDictLoader func(ctx context.Context) (map[string][]*types.Options, error)
+
+var enumDictLoader EnumDictLoader
+
+// SetEnumDictLoader merge the database dictionaries into the enums, it is set after the database is initialized
+func SetEnumDictLoader(fn EnumDictLoader) {
+	enumDictLoader = fn
 }
 
 // enumCache define a cache struct
@@ -48,17 +67,66 @@ func NewEnumCache() EnumCache {
 	})
 
 	cc := &enumCache{cache: c}
-	_ = cc.load()
+	_, _ = cc.load(context.Background())
 	return cc
 }
 
-func (c *enumCache) load() error {
+// load the code enums merged with the database dictionaries into the cache, the code enum wins if a
+// dictionary has the same name
+func (c *enumCache) load(ctx context.Context) (map[string][]*types.Options, error) {
 	options := c.getOptions()
+	if enumDictLoader != nil {
+		dicts, err := enumDictLoader(ctx)
+		if err != nil {
+			logger.Warn("load database dictionaries error", logger.Err(err))
+		}
+		for key, items := range dicts {
+			if _, ok := options[key]; ok {
+				logger.Warn("database dictionary is shadowed by the code enum", logger.String("key", key))
+				continue
+			}
+			options[key] = items
+		}
+	}
+
 	err := c.setAll(options)
+	if err != nil {
+		return nil, err
+	}
+	return options, c.MultiSet(ctx, options)
+}
+
+// Reload load the enums again after a database dictionary is changed, the keys of the deleted dictionaries
+// are removed, the other instances evict all the enum keys and load them again on the next read
+func (c *enumCache) Reload(ctx context.Context) error {
+	old := c.GetAll(ctx)
+	options, err := c.load(ctx)
 	if err != nil {
 		return err
 	}
-	return c.MultiSet(context.Background(), options)
+
+	keys := []string{c.GetEnumCacheKey(enumCacheKey)}
+	for key := range old {
+		if _, ok := options[key]; !ok {
+			if err = c.cache.Del(ctx, c.GetEnumCacheKey(key)); err != nil {
+				return err
+			}
+		}
+		keys = append(keys, c.GetEnumCacheKey(key))
+	}
+	for key := range options {
+		if _, ok := old[key]; !ok {
+			keys = append(keys, c.GetEnumCacheKey(key))
+		}
+	}
+	publishInvalidation(ctx, keys...)
+	return nil
+}
+
+// IsCodeEnum whether the key is an enum of constant/enum
+func (c *enumCache) IsCodeEnum(key string) bool {
+	_, ok := c.getOptions()[key]
+	return ok
 }
 
 // GetEnumCacheKey cache key
@@ -82,6 +150,9 @@ func (c *enumCache) Set(ctx context.Context, key string, data []*types.Options)
 // GetOptions cache key
 func (c *enumCache) getOptions() map[string][]*types.Options {
 	if config.Get().App.Env == "prod" {
+		if len(enum.EmbeddedJSON) > 0 {
+			return util.EnumChangeDictByJSON(enum.EmbeddedJSON)
+		}
 		// 尝试从当前工作目录获取缓存路径
 		wd, _ := os.Getwd()
 		filePath := filepath.Join(wd, "enum.json")
@@ -107,6 +178,13 @@ func (c *enumCache) Get(ctx context.Context, key string) ([]*types.Options, erro
 	var data []*types.Options
 	cacheKey := c.GetEnumCacheKey(key)
 	err := c.cache.Get(ctx, cacheKey, &data)
+	if errors.Is(err, database.ErrCacheNotFound) {
+		// evicted by an invalidation event of another instance
+		if _, err = c.load(ctx); err != nil {
+			return nil, err
+		}
+		err = c.cache.Get(ctx, cacheKey, &data)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -118,6 +196,12 @@ func (c *enumCache) GetAll(ctx context.Context) map[string][]*types.Options {
 	var data map[string][]*types.Options
 	cacheKey := c.GetEnumCacheKey(enumCacheKey)
 	err := c.cache.Get(ctx, cacheKey, &data)
+	if errors.Is(err, database.ErrCacheNotFound) {
+		// evicted by an invalidation event of another instance
+		if data, err = c.load(ctx); err == nil {
+			return data
+		}
+	}
 	if err != nil {
 		return make(map[string][]*types.Options)
 	}
@@ -172,7 +256,11 @@ func (c *enumCache) GetLabel(ctx context.Context, key string, value interface{})
 	}
 
 	for _, v := range options {
-		ov := reflect.ValueOf(v.Value).Convert(reflect.TypeOf(value)).Interface()
+		rv := reflect.ValueOf(v.Value)
+		if !rv.IsValid() || !rv.Type().ConvertibleTo(reflect.TypeOf(value)) {
+			continue // the values of the database dictionaries may be strings
+		}
+		ov := rv.Convert(reflect.TypeOf(value)).Interface()
 		if ov == value {
 			return v.Label
 		}
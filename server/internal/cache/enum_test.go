@@ -2,8 +2,12 @@ package cache
 
 import (
 	"admin/internal/config"
+	"admin/internal/types"
 	"testing"
 
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/stretchr/testify/assert"
 	"golang.org/x/net/context"
 )
 
@@ -17,3 +21,63 @@ func TestNewEnumCache(t *testing.T) {
 
 	t.Log(c.GetLabel(context.Background(), "role_code", "ADMIN"))
 }
+
+func TestEnumCache_DictLoader(t *testing.T) {
+	_ = config.Init("")
+	dicts := map[string][]*types.Options{
+		"channel": {{Label: "官网", Value: "web"}},
+		"gender":  {{Label: "shadowed", Value: 9}},
+	}
+	SetEnumDictLoader(func(ctx context.Context) (map[string][]*types.Options, error) {
+		return dicts, nil
+	})
+	defer SetEnumDictLoader(nil)
+
+	ctx := context.Background()
+	c := NewEnumCache()
+	all := c.GetAll(ctx)
+	assert.Len(t, all["channel"], 1)
+	assert.Len(t, all["gender"], 3) // the code enum wins
+	assert.True(t, c.IsCodeEnum("gender"))
+	assert.False(t, c.IsCodeEnum("channel"))
+	assert.Equal(t, "官网", c.GetLabel(ctx, "channel", "web"))
+	assert.Equal(t, "", c.GetLabel(ctx, "channel", 1))
+
+	// a deleted dictionary is removed after reloading
+	dicts = map[string][]*types.Options{"level": {{Label: "高", Value: 1}}}
+	assert.NoError(t, c.Reload(ctx))
+	all = c.GetAll(ctx)
+	assert.NotContains(t, all, "channel")
+	assert.Contains(t, all, "level")
+	_, err := c.Get(ctx, "channel")
+	assert.Error(t, err)
+
+	// evicted by another instance, loaded on the next read
+	cache.GetGlobalMemoryCli().Del(enumCachePrefixKey + enumCacheKey)
+	cache.GetGlobalMemoryCli().Del(enumCachePrefixKey + "level")
+	options, err := c.Get(ctx, "level")
+	assert.NoError(t, err)
+	assert.Len(t, options, 1)
+	assert.Contains(t, c.GetAll(ctx), "level")
+}
+
+func TestEnumCache_ReloadPublish(t *testing.T) {
+	_ = config.Init("")
+	rc := gotest.NewCache(map[string]interface{}{})
+	defer rc.Close()
+
+	// the enums are memory only, the other instances are told to evict them whatever the cache type is
+	SetInvalidationBus(NewInvalidationBus(rc.RedisClient, InvalidationChannel))
+	defer SetInvalidationBus(nil)
+	sub := rc.RedisClient.Subscribe(rc.Ctx, InvalidationChannel)
+	defer sub.Close()
+	_, err := sub.Receive(rc.Ctx)
+	assert.NoError(t, err)
+
+	c := NewEnumCache()
+	assert.NoError(t, c.Reload(rc.Ctx))
+	msg, err := sub.ReceiveMessage(rc.Ctx)
+	assert.NoError(t, err)
+	assert.Contains(t, msg.Payload, `"entity":"enum"`)
+	assert.Contains(t, msg.Payload, `"all"`)
+}
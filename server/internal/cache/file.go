@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"admin/internal/database"
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"admin/internal/model"
+)
+
+const (
+	// cache prefix key, must end with a colon
+	fileCachePrefixKey = "file:"
+	// FileExpireTime expire time
+	FileExpireTime = 5 * time.Minute
+)
+
+var _ FileCache = (*fileCache)(nil)
+
+// FileCache cache interface
+type FileCache interface {
+	Set(ctx context.Context, id uint64, data *model.File, duration time.Duration) error
+	Get(ctx context.Context, id uint64) (*model.File, error)
+	MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.File, error)
+	MultiSet(ctx context.Context, data []*model.File, duration time.Duration) error
+	Del(ctx context.Context, id uint64) error
+	SetPlaceholder(ctx context.Context, id uint64) error
+	IsPlaceholderErr(err error) bool
+}
+
+// fileCache define a cache struct
+type fileCache struct {
+	cache cache.Cache
+}
+
+// NewFileCache new a cache
+func NewFileCache(cacheType *database.CacheType) FileCache {
+	jsonEncoding := encoding.JSONEncoding{}
+	cachePrefix := ""
+
+	cType := strings.ToLower(cacheType.CType)
+	switch cType {
+	case "redis":
+		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &model.File{}
+		})
+		return &fileCache{cache: c}
+	case "tiered":
+		c := newTieredCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &model.File{}
+		})
+		return &fileCache{cache: c}
+	case "memory":
+		c := newMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+			return &model.File{}
+		})
+		return &fileCache{cache: c}
+	}
+
+	return nil // no cache
+}
+
+// GetFileCacheKey cache key
+func (c *fileCache) GetFileCacheKey(id uint64) string {
+	return fileCachePrefixKey + utils.Uint64ToStr(id)
+}
+
+// Set write to cache
+func (c *fileCache) Set(ctx context.Context, id uint64, data *model.File, duration time.Duration) error {
+	if data == nil || id == 0 {
+		return nil
+	}
+	cacheKey := c.GetFileCacheKey(id)
+	err := c.cache.Set(ctx, cacheKey, data, duration)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Get cache value
+func (c *fileCache) Get(ctx context.Context, id uint64) (*model.File, error) {
+	var data *model.File
+	cacheKey := c.GetFileCacheKey(id)
+	err := c.cache.Get(ctx, cacheKey, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// MultiSet multiple set cache
+func (c *fileCache) MultiSet(ctx context.Context, data []*model.File, duration time.Duration) error {
+	valMap := make(map[string]interface{})
+	for _, v := range data {
+		cacheKey := c.GetFileCacheKey(v.ID)
+		valMap[cacheKey] = v
+	}
+
+	err := c.cache.MultiSet(ctx, valMap, duration)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// MultiGet multiple get cache, return key in map is id value
+func (c *fileCache) MultiGet(ctx context.Context, ids []uint64) (map[uint64]*model.File, error) {
+	var keys []string
+	for _, v := range ids {
+		cacheKey := c.GetFileCacheKey(v)
+		keys = append(keys, cacheKey)
+	}
+
+	itemMap := make(map[string]*model.File)
+	err := c.cache.MultiGet(ctx, keys, itemMap)
+	if err != nil {
+		return nil, err
+	}
+
+	retMap := make(map[uint64]*model.File)
+	for _, id := range ids {
+		val, ok := itemMap[c.GetFileCacheKey(id)]
+		if ok {
+			retMap[id] = val
+		}
+	}
+
+	return retMap, nil
+}
+
+// Del delete cache
+func (c *fileCache) Del(ctx context.Context, id uint64) error {
+	cacheKey := c.GetFileCacheKey(id)
+	err := c.cache.Del(ctx, cacheKey)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// SetPlaceholder set placeholder value to cache
+func (c *fileCache) SetPlaceholder(ctx context.Context, id uint64) error {
+	cacheKey := c.GetFileCacheKey(id)
+	return c.cache.SetCacheWithNotFound(ctx, cacheKey)
+}
+
+// IsPlaceholderErr check if cache is placeholder error
+func (c *fileCache) IsPlaceholderErr(err error) bool {
+	return errors.Is(err, cache.ErrPlaceholder)
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/redis/go-redis/v9"
+
+	"admin/internal/database"
+	"admin/internal/types"
+)
+
+const (
+	// cache key of the last report, shared by all instances when redis is used
+	fileGCReportCacheKey = "fileGC:lastReport"
+	// FileGCReportExpireTime expire time
+	FileGCReportExpireTime = 7 * 24 * time.Hour
+	// cache key of the lock of the collection, shared by all instances when redis is used
+	fileGCLockCacheKey = "fileGC:lock"
+)
+
+// delete the lock only if it is still held by the token, an expired lock may have been taken by another instance
+var fileGCUnlockScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+var _ FileGCCache = (*fileGCCache)(nil)
+
+// FileGCCache cache interface
+type FileGCCache interface {
+	SetReport(ctx context.Context, data *types.FileGCReport) error
+	GetReport(ctx context.Context) (*types.FileGCReport, error)
+	TryLock(ctx context.Context, ttl time.Duration) (string, bool, error)
+	Unlock(ctx context.Context, token string) error
+}
+
+// fileGCCache define a cache struct
+type fileGCCache struct {
+	cache cache.Cache
+	rdb   *redis.Client // the lock is shared by the instances if it is not nil
+}
+
+// NewFileGCCache new a cache, the report is kept in memory if redis is not used
+func NewFileGCCache(cacheType *database.CacheType) FileGCCache {
+	jsonEncoding := encoding.JSONEncoding{}
+	cachePrefix := ""
+
+	switch strings.ToLower(cacheType.CType) {
+	case "redis":
+		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &types.FileGCReport{}
+		})
+		return &fileGCCache{cache: c, rdb: cacheType.Rdb}
+	case "tiered":
+		c := newTieredCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &types.FileGCReport{}
+		})
+		return &fileGCCache{cache: c, rdb: cacheType.Rdb}
+	}
+	c := newMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+		return &types.FileGCReport{}
+	})
+	return &fileGCCache{cache: c}
+}
+
+// SetReport write the last report to cache
+func (c *fileGCCache) SetReport(ctx context.Context, data *types.FileGCReport) error {
+	return c.cache.Set(ctx, fileGCReportCacheKey, data, FileGCReportExpireTime)
+}
+
+// GetReport get the last report from cache, return database.ErrCacheNotFound if gc has not run yet
+func (c *fileGCCache) GetReport(ctx context.Context) (*types.FileGCReport, error) {
+	data := &types.FileGCReport{}
+	err := c.cache.Get(ctx, fileGCReportCacheKey, data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// TryLock take the lock of the collection, ok is false if another instance holds it, the lock expires after ttl
+// in case the instance dies. without redis there is nothing to share, the lock is always taken and the
+// collection must run on a single instance
+func (c *fileGCCache) TryLock(ctx context.Context, ttl time.Duration) (string, bool, error) {
+	if c.rdb == nil {
+		return "", true, nil
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", false, err
+	}
+	token := hex.EncodeToString(b)
+	ok, err := c.rdb.SetNX(ctx, fileGCLockCacheKey, token, ttl).Result()
+	if err != nil || !ok {
+		return "", false, err
+	}
+	return token, true, nil
+}
+
+// Unlock release the lock taken by TryLock
+func (c *fileGCCache) Unlock(ctx context.Context, token string) error {
+	if c.rdb == nil {
+		return nil
+	}
+	return fileGCUnlockScript.Run(ctx, c.rdb, []string{fileGCLockCacheKey}, token).Err()
+}
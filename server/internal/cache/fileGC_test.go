@@ -0,0 +1,64 @@
+package cache
+
+import (
+	"admin/internal/database"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+
+	"admin/internal/types"
+)
+
+func Test_fileGCCache(t *testing.T) {
+	c := gotest.NewCache(map[string]interface{}{})
+	defer c.Close()
+
+	for _, cacheType := range []*database.CacheType{
+		{CType: "redis", Rdb: c.RedisClient},
+		{CType: "memory"},
+	} {
+		iCache := NewFileGCCache(cacheType)
+		_, err := iCache.GetReport(c.Ctx)
+		assert.ErrorIs(t, err, database.ErrCacheNotFound, cacheType.CType)
+
+		err = iCache.SetReport(c.Ctx, &types.FileGCReport{DryRun: true, Scanned: 3})
+		assert.NoError(t, err)
+		report, err := iCache.GetReport(c.Ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, report.Scanned)
+		assert.True(t, report.DryRun)
+	}
+}
+
+func Test_fileGCCache_TryLock(t *testing.T) {
+	c := gotest.NewCache(map[string]interface{}{})
+	defer c.Close()
+
+	// two instances sharing redis
+	a := NewFileGCCache(&database.CacheType{CType: "redis", Rdb: c.RedisClient})
+	b := NewFileGCCache(&database.CacheType{CType: "redis", Rdb: c.RedisClient})
+	token, ok, err := a.TryLock(c.Ctx, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	_, ok, err = b.TryLock(c.Ctx, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// only the holder releases the lock
+	assert.NoError(t, b.Unlock(c.Ctx, "other"))
+	_, ok, _ = b.TryLock(c.Ctx, time.Minute)
+	assert.False(t, ok)
+	assert.NoError(t, a.Unlock(c.Ctx, token))
+	_, ok, err = b.TryLock(c.Ctx, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// nothing is shared without redis
+	m := NewFileGCCache(&database.CacheType{CType: "memory"})
+	_, ok, err = m.TryLock(c.Ctx, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"admin/internal/database"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"admin/internal/model"
+)
+
+func newFileCache() *gotest.Cache {
+	record1 := &model.File{}
+	record1.ID = 1
+	record2 := &model.File{}
+	record2.ID = 2
+	testData := map[string]interface{}{
+		utils.Uint64ToStr(record1.ID): record1,
+		utils.Uint64ToStr(record2.ID): record2,
+	}
+
+	c := gotest.NewCache(testData)
+	c.ICache = NewFileCache(&database.CacheType{
+		CType: "redis",
+		Rdb:   c.RedisClient,
+	})
+	return c
+}
+
+func Test_fileCache_Set(t *testing.T) {
+	c := newFileCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.File)
+	err := c.ICache.(FileCache).Set(c.Ctx, record.ID, record, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// nil data
+	err = c.ICache.(FileCache).Set(c.Ctx, 0, nil, time.Hour)
+	assert.NoError(t, err)
+}
+
+func Test_fileCache_Get(t *testing.T) {
+	c := newFileCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.File)
+	err := c.ICache.(FileCache).Set(c.Ctx, record.ID, record, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.ICache.(FileCache).Get(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, record, got)
+
+	// zero key error
+	_, err = c.ICache.(FileCache).Get(c.Ctx, 0)
+	assert.Error(t, err)
+}
+
+func Test_fileCache_MultiGet(t *testing.T) {
+	c := newFileCache()
+	defer c.Close()
+
+	var testData []*model.File
+	for _, data := range c.TestDataSlice {
+		testData = append(testData, data.(*model.File))
+	}
+
+	err := c.ICache.(FileCache).MultiSet(c.Ctx, testData, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.ICache.(FileCache).MultiGet(c.Ctx, c.GetIDs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := c.GetTestData()
+	for k, v := range expected {
+		assert.Equal(t, got[utils.StrToUint64(k)], v.(*model.File))
+	}
+}
+
+func Test_fileCache_MultiSet(t *testing.T) {
+	c := newFileCache()
+	defer c.Close()
+
+	var testData []*model.File
+	for _, data := range c.TestDataSlice {
+		testData = append(testData, data.(*model.File))
+	}
+
+	err := c.ICache.(FileCache).MultiSet(c.Ctx, testData, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_fileCache_Del(t *testing.T) {
+	c := newFileCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.File)
+	err := c.ICache.(FileCache).Del(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_fileCache_SetCacheWithNotFound(t *testing.T) {
+	c := newFileCache()
+	defer c.Close()
+
+	record := c.TestDataSlice[0].(*model.File)
+	err := c.ICache.(FileCache).SetPlaceholder(c.Ctx, record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := c.ICache.(FileCache).IsPlaceholderErr(err)
+	t.Log(b)
+}
+
+func TestNewFileCache(t *testing.T) {
+	c := NewFileCache(&database.CacheType{
+		CType: "",
+	})
+	assert.Nil(t, c)
+	c = NewFileCache(&database.CacheType{
+		CType: "memory",
+	})
+	assert.NotNil(t, c)
+	c = NewFileCache(&database.CacheType{
+		CType: "redis",
+	})
+	assert.NotNil(t, c)
+}
@@ -0,0 +1,227 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/goredis"
+	"github.com/go-dev-frame/sponge/pkg/krand"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/redis/go-redis/v9"
+)
+
+// InvalidationChannel redis channel of the cache invalidation events
+const InvalidationChannel = "admin:cache:invalidation"
+
+// Invalidation an event published after cache keys of an entity are deleted, every other instance
+// evicts the keys from its memory cache, Flush asks them to clear the whole memory cache
+type Invalidation struct {
+	Node   string   `json:"node"`            // instance which published the event, it ignores its own events
+	Entity string   `json:"entity"`          // key prefix of the entity, e.g. role, menu, config
+	IDs    []string `json:"ids"`             // the part of the keys after the prefix
+	Flush  bool     `json:"flush,omitempty"` // clear all
+}
+
+// Keys cache keys of the event
+func (e *Invalidation) Keys() []string {
+	keys := make([]string, 0, len(e.IDs))
+	for _, id := range e.IDs {
+		if id == "" {
+			keys = append(keys, e.Entity)
+		} else {
+			keys = append(keys, e.Entity+":"+id)
+		}
+	}
+	return keys
+}
+
+// InvalidationBus broadcast the deletion of memory cache keys to the other instances through redis pub/sub,
+// see InvalidationEnabled for when it runs
+type InvalidationBus struct {
+	rdb     *goredis.Client
+	channel string
+	node    string
+
+	mu     sync.Mutex
+	pubsub *redis.PubSub
+	done   chan struct{}
+}
+
+var invalidationBus *InvalidationBus
+
+// InvalidationEnabled whether the bus is needed by the cache type. the enums are kept in memory whatever
+// the cache type is, so the bus runs whenever redis is configured: cacheType redis or tiered, or memory
+// with cacheInvalidation
+func InvalidationEnabled(cacheType string, invalidation bool) bool {
+	switch cacheType {
+	case "redis", "tiered":
+		return true
+	case "memory":
+		return invalidation
+	}
+	return false
+}
+
+// NewInvalidationBus new a bus on the channel
+func NewInvalidationBus(rdb *goredis.Client, channel string) *InvalidationBus {
+	return &InvalidationBus{
+		rdb:     rdb,
+		channel: channel,
+		node:    krand.String(krand.R_All, 16),
+	}
+}
+
+// SetInvalidationBus the memory caches publish their deletions to the bus, nil stops publishing
+func SetInvalidationBus(bus *InvalidationBus) {
+	invalidationBus = bus
+}
+
+// CloseInvalidationBus stop the bus set by SetInvalidationBus
+func CloseInvalidationBus() error {
+	if invalidationBus == nil {
+		return nil
+	}
+	return invalidationBus.Close()
+}
+
+// Publish tell the other instances to evict the keys, a key is split into entity and id at the first colon
+func (b *InvalidationBus) Publish(ctx context.Context, keys ...string) error {
+	events := map[string]*Invalidation{}
+	var entities []string
+	for _, key := range keys {
+		entity, id, _ := strings.Cut(key, ":")
+		e, ok := events[entity]
+		if !ok {
+			e = &Invalidation{Node: b.node, Entity: entity}
+			events[entity] = e
+			entities = append(entities, entity)
+		}
+		e.IDs = append(e.IDs, id)
+	}
+	for _, entity := range entities {
+		if err := b.publish(ctx, events[entity]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishFlush tell the other instances to clear their memory cache
+func (b *InvalidationBus) PublishFlush(ctx context.Context) error {
+	return b.publish(ctx, &Invalidation{Node: b.node, Flush: true})
+}
+
+func (b *InvalidationBus) publish(ctx context.Context, e *Invalidation) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return b.rdb.Publish(ctx, b.channel, data).Err()
+}
+
+// Run subscribe the channel and evict the keys of the received events until Close is called.
+// go-redis reconnects and resubscribes by itself, events published while the subscription was broken
+// are lost, so the whole memory cache is cleared after every resubscription
+func (b *InvalidationBus) Run() {
+	b.mu.Lock()
+	if b.pubsub != nil {
+		b.mu.Unlock()
+		return
+	}
+	b.pubsub = b.rdb.Subscribe(context.Background(), b.channel)
+	b.done = make(chan struct{})
+	ch := b.pubsub.ChannelWithSubscriptions()
+	b.mu.Unlock()
+
+	go func() {
+		defer close(b.done)
+		subscribed := false
+		for msg := range ch {
+			switch m := msg.(type) {
+			case *redis.Subscription:
+				if m.Kind != "subscribe" {
+					continue
+				}
+				if subscribed {
+					logger.Warn("cache invalidation subscription was broken, clear the memory cache", logger.String("channel", b.channel))
+					flushMemory()
+				}
+				subscribed = true
+			case *redis.Message:
+				b.handle(m.Payload)
+			}
+		}
+	}()
+}
+
+// Close stop the subscription
+func (b *InvalidationBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pubsub == nil {
+		return nil
+	}
+	err := b.pubsub.Close()
+	<-b.done
+	b.pubsub = nil
+	return err
+}
+
+func (b *InvalidationBus) handle(payload string) {
+	e := &Invalidation{}
+	if err := json.Unmarshal([]byte(payload), e); err != nil {
+		logger.Warn("invalid cache invalidation event", logger.Err(err), logger.String("payload", payload))
+		return
+	}
+	if e.Node == b.node {
+		return
+	}
+	if e.Flush {
+		flushMemory()
+		return
+	}
+	client := cache.GetGlobalMemoryCli()
+	for _, key := range e.Keys() {
+		client.Del(key)
+		tieredL1.del(key)
+	}
+}
+
+// clear the memory cache and the local tier of the tiered caches
+func flushMemory() {
+	cache.GetGlobalMemoryCli().Clear()
+	tieredL1.clear()
+}
+
+// memory cache which publishes its deletions to the invalidation bus
+type busCache struct {
+	cache.Cache
+	keyPrefix string
+}
+
+// newMemoryCache the memory cache used by all cache types, deletions are broadcast if the bus is set
+func newMemoryCache(keyPrefix string, encode encoding.Encoding, newObject func() interface{}) cache.Cache {
+	return &busCache{
+		Cache:     cache.NewMemoryCache(keyPrefix, encode, newObject),
+		keyPrefix: keyPrefix,
+	}
+}
+
+// Del delete the keys locally and publish them
+func (c *busCache) Del(ctx context.Context, keys ...string) error {
+	cacheKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if err := c.Cache.Del(ctx, key); err != nil {
+			return err
+		}
+		cacheKey, _ := cache.BuildCacheKey(c.keyPrefix, key)
+		cacheKeys = append(cacheKeys, cacheKey)
+	}
+
+	publishInvalidation(ctx, cacheKeys...)
+	return nil
+}
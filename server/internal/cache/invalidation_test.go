@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/stretchr/testify/assert"
+
+	"admin/internal/database"
+	"admin/internal/model"
+)
+
+func TestInvalidationBus(t *testing.T) {
+	c := gotest.NewCache(map[string]interface{}{})
+	defer c.Close()
+
+	// two instances sharing the process memory cache, b plays the other instance
+	a := NewInvalidationBus(c.RedisClient, InvalidationChannel)
+	b := NewInvalidationBus(c.RedisClient, InvalidationChannel)
+	b.Run()
+	defer b.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	SetInvalidationBus(a)
+	defer SetInvalidationBus(nil)
+
+	roleCache := NewRoleCache(&database.CacheType{CType: "memory"})
+	role := &model.Role{Name: "admin"}
+	role.ID = 3
+	assert.NoError(t, roleCache.Set(c.Ctx, role.ID, role, time.Minute))
+	time.Sleep(10 * time.Millisecond) // ristretto sets are asynchronous
+
+	// deletions of the memory caches are published
+	sub := c.RedisClient.Subscribe(c.Ctx, InvalidationChannel)
+	defer sub.Close()
+	_, err := sub.Receive(c.Ctx)
+	assert.NoError(t, err)
+	assert.NoError(t, roleCache.Del(c.Ctx, role.ID))
+	msg, err := sub.ReceiveMessage(c.Ctx)
+	assert.NoError(t, err)
+	assert.Contains(t, msg.Payload, `"entity":"role","ids":["3"]`)
+
+	// the other instance evicts the keys of the event
+	assert.NoError(t, roleCache.Set(c.Ctx, role.ID, role, time.Minute))
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, a.Publish(c.Ctx, "role:3"))
+	assert.Eventually(t, func() bool {
+		_, err := roleCache.Get(c.Ctx, role.ID)
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+
+	// its own events are ignored
+	assert.NoError(t, roleCache.Set(c.Ctx, role.ID, role, time.Minute))
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(t, b.Publish(c.Ctx, "role:3"))
+	time.Sleep(100 * time.Millisecond)
+	_, err = roleCache.Get(c.Ctx, role.ID)
+	assert.NoError(t, err)
+
+	// flush
+	assert.NoError(t, a.PublishFlush(c.Ctx))
+	assert.Eventually(t, func() bool {
+		_, err := roleCache.Get(c.Ctx, role.ID)
+		return err != nil
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestInvalidationEnabled(t *testing.T) {
+	assert.True(t, InvalidationEnabled("redis", false))
+	assert.True(t, InvalidationEnabled("tiered", false))
+	assert.True(t, InvalidationEnabled("memory", true))
+	assert.False(t, InvalidationEnabled("memory", false))
+	assert.False(t, InvalidationEnabled("", true))
+}
+
+func TestInvalidation_Keys(t *testing.T) {
+	e := &Invalidation{Entity: "config", IDs: []string{"1", "imageDomain", ""}}
+	assert.Equal(t, []string{"config:1", "config:imageDomain", "config"}, e.Keys())
+}
@@ -32,11 +32,15 @@ type MenuCache interface {
 	Del(ctx context.Context, id uint64) error
 	SetPlaceholder(ctx context.Context, id uint64) error
 	IsPlaceholderErr(err error) bool
+
+	// MenuTreeCache the menus visible to the role sets, they are invalidated when a menu changes
+	MenuTreeCache
 }
 
 // menuCache define a cache struct
 type menuCache struct {
 	cache cache.Cache
+	*menuTreeCache
 }
 
 // NewMenuCache new a cache
@@ -50,12 +54,17 @@ func NewMenuCache(cacheType *database.CacheType) MenuCache {
 		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
 			return &model.Menu{}
 		})
-		return &menuCache{cache: c}
+		return &menuCache{cache: c, menuTreeCache: newMenuTreeCache(c)}
+	case "tiered":
+		c := newTieredCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &model.Menu{}
+		})
+		return &menuCache{cache: c, menuTreeCache: newMenuTreeCache(c)}
 	case "memory":
-		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+		c := newMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
 			return &model.Menu{}
 		})
-		return &menuCache{cache: c}
+		return &menuCache{cache: c, menuTreeCache: newMenuTreeCache(c)}
 	}
 
 	return nil // no cache
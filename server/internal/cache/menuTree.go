@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/krand"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"admin/internal/database"
+	"admin/internal/model"
+)
+
+const (
+	// cache prefix key, must end with a colon
+	menuTreeCachePrefixKey = "menuTree:"
+	// the version is part of the keys of the trees, deleting it invalidates the trees of all role sets
+	menuTreeVersionKey = menuTreeCachePrefixKey + "version"
+	// MenuTreeExpireTime expire time of the menus of a role set
+	MenuTreeExpireTime = 10 * time.Minute
+	// the version outlives the trees built with it
+	menuTreeVersionExpireTime = 24 * time.Hour
+)
+
+var _ MenuTreeCache = (*menuTreeCache)(nil)
+
+// MenuTreeCache the menus visible to a role set, the menu dao builds the route tree and the options from them.
+// the trees of all role sets are invalidated together when a menu or a role-menu link changes
+type MenuTreeCache interface {
+	// TreeVersion the current version, a new one is created if there is none. read it before loading the
+	// menus from the database, so the menus loaded before an invalidation are not cached under the new version
+	TreeVersion(ctx context.Context) (string, error)
+	GetTree(ctx context.Context, version string, roleIds []uint64) ([]*model.Menu, error)
+	SetTree(ctx context.Context, version string, roleIds []uint64, data []*model.Menu, duration time.Duration) error
+	// DelTrees invalidate the trees of all role sets
+	DelTrees(ctx context.Context) error
+}
+
+// menuTreeCache shares the cache of the menu and the role-menu caches
+type menuTreeCache struct {
+	cache cache.Cache
+}
+
+func newMenuTreeCache(c cache.Cache) *menuTreeCache {
+	return &menuTreeCache{cache: c}
+}
+
+// GetMenuTreeCacheKey cache key of a role set, the ids are sorted, nil is all the menus
+func (c *menuTreeCache) GetMenuTreeCacheKey(version string, roleIds []uint64) string {
+	if roleIds == nil {
+		return menuTreeCachePrefixKey + version + ":all"
+	}
+	ids := make([]uint64, len(roleIds))
+	copy(ids, roleIds)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	strs := make([]string, 0, len(ids))
+	for i, id := range ids {
+		if i > 0 && id == ids[i-1] {
+			continue
+		}
+		strs = append(strs, utils.Uint64ToStr(id))
+	}
+	return menuTreeCachePrefixKey + version + ":" + strings.Join(strs, ",")
+}
+
+// TreeVersion get the current version or create one
+func (c *menuTreeCache) TreeVersion(ctx context.Context) (string, error) {
+	var version string
+	err := c.cache.Get(ctx, menuTreeVersionKey, &version)
+	if err == nil && version != "" {
+		return version, nil
+	}
+	if err != nil && !errors.Is(err, database.ErrCacheNotFound) {
+		return "", err
+	}
+	version = krand.String(krand.R_All, 8)
+	if err = c.cache.Set(ctx, menuTreeVersionKey, &version, menuTreeVersionExpireTime); err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// GetTree cache value
+func (c *menuTreeCache) GetTree(ctx context.Context, version string, roleIds []uint64) ([]*model.Menu, error) {
+	var data []*model.Menu
+	err := c.cache.Get(ctx, c.GetMenuTreeCacheKey(version, roleIds), &data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// SetTree write to cache
+func (c *menuTreeCache) SetTree(ctx context.Context, version string, roleIds []uint64, data []*model.Menu, duration time.Duration) error {
+	if data == nil || version == "" {
+		return nil
+	}
+	return c.cache.Set(ctx, c.GetMenuTreeCacheKey(version, roleIds), &data, duration)
+}
+
+// DelTrees delete the version, the trees built with it expire by themselves
+func (c *menuTreeCache) DelTrees(ctx context.Context) error {
+	return c.cache.Del(ctx, menuTreeVersionKey)
+}
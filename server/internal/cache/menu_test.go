@@ -145,3 +145,32 @@ func TestNewMenuCache(t *testing.T) {
 	})
 	assert.NotNil(t, c)
 }
+
+func Test_menuCache_Tree(t *testing.T) {
+	c := newMenuCache()
+	defer c.Close()
+
+	menuCache := c.ICache.(MenuCache)
+	version, err := menuCache.TreeVersion(c.Ctx)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, version)
+	again, _ := menuCache.TreeVersion(c.Ctx)
+	assert.Equal(t, version, again)
+
+	menus := []*model.Menu{{Name: "系统管理"}}
+	assert.NoError(t, menuCache.SetTree(c.Ctx, version, []uint64{2, 1, 2}, menus, time.Hour))
+	data, err := menuCache.GetTree(c.Ctx, version, []uint64{1, 2})
+	if assert.NoError(t, err) && assert.Len(t, data, 1) {
+		assert.Equal(t, "系统管理", data[0].Name)
+	}
+	_, err = menuCache.GetTree(c.Ctx, version, nil)
+	assert.ErrorIs(t, err, database.ErrCacheNotFound)
+
+	// the role-menu cache invalidates the trees of the menu cache
+	roleMenuCache := NewRoleMenuCache(&database.CacheType{CType: "redis", Rdb: c.RedisClient})
+	assert.NoError(t, roleMenuCache.DelTrees(c.Ctx))
+	newVersion, _ := menuCache.TreeVersion(c.Ctx)
+	assert.NotEqual(t, version, newVersion)
+	_, err = menuCache.GetTree(c.Ctx, newVersion, []uint64{1, 2})
+	assert.ErrorIs(t, err, database.ErrCacheNotFound)
+}
@@ -51,8 +51,13 @@ func NewPlatformCache(cacheType *database.CacheType) PlatformCache {
 			return &model.Platform{}
 		})
 		return &platformCache{cache: c}
+	case "tiered":
+		c := newTieredCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &model.Platform{}
+		})
+		return &platformCache{cache: c}
 	case "memory":
-		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+		c := newMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
 			return &model.Platform{}
 		})
 		return &platformCache{cache: c}
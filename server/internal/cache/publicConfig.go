@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+
+	"admin/internal/database"
+	"admin/internal/types"
+)
+
+const (
+	// cache key of the public configs, it must not start with configCachePrefixKey which is used by config keys
+	publicConfigCacheKey = "publicConfig:all"
+	// PublicConfigExpireTime expire time, changes made outside the api are visible after it
+	PublicConfigExpireTime = 5 * time.Minute
+)
+
+var _ PublicConfigCache = (*publicConfigCache)(nil)
+
+// PublicConfigCache cache interface
+type PublicConfigCache interface {
+	Set(ctx context.Context, data *types.PublicConfigs) error
+	Get(ctx context.Context) (*types.PublicConfigs, error)
+	Del(ctx context.Context) error
+}
+
+// publicConfigCache define a cache struct
+type publicConfigCache struct {
+	cache cache.Cache
+}
+
+// NewPublicConfigCache new a cache, the configs are kept in memory if redis is not used
+func NewPublicConfigCache(cacheType *database.CacheType) PublicConfigCache {
+	jsonEncoding := encoding.JSONEncoding{}
+	cachePrefix := ""
+
+	switch strings.ToLower(cacheType.CType) {
+	case "redis":
+		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &types.PublicConfigs{}
+		})
+		return &publicConfigCache{cache: c}
+	case "tiered":
+		c := newTieredCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &types.PublicConfigs{}
+		})
+		return &publicConfigCache{cache: c}
+	}
+	c := newMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+		return &types.PublicConfigs{}
+	})
+	return &publicConfigCache{cache: c}
+}
+
+// Set write to cache
+func (c *publicConfigCache) Set(ctx context.Context, data *types.PublicConfigs) error {
+	return c.cache.Set(ctx, publicConfigCacheKey, data, PublicConfigExpireTime)
+}
+
+// Get from cache
+func (c *publicConfigCache) Get(ctx context.Context) (*types.PublicConfigs, error) {
+	data := &types.PublicConfigs{}
+	err := c.cache.Get(ctx, publicConfigCacheKey, data)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Del delete the cache, called when a config changes
+func (c *publicConfigCache) Del(ctx context.Context) error {
+	return c.cache.Del(ctx, publicConfigCacheKey)
+}
@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"admin/internal/database"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+
+	"admin/internal/types"
+)
+
+func Test_publicConfigCache(t *testing.T) {
+	c := gotest.NewCache(map[string]interface{}{})
+	defer c.Close()
+
+	for _, cacheType := range []*database.CacheType{
+		{CType: "redis", Rdb: c.RedisClient},
+		{CType: "memory"},
+	} {
+		iCache := NewPublicConfigCache(cacheType)
+		_, err := iCache.Get(c.Ctx)
+		assert.ErrorIs(t, err, database.ErrCacheNotFound, cacheType.CType)
+
+		err = iCache.Set(c.Ctx, &types.PublicConfigs{ETag: `"abc"`, Configs: types.ConfigValues{"siteName": "admin"}})
+		assert.NoError(t, err)
+		data, err := iCache.Get(c.Ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, `"abc"`, data.ETag)
+		assert.Equal(t, "admin", data.Configs["siteName"])
+
+		assert.NoError(t, iCache.Del(c.Ctx))
+		_, err = iCache.Get(c.Ctx)
+		assert.ErrorIs(t, err, database.ErrCacheNotFound)
+	}
+}
@@ -51,8 +51,13 @@ func NewRoleCache(cacheType *database.CacheType) RoleCache {
 			return &model.Role{}
 		})
 		return &roleCache{cache: c}
+	case "tiered":
+		c := newTieredCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &model.Role{}
+		})
+		return &roleCache{cache: c}
 	case "memory":
-		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+		c := newMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
 			return &model.Role{}
 		})
 		return &roleCache{cache: c}
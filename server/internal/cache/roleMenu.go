@@ -32,11 +32,15 @@ type RoleMenuCache interface {
 	Del(ctx context.Context, id uint64) error
 	SetPlaceholder(ctx context.Context, id uint64) error
 	IsPlaceholderErr(err error) bool
+
+	// MenuTreeCache the menus visible to the role sets, they are invalidated when a role-menu link changes
+	MenuTreeCache
 }
 
 // roleMenuCache define a cache struct
 type roleMenuCache struct {
 	cache cache.Cache
+	*menuTreeCache
 }
 
 // NewRoleMenuCache new a cache
@@ -50,12 +54,17 @@ func NewRoleMenuCache(cacheType *database.CacheType) RoleMenuCache {
 		c := cache.NewRedisCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
 			return &model.RoleMenu{}
 		})
-		return &roleMenuCache{cache: c}
+		return &roleMenuCache{cache: c, menuTreeCache: newMenuTreeCache(c)}
+	case "tiered":
+		c := newTieredCache(cacheType.Rdb, cachePrefix, jsonEncoding, func() interface{} {
+			return &model.RoleMenu{}
+		})
+		return &roleMenuCache{cache: c, menuTreeCache: newMenuTreeCache(c)}
 	case "memory":
-		c := cache.NewMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
+		c := newMemoryCache(cachePrefix, jsonEncoding, func() interface{} {
 			return &model.RoleMenu{}
 		})
-		return &roleMenuCache{cache: c}
+		return &roleMenuCache{cache: c, menuTreeCache: newMenuTreeCache(c)}
 	}
 
 	return nil // no cache
@@ -0,0 +1,282 @@
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/cache"
+	"github.com/go-dev-frame/sponge/pkg/encoding"
+	"github.com/go-dev-frame/sponge/pkg/goredis"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maximum number of keys kept in the local tier
+const tieredL1Size = 10000
+
+var (
+	// TieredL1ExpireTime the local tier keeps a value at most this long, it bounds the staleness
+	// when an invalidation event is lost
+	TieredL1ExpireTime = 10 * time.Second
+
+	// local tier shared by all tiered caches, the invalidation bus evicts from it
+	tieredL1 = newLRU(tieredL1Size)
+
+	cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_tier_requests_total",
+		Help: "Lookups of the tiered cache by tier (l1: memory, l2: redis) and result (hit or miss).",
+	}, []string{"tier", "result"})
+)
+
+func init() {
+	prometheus.MustRegister(cacheRequests)
+}
+
+// tieredCache a local LRU (L1) in front of the redis cache (L2), used when cacheType is tiered.
+// deletions are published to the invalidation bus, so the other instances evict their L1
+type tieredCache struct {
+	l1        *lruCache
+	l2        cache.Cache
+	keyPrefix string
+	encoding  encoding.Encoding
+	newObject func() interface{}
+}
+
+// newTieredCache new a tiered cache, the arguments are the same as cache.NewRedisCache
+func newTieredCache(rdb *goredis.Client, keyPrefix string, encode encoding.Encoding, newObject func() interface{}) cache.Cache {
+	return &tieredCache{
+		l1:        tieredL1,
+		l2:        cache.NewRedisCache(rdb, keyPrefix, encode, newObject),
+		keyPrefix: keyPrefix,
+		encoding:  encode,
+		newObject: newObject,
+	}
+}
+
+// Set write to both tiers
+func (c *tieredCache) Set(ctx context.Context, key string, val interface{}, expiration time.Duration) error {
+	if err := c.l2.Set(ctx, key, val, expiration); err != nil {
+		return err
+	}
+	c.setL1(key, val, expiration)
+	return nil
+}
+
+// Get read from L1, then from L2 and keep the value in L1
+func (c *tieredCache) Get(ctx context.Context, key string, val interface{}) error {
+	cacheKey, err := cache.BuildCacheKey(c.keyPrefix, key)
+	if err != nil {
+		return err
+	}
+	if data, ok := c.l1.get(cacheKey); ok {
+		cacheRequests.WithLabelValues("l1", "hit").Inc()
+		if bytes.Equal(data, cache.NotFoundPlaceholderBytes) {
+			return cache.ErrPlaceholder
+		}
+		return encoding.Unmarshal(c.encoding, data, val)
+	}
+	cacheRequests.WithLabelValues("l1", "miss").Inc()
+
+	err = c.l2.Get(ctx, key, val)
+	switch {
+	case err == nil:
+		cacheRequests.WithLabelValues("l2", "hit").Inc()
+		c.setL1(key, val, TieredL1ExpireTime)
+	case errors.Is(err, cache.ErrPlaceholder):
+		cacheRequests.WithLabelValues("l2", "hit").Inc()
+		c.l1.set(cacheKey, cache.NotFoundPlaceholderBytes, TieredL1ExpireTime)
+	default:
+		cacheRequests.WithLabelValues("l2", "miss").Inc()
+	}
+	return err
+}
+
+// MultiSet write to both tiers
+func (c *tieredCache) MultiSet(ctx context.Context, valMap map[string]interface{}, expiration time.Duration) error {
+	if err := c.l2.MultiSet(ctx, valMap, expiration); err != nil {
+		return err
+	}
+	for key, val := range valMap {
+		c.setL1(key, val, expiration)
+	}
+	return nil
+}
+
+// MultiGet read the keys missing in L1 from L2, valueMap is keyed by the cache keys like the redis cache
+func (c *tieredCache) MultiGet(ctx context.Context, keys []string, valueMap interface{}) error {
+	values := reflect.ValueOf(valueMap)
+	var missKeys []string
+	for _, key := range keys {
+		cacheKey, err := cache.BuildCacheKey(c.keyPrefix, key)
+		if err != nil {
+			return err
+		}
+		data, ok := c.l1.get(cacheKey)
+		if !ok {
+			missKeys = append(missKeys, key)
+			continue
+		}
+		if bytes.Equal(data, cache.NotFoundPlaceholderBytes) {
+			cacheRequests.WithLabelValues("l1", "hit").Inc()
+			continue
+		}
+		object := c.newObject()
+		if err = encoding.Unmarshal(c.encoding, data, object); err != nil {
+			missKeys = append(missKeys, key)
+			continue
+		}
+		cacheRequests.WithLabelValues("l1", "hit").Inc()
+		values.SetMapIndex(reflect.ValueOf(cacheKey), reflect.ValueOf(object))
+	}
+	if len(missKeys) == 0 {
+		return nil
+	}
+	cacheRequests.WithLabelValues("l1", "miss").Add(float64(len(missKeys)))
+
+	if err := c.l2.MultiGet(ctx, missKeys, valueMap); err != nil {
+		return err
+	}
+	for _, key := range missKeys {
+		cacheKey, _ := cache.BuildCacheKey(c.keyPrefix, key)
+		v := values.MapIndex(reflect.ValueOf(cacheKey))
+		if !v.IsValid() {
+			cacheRequests.WithLabelValues("l2", "miss").Inc()
+			continue
+		}
+		cacheRequests.WithLabelValues("l2", "hit").Inc()
+		c.setL1(key, v.Interface(), TieredL1ExpireTime)
+	}
+	return nil
+}
+
+// Del delete from both tiers and tell the other instances to evict their L1
+func (c *tieredCache) Del(ctx context.Context, keys ...string) error {
+	if err := c.l2.Del(ctx, keys...); err != nil {
+		return err
+	}
+	cacheKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		cacheKey, err := cache.BuildCacheKey(c.keyPrefix, key)
+		if err != nil {
+			continue
+		}
+		c.l1.del(cacheKey)
+		cacheKeys = append(cacheKeys, cacheKey)
+	}
+
+	publishInvalidation(ctx, cacheKeys...)
+	return nil
+}
+
+// SetCacheWithNotFound set the placeholder in both tiers
+func (c *tieredCache) SetCacheWithNotFound(ctx context.Context, key string) error {
+	if err := c.l2.SetCacheWithNotFound(ctx, key); err != nil {
+		return err
+	}
+	if cacheKey, err := cache.BuildCacheKey(c.keyPrefix, key); err == nil {
+		c.l1.set(cacheKey, cache.NotFoundPlaceholderBytes, TieredL1ExpireTime)
+	}
+	return nil
+}
+
+func (c *tieredCache) setL1(key string, val interface{}, expiration time.Duration) {
+	cacheKey, err := cache.BuildCacheKey(c.keyPrefix, key)
+	if err != nil {
+		return
+	}
+	data, err := encoding.Marshal(c.encoding, val)
+	if err != nil || len(data) == 0 {
+		return
+	}
+	c.l1.set(cacheKey, data, expiration)
+}
+
+// ----------------------------------------------------------------------------
+
+// lruCache a size bounded LRU of encoded values with expiration
+type lruCache struct {
+	mu    sync.Mutex
+	size  int
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiredAt time.Time
+}
+
+func newLRU(size int) *lruCache {
+	return &lruCache{
+		size:  size,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+	}
+}
+
+func (l *lruCache) get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e, ok := l.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*lruEntry)
+	if time.Now().After(entry.expiredAt) {
+		l.remove(e)
+		return nil, false
+	}
+	l.ll.MoveToFront(e)
+	return entry.value, true
+}
+
+// set keep the value for the shorter of ttl and TieredL1ExpireTime, ttl 0 means TieredL1ExpireTime
+func (l *lruCache) set(key string, value []byte, ttl time.Duration) {
+	if ttl <= 0 || ttl > TieredL1ExpireTime {
+		ttl = TieredL1ExpireTime
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.items[key]; ok {
+		entry := e.Value.(*lruEntry)
+		entry.value = value
+		entry.expiredAt = time.Now().Add(ttl)
+		l.ll.MoveToFront(e)
+		return
+	}
+	l.items[key] = l.ll.PushFront(&lruEntry{key: key, value: value, expiredAt: time.Now().Add(ttl)})
+	for l.size > 0 && l.ll.Len() > l.size {
+		l.remove(l.ll.Back())
+	}
+}
+
+func (l *lruCache) del(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if e, ok := l.items[key]; ok {
+		l.remove(e)
+	}
+}
+
+func (l *lruCache) clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.ll.Init()
+	l.items = make(map[string]*list.Element)
+}
+
+func (l *lruCache) count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.ll.Len()
+}
+
+func (l *lruCache) remove(e *list.Element) {
+	l.ll.Remove(e)
+	delete(l.items, e.Value.(*lruEntry).key)
+}
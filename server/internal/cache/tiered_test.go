@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"admin/internal/database"
+	"admin/internal/model"
+)
+
+func Test_tieredCache(t *testing.T) {
+	c := gotest.NewCache(map[string]interface{}{})
+	defer c.Close()
+	tieredL1.clear()
+
+	iCache := NewPlatformCache(&database.CacheType{CType: "tiered", Rdb: c.RedisClient})
+	platform := &model.Platform{Username: "admin"}
+	platform.ID = 1
+	assert.NoError(t, iCache.Set(c.Ctx, platform.ID, platform, time.Minute))
+
+	l1Hits := testutil.ToFloat64(cacheRequests.WithLabelValues("l1", "hit"))
+	data, err := iCache.Get(c.Ctx, platform.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", data.Username)
+	assert.Equal(t, l1Hits+1, testutil.ToFloat64(cacheRequests.WithLabelValues("l1", "hit")))
+
+	// filled from redis after the local tier lost it
+	tieredL1.clear()
+	l2Hits := testutil.ToFloat64(cacheRequests.WithLabelValues("l2", "hit"))
+	data, err = iCache.Get(c.Ctx, platform.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", data.Username)
+	assert.Equal(t, l2Hits+1, testutil.ToFloat64(cacheRequests.WithLabelValues("l2", "hit")))
+	assert.Equal(t, 1, tieredL1.count())
+
+	// multi get mixes both tiers
+	platform2 := &model.Platform{Username: "shop"}
+	platform2.ID = 2
+	assert.NoError(t, iCache.Set(c.Ctx, platform2.ID, platform2, time.Minute))
+	tieredL1.del("platform:2")
+	items, err := iCache.MultiGet(c.Ctx, []uint64{1, 2, 3})
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.Equal(t, "shop", items[2].Username)
+
+	// deleted from both tiers
+	assert.NoError(t, iCache.Del(c.Ctx, platform.ID))
+	_, err = iCache.Get(c.Ctx, platform.ID)
+	assert.ErrorIs(t, err, database.ErrCacheNotFound)
+
+	assert.NoError(t, iCache.SetPlaceholder(c.Ctx, 3))
+	_, err = iCache.Get(c.Ctx, 3)
+	assert.True(t, iCache.IsPlaceholderErr(err))
+}
+
+func Test_lruCache(t *testing.T) {
+	l := newLRU(2)
+	l.set("a", []byte("1"), time.Minute)
+	l.set("b", []byte("2"), time.Minute)
+	_, ok := l.get("a")
+	assert.True(t, ok)
+	l.set("c", []byte("3"), time.Minute) // evicts b, the least recently used
+	_, ok = l.get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, l.count())
+
+	l.set("d", []byte("4"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	_, ok = l.get("d")
+	assert.False(t, ok)
+
+	l.clear()
+	assert.Equal(t, 0, l.count())
+}
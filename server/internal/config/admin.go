@@ -40,6 +40,7 @@ type Config struct {
 	Logger     Logger       `yaml:"logger" json:"logger"`
 	NacosRd    NacosRd      `yaml:"nacosRd" json:"nacosRd"`
 	Redis      Redis        `yaml:"redis" json:"redis"`
+	Storage    Storage      `yaml:"storage" json:"storage"`
 }
 
 type Consul struct {
@@ -77,6 +78,7 @@ type ServerSecure struct {
 }
 
 type App struct {
+	CacheInvalidation     bool    `yaml:"cacheInvalidation" json:"cacheInvalidation"`
 	CacheType             string  `yaml:"cacheType" json:"cacheType"`
 	EnableCircuitBreaker  bool    `yaml:"enableCircuitBreaker" json:"enableCircuitBreaker"`
 	EnableHTTPProfile     bool    `yaml:"enableHTTPProfile" json:"enableHTTPProfile"`
@@ -88,6 +90,7 @@ type App struct {
 	Host                  string  `yaml:"host" json:"host"`
 	Name                  string  `yaml:"name" json:"name"`
 	RegistryDiscoveryType string  `yaml:"registryDiscoveryType" json:"registryDiscoveryType"`
+	SyncMenus             bool    `yaml:"syncMenus" json:"syncMenus"`
 	TracingSamplingRate   float64 `yaml:"tracingSamplingRate" json:"tracingSamplingRate"`
 	Version               string  `yaml:"version" json:"version"`
 }
@@ -135,6 +138,27 @@ type Redis struct {
 	WriteTimeout int    `yaml:"writeTimeout" json:"writeTimeout"`
 }
 
+type Storage struct {
+	Local   LocalStorage `yaml:"local" json:"local"`
+	S3      S3Storage    `yaml:"s3" json:"s3"`
+	SignKey string       `yaml:"signKey" json:"signKey"`
+	Type    string       `yaml:"type" json:"type"`
+}
+
+type LocalStorage struct {
+	Root string `yaml:"root" json:"root"`
+}
+
+type S3Storage struct {
+	AccessKey string `yaml:"accessKey" json:"accessKey"`
+	Bucket    string `yaml:"bucket" json:"bucket"`
+	Endpoint  string `yaml:"endpoint" json:"endpoint"`
+	PathStyle bool   `yaml:"pathStyle" json:"pathStyle"`
+	Prefix    string `yaml:"prefix" json:"prefix"`
+	Region    string `yaml:"region" json:"region"`
+	SecretKey string `yaml:"secretKey" json:"secretKey"`
+}
+
 type Database struct {
 	Driver     string     `yaml:"driver" json:"driver"`
 	Mongodb    Mongodb    `yaml:"mongodb" json:"mongodb"`
@@ -2,4 +2,12 @@ package constant
 
 const (
 	ConfigKeyImageDomain = "imageDomain"
+	// ConfigKeyStorageDomain public domain of the storage backend (CDN, bucket domain), /uploads/* redirects to it when set
+	ConfigKeyStorageDomain = "storageDomain"
+	// ConfigKeyUploadPolicyPrefix upload policy of a category, e.g. uploadPolicy.avatar, see upload.Policy
+	ConfigKeyUploadPolicyPrefix = "uploadPolicy."
+	// ConfigKeyImagePipeline image processing options of uploaded images, see imageproc.Options
+	ConfigKeyImagePipeline = "imagePipeline"
+	// ConfigKeyUploadGC options of the orphaned upload garbage collection, see task.FileGCOptions
+	ConfigKeyUploadGC = "uploadGC"
 )
@@ -1,6 +1,6 @@
 package enum
 
 const (
-	BaseStatusDisable = iota // 禁用
-	BaseStatusNormal         // 正常
+	BaseStatusDisable = iota // 禁用 @en:Disabled
+	BaseStatusNormal         // 正常 @en:Normal
 )
@@ -0,0 +1,14 @@
+//go:build enumembed
+
+package enum
+
+import _ "embed"
+
+// written by go run ./cmd/enumgen -embed
+//
+//go:embed enum.json
+var embeddedJSON []byte
+
+func init() {
+	EmbeddedJSON = embeddedJSON
+}
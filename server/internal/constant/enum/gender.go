@@ -1,7 +1,7 @@
 package enum
 
 const (
-	GenderUnknown = iota // 保密
-	GenderMale           // 男
-	GenderFemale         // 女
+	GenderUnknown = iota // 保密 @en:Secret
+	GenderMale           // 男 @en:Male
+	GenderFemale         // 女 @en:Female
 )
@@ -0,0 +1,7 @@
+package enum
+
+//go:generate go run ../../../cmd/enumgen -dir . -json ../../../enum.json -ts ../../../../web/src/enums/generated/enum.gen.ts
+
+// EmbeddedJSON enum.json embedded into the binary, it is set only when built with -tags enumembed,
+// otherwise the enum cache reads enum.json from the working directory in prod
+var EmbeddedJSON []byte
@@ -1,5 +1,5 @@
 package enum
 
 const (
-	RoleCodeAdmin = "ADMIN" // 管理员
+	RoleCodeAdmin = "ADMIN" // 管理员 @en:Administrator
 )
@@ -1,6 +1,6 @@
 package enum
 
 const (
-	WhetherNo  = iota // 否
-	WhetherYes        // 是
+	WhetherNo  = iota // 否 @en:No
+	WhetherYes        // 是 @en:Yes
 )
@@ -0,0 +1,22 @@
+package constant
+
+// UploadPathPrefix url path prefix of uploaded files, the rest of the path is the storage key
+const UploadPathPrefix = "/uploads/"
+
+// business fields that reference uploaded files, see dao.FileDao.SyncRefs
+const (
+	FileRefPlatformAvatar = "platform.avatar"
+)
+
+// PrivateKeyPrefix storage key prefix of private files, they are not served under UploadPathPrefix
+// and can only be downloaded by signed urls
+const PrivateKeyPrefix = "private/"
+
+// DownloadPathPrefix url path prefix of signed downloads, the rest of the path is the file id
+const DownloadPathPrefix = "/api/v1/download/"
+
+// expiry of the signed download urls, unit(second)
+const (
+	SignedURLDefaultExpires = 1800
+	SignedURLMaxExpires     = 7 * 24 * 3600
+)
@@ -0,0 +1,74 @@
+package dao
+
+import (
+	"context"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"gorm.io/gorm"
+
+	"admin/internal/model"
+	"admin/internal/types"
+)
+
+var _ AuditLogDao = (*auditLogDao)(nil)
+
+// AuditLogDao defining the dao interface
+type AuditLogDao interface {
+	Create(ctx context.Context, table *model.AuditLog) error
+	GetByParams(ctx context.Context, params *types.ListAuditLogsRequest) ([]*model.AuditLog, int64, error)
+}
+
+// audit logs are only appended and listed, so they are not cached
+type auditLogDao struct {
+	db *gorm.DB
+}
+
+// NewAuditLogDao creating the dao interface
+func NewAuditLogDao(db *gorm.DB) AuditLogDao {
+	return &auditLogDao{db: db}
+}
+
+// Create a record, insert the record and the id value is written back to the table
+func (d *auditLogDao) Create(ctx context.Context, table *model.AuditLog) error {
+	return d.db.WithContext(ctx).Create(table).Error
+}
+
+// GetByParams get records by paging and conditions, the newest first by default
+func (d *auditLogDao) GetByParams(ctx context.Context, request *types.ListAuditLogsRequest) ([]*model.AuditLog, int64, error) {
+	sort := request.Sort
+	if sort == "" {
+		sort = "-id"
+	}
+	page := query.NewPage(request.Page-1, request.PageSize, sort)
+
+	db := d.db.WithContext(ctx).Model(&model.AuditLog{}).Order(page.Sort())
+	if request.StartTime != "" && request.EndTime != "" {
+		db = db.Where("created_at BETWEEN ? AND ?", request.StartTime, request.EndTime)
+	}
+	if request.Action != "" {
+		db = db.Where("action LIKE ?", request.Action+"%")
+	}
+	if request.AccountID != 0 {
+		db = db.Where("account_id = ?", request.AccountID)
+	}
+
+	var total int64 = 0
+	err := db.Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, total, nil
+	}
+
+	if request.PageSize > 0 {
+		db = db.Limit(page.Limit()).Offset(page.Page() * page.Limit())
+	}
+
+	records := []*model.AuditLog{}
+	err = db.Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return records, total, err
+}
@@ -6,6 +6,8 @@ import (
 	"admin/internal/types"
 	"context"
 	"errors"
+	"strconv"
+	"strings"
 
 	"github.com/go-dev-frame/sponge/pkg/logger"
 	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
@@ -27,13 +29,22 @@ type ConfigDao interface {
 	DeleteByIDs(ctx context.Context, ids []uint64) error
 	UpdateByID(ctx context.Context, table *model.Config) error
 	GetByID(ctx context.Context, id uint64) (*model.Config, error)
+	GetByKey(ctx context.Context, key string) (*model.Config, error)
 	GetByColumns(ctx context.Context, params *query.Params) ([]*model.Config, int64, error)
 	GetByParams(ctx context.Context, params *types.ListConfigsRequest) ([]*model.Config, int64, error)
+	GetByGroup(ctx context.Context, group string) ([]*model.Config, error)
+	GetPublic(ctx context.Context) ([]*model.Config, error)
+	WarmCache(ctx context.Context, limit int) (int, error)
 
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Config) (uint64, error)
 	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
 	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Config) error
 
+	GetString(ctx context.Context, key string, def string) string
+	GetInt(ctx context.Context, key string, def int64) int64
+	GetBool(ctx context.Context, key string, def bool) bool
+	GetFloat(ctx context.Context, key string, def float64) float64
+
 	MakePathByConfig(ctx context.Context, path, key string) string
 }
 
@@ -144,6 +155,18 @@ func (d *configDao) updateDataByID(ctx context.Context, db *gorm.DB, table *mode
 	if table.Value != "" {
 		update["value"] = table.Value
 	}
+	if table.Type != "" {
+		update["type"] = table.Type
+	}
+	if table.Constraints != nil {
+		update["constraints"] = table.Constraints
+	}
+	if table.Group != "" {
+		update["group"] = table.Group
+	}
+	if table.Public != nil {
+		update["public"] = *table.Public
+	}
 
 	return db.WithContext(ctx).Model(table).Updates(update).Error
 }
@@ -324,8 +347,10 @@ func (d *configDao) GetByParams(ctx context.Context, request *types.ListConfigsR
 	}
 
 	if request.Name != "" {
-		db = db.Where("name LIKE ?", "%"+request.Name+"%").
-			Or("`key` LIKE ?", "%"+request.Name+"%")
+		db = db.Where("name LIKE ? OR `key` LIKE ?", "%"+request.Name+"%", "%"+request.Name+"%")
+	}
+	if request.Group != "" {
+		db = db.Where("`group` = ?", request.Group)
 	}
 
 	var total int64 = 0
@@ -351,6 +376,44 @@ func (d *configDao) GetByParams(ctx context.Context, request *types.ListConfigsR
 	return records, total, err
 }
 
+// GetByGroup get all records of a group
+func (d *configDao) GetByGroup(ctx context.Context, group string) ([]*model.Config, error) {
+	records := []*model.Config{}
+	err := d.db.WithContext(ctx).Where("`group` = ?", group).Order("id").Find(&records).Error
+	return records, err
+}
+
+// GetPublic get all records that can be read without login
+func (d *configDao) GetPublic(ctx context.Context) ([]*model.Config, error) {
+	records := []*model.Config{}
+	err := d.db.WithContext(ctx).Where("public = ?", true).Order("id").Find(&records).Error
+	return records, err
+}
+
+// WarmCache load the latest records into the cache, return the number of loaded records
+func (d *configDao) WarmCache(ctx context.Context, limit int) (int, error) {
+	if d.cache == nil {
+		return 0, nil
+	}
+	records := []*model.Config{}
+	err := d.db.WithContext(ctx).Order("id DESC").Limit(limit).Find(&records).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	if err = d.cache.MultiSet(ctx, records, cache.ConfigExpireTime); err != nil {
+		return 0, err
+	}
+	for _, record := range records {
+		if err = d.cache.SetByKey(ctx, record.Key, record, cache.ConfigExpireTime); err != nil {
+			return 0, err
+		}
+	}
+	return len(records), nil
+}
+
 // CreateByTx create a record in the database using the provided transaction
 func (d *configDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Config) (uint64, error) {
 	err := tx.WithContext(ctx).Create(table).Error
@@ -385,11 +448,64 @@ func (d *configDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Co
 	return err
 }
 
-func (d *configDao) MakePathByConfig(ctx context.Context, path, key string) string {
-	host := ""
-	config, _ := d.GetByKey(ctx, key)
-	if config != nil {
-		host = config.Value
+// GetString value of the key, def is returned if the key does not exist or the value is empty
+func (d *configDao) GetString(ctx context.Context, key string, def string) string {
+	config, err := d.GetByKey(ctx, key)
+	if err != nil {
+		if !errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("GetByKey error", logger.Err(err), logger.String("key", key))
+		}
+		return def
+	}
+	if config.Value == "" {
+		return def
+	}
+	return config.Value
+}
+
+// GetInt value of the key as an integer, def is returned if the key does not exist or the value is not an integer
+func (d *configDao) GetInt(ctx context.Context, key string, def int64) int64 {
+	value := d.GetString(ctx, key, "")
+	if value == "" {
+		return def
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		logger.Warn("config value is not an integer", logger.String("key", key), logger.String("value", value))
+		return def
+	}
+	return v
+}
+
+// GetBool value of the key as a boolean, def is returned if the key does not exist or the value is not a boolean
+func (d *configDao) GetBool(ctx context.Context, key string, def bool) bool {
+	value := d.GetString(ctx, key, "")
+	if value == "" {
+		return def
+	}
+	v, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		logger.Warn("config value is not a boolean", logger.String("key", key), logger.String("value", value))
+		return def
+	}
+	return v
+}
+
+// GetFloat value of the key as a number, def is returned if the key does not exist or the value is not a number
+func (d *configDao) GetFloat(ctx context.Context, key string, def float64) float64 {
+	value := d.GetString(ctx, key, "")
+	if value == "" {
+		return def
 	}
-	return util.ImageMakePath(path, host)
+	v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		logger.Warn("config value is not a number", logger.String("key", key), logger.String("value", value))
+		return def
+	}
+	return v
+}
+
+// MakePathByConfig join path with the host configured by the key, path is returned as it is if the host is not configured
+func (d *configDao) MakePathByConfig(ctx context.Context, path, key string) string {
+	return util.ImageMakePath(path, d.GetString(ctx, key, ""))
 }
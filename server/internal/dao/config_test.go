@@ -247,3 +247,55 @@ func Test_configDao_UpdateByTx(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func Test_configDao_TypedGetters(t *testing.T) {
+	d := newConfigDao()
+	defer d.Close()
+	iDao := NewConfigDao(d.DB, nil)
+
+	expectValue := func(key string, value string) {
+		d.SQLMock.ExpectQuery("SELECT .*").
+			WithArgs(key, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "key", "value"}).AddRow(1, key, value))
+	}
+
+	expectValue("pageSize", " 20")
+	assert.Equal(t, int64(20), iDao.GetInt(d.Ctx, "pageSize", 10))
+	expectValue("pageSize", "abc")
+	assert.Equal(t, int64(10), iDao.GetInt(d.Ctx, "pageSize", 10))
+	expectValue("register", "true")
+	assert.True(t, iDao.GetBool(d.Ctx, "register", false))
+	expectValue("register", "")
+	assert.True(t, iDao.GetBool(d.Ctx, "register", true))
+	expectValue("ratio", "0.5")
+	assert.Equal(t, 0.5, iDao.GetFloat(d.Ctx, "ratio", 1))
+	expectValue("imageDomain", "http://127.0.0.1:9501/")
+	assert.Equal(t, "http://127.0.0.1:9501/uploads/a.png", iDao.MakePathByConfig(d.Ctx, "/uploads/a.png", "imageDomain"))
+
+	// not found
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs("siteName", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	assert.Equal(t, "admin", iDao.GetString(d.Ctx, "siteName", "admin"))
+}
+
+func Test_configDao_GetByGroup(t *testing.T) {
+	d := newConfigDao()
+	defer d.Close()
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs("site").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "key", "group"}).AddRow(2, "imageDomain", "site").AddRow(7, "siteName", "site"))
+	records, err := d.IDao.(ConfigDao).GetByGroup(d.Ctx, "site")
+	assert.NoError(t, err)
+	assert.Len(t, records, 2)
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(true).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "key", "public"}).AddRow(7, "siteName", true))
+	records, err = d.IDao.(ConfigDao).GetPublic(d.Ctx)
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.True(t, *records[0].Public)
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+}
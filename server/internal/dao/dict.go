@@ -0,0 +1,180 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"gorm.io/gorm"
+
+	"admin/internal/constant/enum"
+	"admin/internal/model"
+	"admin/internal/types"
+)
+
+var _ DictDao = (*dictDao)(nil)
+
+// value types of the dictionaries
+const (
+	DictValueTypeString = "string"
+	DictValueTypeInt    = "int"
+)
+
+// DictDao defining the dao interface
+type DictDao interface {
+	Create(ctx context.Context, table *model.Dict) error
+	DeleteByID(ctx context.Context, id uint64) error
+	UpdateByID(ctx context.Context, table *model.Dict) error
+	GetByID(ctx context.Context, id uint64) (*model.Dict, error)
+	GetByCode(ctx context.Context, code string) (*model.Dict, error)
+	GetByParams(ctx context.Context, params *types.ListDictsRequest) ([]*model.Dict, int64, error)
+	GetOptions(ctx context.Context) (map[string][]*types.Options, error)
+}
+
+// dictionaries are read through the enum cache, which is reloaded after they are changed, so they are not cached here
+type dictDao struct {
+	db *gorm.DB
+}
+
+// NewDictDao creating the dao interface
+func NewDictDao(db *gorm.DB) DictDao {
+	return &dictDao{db: db}
+}
+
+// Create a record, insert the record and the id value is written back to the table
+func (d *dictDao) Create(ctx context.Context, table *model.Dict) error {
+	return d.db.WithContext(ctx).Create(table).Error
+}
+
+// DeleteByID delete a record and its items by id
+func (d *dictDao) DeleteByID(ctx context.Context, id uint64) error {
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("dict_id = ?", id).Delete(&model.DictItem{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.Dict{}).Error
+	})
+}
+
+// UpdateByID update a record by id
+func (d *dictDao) UpdateByID(ctx context.Context, table *model.Dict) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+
+	update := map[string]interface{}{}
+
+	if table.Name != "" {
+		update["name"] = table.Name
+	}
+	if table.Code != "" {
+		update["code"] = table.Code
+	}
+	if table.ValueType != "" {
+		update["value_type"] = table.ValueType
+	}
+	if table.Description != "" {
+		update["description"] = table.Description
+	}
+	if table.Status != nil {
+		update["status"] = *table.Status
+	}
+
+	return d.db.WithContext(ctx).Model(table).Updates(update).Error
+}
+
+// GetByID get a record by id
+func (d *dictDao) GetByID(ctx context.Context, id uint64) (*model.Dict, error) {
+	record := &model.Dict{}
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(record).Error
+	return record, err
+}
+
+// GetByCode get a record by code
+func (d *dictDao) GetByCode(ctx context.Context, code string) (*model.Dict, error) {
+	record := &model.Dict{}
+	err := d.db.WithContext(ctx).Where("code = ?", code).First(record).Error
+	return record, err
+}
+
+// GetByParams get records by paging and conditions
+func (d *dictDao) GetByParams(ctx context.Context, request *types.ListDictsRequest) ([]*model.Dict, int64, error) {
+	page := query.NewPage(request.Page-1, request.PageSize, request.Sort)
+
+	db := d.db.WithContext(ctx).Model(&model.Dict{}).Order(page.Sort())
+	if request.Name != "" {
+		db = db.Where("name LIKE ? OR code LIKE ?", "%"+request.Name+"%", "%"+request.Name+"%")
+	}
+	if request.Status != nil {
+		db = db.Where("status = ?", *request.Status)
+	}
+
+	var total int64 = 0
+	if request.Sort != "ignore count" { // determine if count is required
+		err := db.Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	if request.PageSize > 0 {
+		db = db.Limit(page.Limit()).Offset(page.Page() * page.Limit())
+	}
+
+	records := []*model.Dict{}
+	err := db.Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return records, total, err
+}
+
+// GetOptions the enabled items of the enabled dictionaries as options keyed by dictionary code, it is the
+// loader of the enum cache. the values of the int dictionaries are converted to numbers like the code enums,
+// the color and tag type are put in the other of the options
+func (d *dictDao) GetOptions(ctx context.Context) (map[string][]*types.Options, error) {
+	dicts := []*model.Dict{}
+	err := d.db.WithContext(ctx).Where("status = ?", enum.BaseStatusNormal).Find(&dicts).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string][]*types.Options, len(dicts))
+	if len(dicts) == 0 {
+		return result, nil
+	}
+
+	dictMap := make(map[uint64]*model.Dict, len(dicts))
+	ids := make([]uint64, 0, len(dicts))
+	for _, dict := range dicts {
+		dictMap[dict.ID] = dict
+		ids = append(ids, dict.ID)
+		result[dict.Code] = []*types.Options{}
+	}
+
+	items := []*model.DictItem{}
+	err = d.db.WithContext(ctx).Where("dict_id IN (?) AND status = ?", ids, enum.BaseStatusNormal).
+		Order("sort, id").Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		dict := dictMap[item.DictID]
+		option := &types.Options{Label: item.Label, Value: item.Value, Labels: item.Labels}
+		if dict.ValueType == DictValueTypeInt {
+			if v, err := strconv.Atoi(item.Value); err == nil {
+				option.Value = v
+			}
+		}
+		if item.Color != "" || item.TagType != "" {
+			option.Other = &types.DictItemOther{Color: item.Color, TagType: item.TagType}
+		}
+		result[dict.Code] = append(result[dict.Code], option)
+	}
+
+	return result, nil
+}
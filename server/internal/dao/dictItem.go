@@ -0,0 +1,88 @@
+package dao
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+
+	"admin/internal/model"
+)
+
+var _ DictItemDao = (*dictItemDao)(nil)
+
+// DictItemDao defining the dao interface
+type DictItemDao interface {
+	Create(ctx context.Context, table *model.DictItem) error
+	DeleteByID(ctx context.Context, id uint64) error
+	UpdateByID(ctx context.Context, table *model.DictItem) error
+	GetByID(ctx context.Context, id uint64) (*model.DictItem, error)
+	GetByDictID(ctx context.Context, dictID uint64) ([]*model.DictItem, error)
+}
+
+// dictionary items are read through the enum cache like the dictionaries, so they are not cached here
+type dictItemDao struct {
+	db *gorm.DB
+}
+
+// NewDictItemDao creating the dao interface
+func NewDictItemDao(db *gorm.DB) DictItemDao {
+	return &dictItemDao{db: db}
+}
+
+// Create a record, insert the record and the id value is written back to the table
+func (d *dictItemDao) Create(ctx context.Context, table *model.DictItem) error {
+	return d.db.WithContext(ctx).Create(table).Error
+}
+
+// DeleteByID delete a record by id
+func (d *dictItemDao) DeleteByID(ctx context.Context, id uint64) error {
+	return d.db.WithContext(ctx).Where("id = ?", id).Delete(&model.DictItem{}).Error
+}
+
+// UpdateByID update a record by id, the dictionary of an item can not be changed
+func (d *dictItemDao) UpdateByID(ctx context.Context, table *model.DictItem) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+
+	update := map[string]interface{}{}
+
+	if table.Label != "" {
+		update["label"] = table.Label
+	}
+	if table.Value != "" {
+		update["value"] = table.Value
+	}
+	if table.Sort != 0 {
+		update["sort"] = table.Sort
+	}
+	if table.Status != nil {
+		update["status"] = *table.Status
+	}
+	if table.Color != "" {
+		update["color"] = table.Color
+	}
+	if table.TagType != "" {
+		update["tag_type"] = table.TagType
+	}
+	if table.Labels != nil {
+		update["labels"] = table.Labels
+	}
+
+	return d.db.WithContext(ctx).Model(table).Updates(update).Error
+}
+
+// GetByID get a record by id
+func (d *dictItemDao) GetByID(ctx context.Context, id uint64) (*model.DictItem, error) {
+	record := &model.DictItem{}
+	err := d.db.WithContext(ctx).Where("id = ?", id).First(record).Error
+	return record, err
+}
+
+// GetByDictID get all items of a dictionary in order
+func (d *dictItemDao) GetByDictID(ctx context.Context, dictID uint64) ([]*model.DictItem, error) {
+	records := []*model.DictItem{}
+	err := d.db.WithContext(ctx).Where("dict_id = ?", dictID).Order("sort, id").Find(&records).Error
+	return records, err
+}
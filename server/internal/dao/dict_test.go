@@ -0,0 +1,132 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/stretchr/testify/assert"
+
+	"admin/internal/model"
+	"admin/internal/types"
+)
+
+func newDictDao() *gotest.Dao {
+	testData := &model.Dict{Code: "color", ValueType: DictValueTypeInt}
+	testData.ID = 1
+
+	// dictionaries are not cached
+	d := gotest.NewDao(nil, testData)
+	d.IDao = NewDictDao(d.DB)
+
+	return d
+}
+
+func Test_dictDao_Create(t *testing.T) {
+	d := newDictDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Dict)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(DictDao).Create(d.Ctx, testData)
+	assert.NoError(t, err)
+}
+
+func Test_dictDao_DeleteByID(t *testing.T) {
+	d := newDictDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Dict)
+
+	// the items are deleted with the dictionary
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE `t_dict_item` SET `deleted_at`.*").
+		WithArgs(d.AnyTime, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	d.SQLMock.ExpectExec("UPDATE `t_dict` SET `deleted_at`.*").
+		WithArgs(d.AnyTime, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(DictDao).DeleteByID(d.Ctx, testData.ID)
+	assert.NoError(t, err)
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+}
+
+func Test_dictDao_UpdateByID(t *testing.T) {
+	d := newDictDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Dict)
+	status := 0
+	testData.Status = &status
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(DictDao).UpdateByID(d.Ctx, testData)
+	assert.NoError(t, err)
+
+	err = d.IDao.(DictDao).UpdateByID(d.Ctx, &model.Dict{})
+	assert.Error(t, err)
+}
+
+func Test_dictDao_GetByParams(t *testing.T) {
+	d := newDictDao()
+	defer d.Close()
+	testData := d.TestData.(*model.Dict)
+
+	d.SQLMock.ExpectQuery("SELECT count.*").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code"}).AddRow(testData.ID, testData.Code))
+
+	records, total, err := d.IDao.(DictDao).GetByParams(d.Ctx, &types.ListDictsRequest{Page: 1, PageSize: 10, Name: "co"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	assert.Len(t, records, 1)
+}
+
+func Test_dictDao_GetOptions(t *testing.T) {
+	d := newDictDao()
+	defer d.Close()
+
+	d.SQLMock.ExpectQuery("SELECT \\* FROM `t_dict` WHERE status = .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "value_type"}).
+			AddRow(1, "color", DictValueTypeInt).
+			AddRow(2, "channel", DictValueTypeString).
+			AddRow(3, "empty", DictValueTypeString))
+	d.SQLMock.ExpectQuery("SELECT \\* FROM `t_dict_item` WHERE \\(dict_id IN .* ORDER BY sort, id").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dict_id", "label", "value", "color", "tag_type"}).
+			AddRow(1, 1, "红", "1", "#f00", "danger").
+			AddRow(2, 1, "绿", "2", "", "").
+			AddRow(3, 2, "官网", "web", "", "info"))
+
+	options, err := d.IDao.(DictDao).GetOptions(d.Ctx)
+	assert.NoError(t, err)
+	assert.Len(t, options, 3)
+	assert.Len(t, options["color"], 2)
+	assert.Equal(t, 1, options["color"][0].Value)
+	assert.Equal(t, &types.DictItemOther{Color: "#f00", TagType: "danger"}, options["color"][0].Other)
+	assert.Nil(t, options["color"][1].Other)
+	assert.Equal(t, "web", options["channel"][0].Value)
+	assert.Empty(t, options["empty"])
+}
+
+func Test_dictDao_GetOptionsEmpty(t *testing.T) {
+	d := newDictDao()
+	defer d.Close()
+
+	// no item query when there is no enabled dictionary
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	options, err := d.IDao.(DictDao).GetOptions(d.Ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, options)
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+}
@@ -0,0 +1,553 @@
+package dao
+
+import (
+	"admin/internal/database"
+	"admin/internal/types"
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"golang.org/x/sync/singleflight"
+	"gorm.io/gorm"
+
+	"admin/internal/cache"
+	"admin/internal/constant"
+	"admin/internal/model"
+)
+
+var _ FileDao = (*fileDao)(nil)
+
+// FileDao defining the dao interface
+type FileDao interface {
+	Create(ctx context.Context, table *model.File) error
+	CreateOrGet(ctx context.Context, table *model.File) (*model.File, bool, error)
+	DeleteByID(ctx context.Context, id uint64) error
+	DeleteByIDs(ctx context.Context, ids []uint64) error
+	UpdateByID(ctx context.Context, table *model.File) error
+	GetByID(ctx context.Context, id uint64) (*model.File, error)
+	GetByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.File, error)
+	GetByColumns(ctx context.Context, params *query.Params) ([]*model.File, int64, error)
+	GetByParams(ctx context.Context, params *types.ListFilesRequest) ([]*model.File, int64, error)
+	GetByHash(ctx context.Context, hash string, private bool, ownerID uint64) (*model.File, error)
+	GetByStorageKeys(ctx context.Context, keys []string) ([]*model.File, error)
+
+	SyncRefs(ctx context.Context, biz string, bizID uint64, storageKeys ...string) error
+	DeleteRefs(ctx context.Context, biz string, bizIDs []uint64) error
+	CountRefs(ctx context.Context, fileIDs []uint64) (map[uint64]int64, error)
+	GetFieldPaths(ctx context.Context) ([]string, error)
+	GetFieldPathsOf(ctx context.Context, key string) ([]string, error)
+	Touch(ctx context.Context, id uint64) error
+
+	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.File) (uint64, error)
+	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
+	UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.File) error
+}
+
+type fileDao struct {
+	db    *gorm.DB
+	cache cache.FileCache     // if nil, the cache is not used.
+	sfg   *singleflight.Group // if cache is nil, the sfg is not used.
+}
+
+// NewFileDao creating the dao interface
+func NewFileDao(db *gorm.DB, xCache cache.FileCache) FileDao {
+	if xCache == nil {
+		return &fileDao{db: db}
+	}
+	return &fileDao{
+		db:    db,
+		cache: xCache,
+		sfg:   new(singleflight.Group),
+	}
+}
+
+func (d *fileDao) deleteCache(ctx context.Context, id uint64) error {
+	if d.cache != nil {
+		return d.cache.Del(ctx, id)
+	}
+	return nil
+}
+
+// Create a record, insert the record and the id value is written back to the table
+func (d *fileDao) Create(ctx context.Context, table *model.File) error {
+	return d.db.WithContext(ctx).Create(table).Error
+}
+
+// CreateOrGet create a record, if a record with the same hash was created concurrently the existing
+// record is returned instead, created reports whether the table was inserted
+func (d *fileDao) CreateOrGet(ctx context.Context, table *model.File) (*model.File, bool, error) {
+	err := d.db.WithContext(ctx).Create(table).Error
+	if err == nil {
+		return table, true, nil
+	}
+	if !database.IsDuplicateKey(err) {
+		return nil, false, err
+	}
+	record, err := d.GetByHash(ctx, table.Hash, table.Private, table.OwnerID)
+	if err != nil {
+		return nil, false, err
+	}
+	return record, false, nil
+}
+
+// DeleteByID delete a record by id, the record is removed permanently together with its object,
+// a soft deleted record would keep occupying the unique hash
+func (d *fileDao) DeleteByID(ctx context.Context, id uint64) error {
+	err := d.db.WithContext(ctx).Unscoped().Where("id = ?", id).Delete(&model.File{}).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// DeleteByIDs delete records by batch id
+func (d *fileDao) DeleteByIDs(ctx context.Context, ids []uint64) error {
+	err := d.db.WithContext(ctx).Unscoped().Where("id IN (?)", ids).Delete(&model.File{}).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	for _, id := range ids {
+		_ = d.deleteCache(ctx, id)
+	}
+
+	return nil
+}
+
+// UpdateByID update a record by id
+func (d *fileDao) UpdateByID(ctx context.Context, table *model.File) error {
+	err := d.updateDataByID(ctx, d.db, table)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
+
+func (d *fileDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.File) error {
+	if table.ID < 1 {
+		return errors.New("id cannot be 0")
+	}
+
+	update := map[string]interface{}{}
+
+	if table.Name != "" {
+		update["name"] = table.Name
+	}
+	if table.UploaderID != 0 {
+		update["uploader_id"] = table.UploaderID
+	}
+	if table.Size != 0 {
+		update["size"] = table.Size
+	}
+	if table.MimeType != "" {
+		update["mime_type"] = table.MimeType
+	}
+	if table.Hash != "" {
+		update["hash"] = table.Hash
+	}
+	if table.StorageKey != "" {
+		update["storage_key"] = table.StorageKey
+	}
+	if table.Category != "" {
+		update["category"] = table.Category
+	}
+
+	return db.WithContext(ctx).Model(table).Updates(update).Error
+}
+
+// GetByID get a record by id
+func (d *fileDao) GetByID(ctx context.Context, id uint64) (*model.File, error) {
+	// no cache
+	if d.cache == nil {
+		record := &model.File{}
+		err := d.db.WithContext(ctx).Where("id = ?", id).First(record).Error
+		return record, err
+	}
+
+	// get from cache or database
+	record, err := d.cache.Get(ctx, id)
+	if err == nil {
+		return record, nil
+	}
+
+	// get from database
+	if errors.Is(err, database.ErrCacheNotFound) {
+		// for the same id, prevent high concurrent simultaneous access to database
+		val, err, _ := d.sfg.Do(utils.Uint64ToStr(id), func() (interface{}, error) { //nolint
+			table := &model.File{}
+			err = d.db.WithContext(ctx).Where("id = ?", id).First(table).Error
+			if err != nil {
+				if errors.Is(err, database.ErrRecordNotFound) {
+					// set placeholder cache to prevent cache penetration, default expiration time 10 minutes
+					if err = d.cache.SetPlaceholder(ctx, id); err != nil {
+						logger.Warn("cache.SetPlaceholder error", logger.Err(err), logger.Any("id", id))
+					}
+					return nil, database.ErrRecordNotFound
+				}
+				return nil, err
+			}
+			// set cache
+			if err = d.cache.Set(ctx, id, table, cache.FileExpireTime); err != nil {
+				logger.Warn("cache.Set error", logger.Err(err), logger.Any("id", id))
+			}
+			return table, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		table, ok := val.(*model.File)
+		if !ok {
+			return nil, database.ErrRecordNotFound
+		}
+		return table, nil
+	}
+
+	if d.cache.IsPlaceholderErr(err) {
+		return nil, database.ErrRecordNotFound
+	}
+
+	// fail fast, if cache error return, don't request to db
+	return nil, err
+}
+
+// GetByIDs get records by batch id
+func (d *fileDao) GetByIDs(ctx context.Context, ids []uint64) (map[uint64]*model.File, error) {
+	// no cache
+	if d.cache == nil {
+		var records []*model.File
+		err := d.db.WithContext(ctx).Where("id IN (?)", ids).Find(&records).Error
+		if err != nil {
+			return nil, err
+		}
+		itemMap := make(map[uint64]*model.File)
+		for _, record := range records {
+			itemMap[record.ID] = record
+		}
+		return itemMap, nil
+	}
+
+	// get form cache
+	itemMap, err := d.cache.MultiGet(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var missedIDs []uint64
+	for _, id := range ids {
+		if _, ok := itemMap[id]; !ok {
+			missedIDs = append(missedIDs, id)
+		}
+	}
+
+	// get missed data
+	if len(missedIDs) > 0 {
+		// find the id of an active placeholder, i.e. an id that does not exist in database
+		var realMissedIDs []uint64
+		for _, id := range missedIDs {
+			_, err = d.cache.Get(ctx, id)
+			if d.cache.IsPlaceholderErr(err) {
+				continue
+			}
+			realMissedIDs = append(realMissedIDs, id)
+		}
+
+		// get missed id from database
+		if len(realMissedIDs) > 0 {
+			var records []*model.File
+			var recordIDMap = make(map[uint64]struct{})
+			err = d.db.WithContext(ctx).Where("id IN (?)", realMissedIDs).Find(&records).Error
+			if err != nil {
+				return nil, err
+			}
+			if len(records) > 0 {
+				for _, record := range records {
+					itemMap[record.ID] = record
+					recordIDMap[record.ID] = struct{}{}
+				}
+				if err = d.cache.MultiSet(ctx, records, cache.FileExpireTime); err != nil {
+					logger.Warn("cache.MultiSet error", logger.Err(err), logger.Any("ids", records))
+				}
+				if len(records) == len(realMissedIDs) {
+					return itemMap, nil
+				}
+			}
+			for _, id := range realMissedIDs {
+				if _, ok := recordIDMap[id]; !ok {
+					if err = d.cache.SetPlaceholder(ctx, id); err != nil {
+						logger.Warn("cache.SetPlaceholder error", logger.Err(err), logger.Any("id", id))
+					}
+				}
+			}
+		}
+	}
+
+	return itemMap, nil
+}
+
+// GetByHash get a record by content hash, used for deduplication,
+// public and private files are deduplicated separately so a private upload is never served publicly,
+// private files are only deduplicated within their owner, ownerID is 0 for public files
+func (d *fileDao) GetByHash(ctx context.Context, hash string, private bool, ownerID uint64) (*model.File, error) {
+	record := &model.File{}
+	err := d.db.WithContext(ctx).Where("hash = ? AND private = ? AND owner_id = ?", hash, private, ownerID).First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Touch refresh updated_at of a record reused by an upload of the same content, the garbage collection
+// keeps it for another grace period
+func (d *fileDao) Touch(ctx context.Context, id uint64) error {
+	err := d.db.WithContext(ctx).Model(&model.File{}).Where("id = ?", id).Update("updated_at", time.Now()).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// GetByStorageKeys get records by storage keys
+func (d *fileDao) GetByStorageKeys(ctx context.Context, keys []string) ([]*model.File, error) {
+	var records []*model.File
+	if len(keys) == 0 {
+		return records, nil
+	}
+	err := d.db.WithContext(ctx).Where("storage_key IN (?)", keys).Find(&records).Error
+	return records, err
+}
+
+// SyncRefs replace the files referenced by a business field, e.g. SyncRefs(ctx, "platform.avatar", 1, "2024-11-10/a.png"),
+// keys that are not registered files (external urls, legacy uploads) are ignored
+func (d *fileDao) SyncRefs(ctx context.Context, biz string, bizID uint64, storageKeys ...string) error {
+	files, err := d.GetByStorageKeys(ctx, storageKeys)
+	if err != nil {
+		return err
+	}
+
+	return d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Unscoped().Where("biz = ? AND biz_id = ?", biz, bizID).Delete(&model.FileRef{}).Error
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			return nil
+		}
+		refs := make([]*model.FileRef, 0, len(files))
+		for _, file := range files {
+			refs = append(refs, &model.FileRef{FileID: file.ID, Biz: biz, BizID: bizID})
+		}
+		return tx.Create(&refs).Error
+	})
+}
+
+// DeleteRefs delete the file references of business records
+func (d *fileDao) DeleteRefs(ctx context.Context, biz string, bizIDs []uint64) error {
+	return d.db.WithContext(ctx).Unscoped().Where("biz = ? AND biz_id IN (?)", biz, bizIDs).Delete(&model.FileRef{}).Error
+}
+
+// CountRefs count references of files, files without references are not in the map
+func (d *fileDao) CountRefs(ctx context.Context, fileIDs []uint64) (map[uint64]int64, error) {
+	var rows []struct {
+		FileID uint64
+		Total  int64
+	}
+	err := d.db.WithContext(ctx).Model(&model.FileRef{}).
+		Select("file_id, COUNT(*) AS total").
+		Where("file_id IN (?)", fileIDs).
+		Group("file_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint64]int64, len(rows))
+	for _, row := range rows {
+		counts[row.FileID] = row.Total
+	}
+	return counts, nil
+}
+
+// fileFields model and config fields that hold upload paths, files referenced by them are never collected as
+// orphans, a field may hold a path, an url or a text embedding them such as the json values of t_config.
+// add the field here when a new model stores upload paths
+var fileFields = []struct {
+	table  string
+	column string
+}{
+	{table: "t_platform", column: "avatar"},
+	{table: "t_config", column: "value"}, // e.g. siteLogo, the images of the json values
+	{table: "t_menu", column: "icon"},    // an icon may be an uploaded image
+}
+
+// GetFieldPaths the values of the model fields of not deleted records which contain upload paths or urls
+func (d *fileDao) GetFieldPaths(ctx context.Context) ([]string, error) {
+	// matches /uploads/ and the escaped \/uploads\/ of json
+	return d.getFieldValues(ctx, strings.Trim(constant.UploadPathPrefix, "/"))
+}
+
+// GetFieldPathsOf the values of the model fields which may hold the storage key, they are matched by the
+// file name because json escapes the slashes, the caller finds the key in them
+func (d *fileDao) GetFieldPathsOf(ctx context.Context, key string) ([]string, error) {
+	return d.getFieldValues(ctx, path.Base(key))
+}
+
+func (d *fileDao) getFieldValues(ctx context.Context, contains string) ([]string, error) {
+	var paths []string
+	like := "%" + contains + "%"
+	for _, field := range fileFields {
+		var values []string
+		err := d.db.WithContext(ctx).Table(field.table).
+			Where("deleted_at IS NULL AND `"+field.column+"` LIKE ?", like).
+			Distinct().Pluck(field.column, &values).Error
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, values...)
+	}
+	return paths, nil
+}
+
+// GetByColumns get paging records by column information,
+// Note: query performance degrades when table rows are very large because of the use of offset.
+//
+// params includes paging parameters and query parameters
+// paging parameters (required):
+//
+//	page: page number, starting from 0
+//	limit: lines per page
+//	sort: sort fields, default is id backwards, you can add - sign before the field to indicate reverse order, no - sign to indicate ascending order, multiple fields separated by comma
+//
+// query parameters (not required):
+//
+//	name: column name
+//	exp: expressions, which default is "=",  support =, !=, >, >=, <, <=, like, in
+//	value: column value, if exp=in, multiple values are separated by commas
+//	logic: logical type, defaults to and when value is null, only &(and), ||(or)
+//
+// example: search for a male over 20 years of age
+//
+//	params = &query.Params{
+//	    Page: 0,
+//	    Limit: 20,
+//	    Columns: []query.Column{
+//		{
+//			Name:    "age",
+//			Exp: ">",
+//			Value:   20,
+//		},
+//		{
+//			Name:  "gender",
+//			Value: "male",
+//		},
+//	}
+func (d *fileDao) GetByColumns(ctx context.Context, params *query.Params) ([]*model.File, int64, error) {
+	queryStr, args, err := params.ConvertToGormConditions()
+	if err != nil {
+		return nil, 0, errors.New("query params error: " + err.Error())
+	}
+
+	var total int64
+	if params.Sort != "ignore count" { // determine if count is required
+		err = d.db.WithContext(ctx).Model(&model.File{}).Where(queryStr, args...).Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	records := []*model.File{}
+	order, limit, offset := params.ConvertToPage()
+	err = d.db.WithContext(ctx).Order(order).Limit(limit).Offset(offset).Where(queryStr, args...).Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return records, total, err
+}
+
+func (d *fileDao) GetByParams(ctx context.Context, request *types.ListFilesRequest) ([]*model.File, int64, error) {
+	page := query.NewPage(request.Page-1, request.PageSize, request.Sort)
+
+	db := d.db.WithContext(ctx).Model(&model.File{}).Order(page.Sort())
+	if request.StartTime != "" && request.EndTime != "" {
+		db = db.Where("created_at BETWEEN ? AND ?", request.StartTime, request.EndTime)
+	}
+	if request.Name != "" {
+		db = db.Where("name LIKE ?", "%"+request.Name+"%")
+	}
+	if request.MimeType != "" {
+		db = db.Where("mime_type LIKE ?", request.MimeType+"%")
+	}
+	if request.UploaderID != 0 {
+		db = db.Where("uploader_id = ?", request.UploaderID)
+	}
+
+	var total int64 = 0
+	if request.Sort != "ignore count" { // determine if count is required
+		err := db.Count(&total).Error
+		if err != nil {
+			return nil, 0, err
+		}
+		if total == 0 {
+			return nil, total, nil
+		}
+	}
+
+	if request.PageSize > 0 {
+		db = db.Limit(page.Limit()).Offset(page.Page() * page.Limit())
+	}
+
+	records := []*model.File{}
+	err := db.Find(&records).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return records, total, err
+}
+
+// CreateByTx create a record in the database using the provided transaction
+func (d *fileDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.File) (uint64, error) {
+	err := tx.WithContext(ctx).Create(table).Error
+	return table.ID, err
+}
+
+// DeleteByTx delete a record by id in the database using the provided transaction
+func (d *fileDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error {
+	err := tx.WithContext(ctx).Unscoped().Where("id = ?", id).Delete(&model.File{}).Error
+	if err != nil {
+		return err
+	}
+
+	// delete cache
+	_ = d.deleteCache(ctx, id)
+
+	return nil
+}
+
+// UpdateByTx update a record by id in the database using the provided transaction
+func (d *fileDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.File) error {
+	err := d.updateDataByID(ctx, tx, table)
+
+	// delete cache
+	_ = d.deleteCache(ctx, table.ID)
+
+	return err
+}
@@ -0,0 +1,397 @@
+package dao
+
+import (
+	"admin/internal/database"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+
+	"admin/internal/cache"
+	"admin/internal/model"
+)
+
+func newFileDao() *gotest.Dao {
+	testData := &model.File{}
+	testData.ID = 1
+	// you can set the other fields of testData here, such as:
+	//testData.CreatedAt = time.Now()
+	//testData.UpdatedAt = testData.CreatedAt
+
+	// init mock cache
+	//c := gotest.NewCache(map[string]interface{}{"no cache": testData}) // to test mysql, disable caching
+	c := gotest.NewCache(map[string]interface{}{utils.Uint64ToStr(testData.ID): testData})
+	c.ICache = cache.NewFileCache(&database.CacheType{
+		CType: "redis",
+		Rdb:   c.RedisClient,
+	})
+
+	// init mock dao
+	d := gotest.NewDao(c, testData)
+	d.IDao = NewFileDao(d.DB, c.ICache.(cache.FileCache))
+
+	return d
+}
+
+func Test_fileDao_Create(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+	testData := d.TestData.(*model.File)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WithArgs(d.GetAnyArgs(testData)...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(FileDao).Create(d.Ctx, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_fileDao_CreateOrGet(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+	testData := d.TestData.(*model.File)
+	testData.Hash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+	record, created, err := d.IDao.(FileDao).CreateOrGet(d.Ctx, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, created)
+	assert.Equal(t, testData, record)
+
+	// uploaded concurrently, the existing record is reused
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry"})
+	d.SQLMock.ExpectRollback()
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.Hash, false, 0, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hash", "storage_key"}).AddRow(2, testData.Hash, "2024-11-10/a.png"))
+	record, created, err = d.IDao.(FileDao).CreateOrGet(d.Ctx, &model.File{Hash: testData.Hash})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, created)
+	assert.Equal(t, uint64(2), record.ID)
+	assert.Equal(t, "2024-11-10/a.png", record.StorageKey)
+
+	// other errors are returned
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WillReturnError(errors.New("insert error"))
+	d.SQLMock.ExpectRollback()
+	_, _, err = d.IDao.(FileDao).CreateOrGet(d.Ctx, &model.File{Hash: testData.Hash})
+	assert.Error(t, err)
+}
+
+func Test_fileDao_DeleteByID(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+	testData := d.TestData.(*model.File)
+	expectedSQLForDeletion := "DELETE FROM .*"
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec(expectedSQLForDeletion).
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(FileDao).DeleteByID(d.Ctx, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// zero id error
+	err = d.IDao.(FileDao).DeleteByID(d.Ctx, 0)
+	assert.Error(t, err)
+}
+
+func Test_fileDao_UpdateByID(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+	testData := d.TestData.(*model.File)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(d.AnyTime, testData.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(FileDao).UpdateByID(d.Ctx, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// zero id error
+	err = d.IDao.(FileDao).UpdateByID(d.Ctx, &model.File{})
+	assert.Error(t, err)
+
+}
+
+func Test_fileDao_GetByID(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+	testData := d.TestData.(*model.File)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(rows)
+
+	_, err := d.IDao.(FileDao).GetByID(d.Ctx, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// error test
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(2).
+		WillReturnRows(rows)
+	_, err = d.IDao.(FileDao).GetByID(d.Ctx, 2)
+	assert.Error(t, err)
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(3, 4).
+		WillReturnRows(rows)
+	_, err = d.IDao.(FileDao).GetByID(d.Ctx, 4)
+	assert.Error(t, err)
+}
+
+func Test_fileDao_GetByColumns(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+	testData := d.TestData.(*model.File)
+
+	// column names and corresponding data
+	rows := sqlmock.NewRows([]string{"id"}).
+		AddRow(testData.ID)
+
+	d.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	_, _, err := d.IDao.(FileDao).GetByColumns(d.Ctx, &query.Params{
+		Page:  0,
+		Limit: 10,
+		Sort:  "ignore count", // ignore test count(*)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = d.SQLMock.ExpectationsWereMet()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// err test
+	_, _, err = d.IDao.(FileDao).GetByColumns(d.Ctx, &query.Params{
+		Page:  0,
+		Limit: 10,
+		Columns: []query.Column{
+			{
+				Name:  "id",
+				Exp:   "<",
+				Value: 0,
+			},
+		},
+	})
+	assert.Error(t, err)
+
+	// error test
+	dao := &fileDao{}
+	_, _, err = dao.GetByColumns(context.Background(), &query.Params{Columns: []query.Column{{}}})
+	t.Log(err)
+}
+
+func Test_fileDao_CreateByTx(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+	testData := d.TestData.(*model.File)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WithArgs(d.GetAnyArgs(testData)...).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	_, err := d.IDao.(FileDao).CreateByTx(d.Ctx, d.DB, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_fileDao_DeleteByTx(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+	testData := d.TestData.(*model.File)
+	expectedSQLForDeletion := "DELETE FROM .*"
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec(expectedSQLForDeletion).
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(FileDao).DeleteByTx(d.Ctx, d.DB, testData.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_fileDao_UpdateByTx(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+	testData := d.TestData.(*model.File)
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(d.AnyTime, testData.ID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(FileDao).UpdateByTx(d.Ctx, d.DB, testData)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_fileDao_GetByHash(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+	testData := d.TestData.(*model.File)
+	testData.Hash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+	rows := sqlmock.NewRows([]string{"id", "hash"}).
+		AddRow(testData.ID, testData.Hash)
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.Hash, false, 0, 1).
+		WillReturnRows(rows)
+
+	record, err := d.IDao.(FileDao).GetByHash(d.Ctx, testData.Hash, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, testData.ID, record.ID)
+
+	// not found
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs("not found", true, 2, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = d.IDao.(FileDao).GetByHash(d.Ctx, "not found", true, 2)
+	assert.ErrorIs(t, err, database.ErrRecordNotFound)
+}
+
+func Test_fileDao_SyncRefs(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+	testData := d.TestData.(*model.File)
+
+	rows := sqlmock.NewRows([]string{"id", "storage_key"}).
+		AddRow(testData.ID, "2024-11-10/a.png")
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs("2024-11-10/a.png").
+		WillReturnRows(rows)
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("DELETE FROM .*").
+		WithArgs("platform.avatar", 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	d.SQLMock.ExpectExec("INSERT INTO .*").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(FileDao).SyncRefs(d.Ctx, "platform.avatar", 1, "2024-11-10/a.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_fileDao_CountRefs(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+	testData := d.TestData.(*model.File)
+
+	rows := sqlmock.NewRows([]string{"file_id", "total"}).
+		AddRow(testData.ID, 2)
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(rows)
+
+	counts, err := d.IDao.(FileDao).CountRefs(d.Ctx, []uint64{testData.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(2), counts[testData.ID])
+}
+
+func Test_fileDao_GetFieldPaths(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+
+	d.SQLMock.ExpectQuery("SELECT DISTINCT `avatar` FROM `t_platform`.*").
+		WithArgs("%uploads%").
+		WillReturnRows(sqlmock.NewRows([]string{"avatar"}).AddRow("/uploads/2024-11-10/a.png"))
+	d.SQLMock.ExpectQuery("SELECT DISTINCT `value` FROM `t_config`.*").
+		WithArgs("%uploads%").
+		WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(`{"image":"/uploads/2024-11-10/b.png"}`))
+	d.SQLMock.ExpectQuery("SELECT DISTINCT `icon` FROM `t_menu`.*").
+		WithArgs("%uploads%").
+		WillReturnRows(sqlmock.NewRows([]string{"icon"}))
+
+	paths, err := d.IDao.(FileDao).GetFieldPaths(d.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"/uploads/2024-11-10/a.png", `{"image":"/uploads/2024-11-10/b.png"}`}, paths)
+}
+
+func Test_fileDao_GetFieldPathsOf(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+
+	// matched by the file name, the slashes of json are escaped
+	for _, column := range []string{"avatar", "value", "icon"} {
+		d.SQLMock.ExpectQuery("SELECT DISTINCT `" + column + "` FROM .*").
+			WithArgs("%a.png%").
+			WillReturnRows(sqlmock.NewRows([]string{column}))
+	}
+
+	paths, err := d.IDao.(FileDao).GetFieldPathsOf(d.Ctx, "2024-11-10/a.png")
+	assert.NoError(t, err)
+	assert.Empty(t, paths)
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+}
+
+func Test_fileDao_Touch(t *testing.T) {
+	d := newFileDao()
+	defer d.Close()
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE `t_file` SET `updated_at`=.*").
+		WithArgs(d.AnyTime, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(FileDao).Touch(d.Ctx, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+}
@@ -2,9 +2,16 @@ package dao
 
 import (
 	"admin/internal/database"
+	"admin/internal/pkg/i18n"
+	"admin/internal/pkg/menumanifest"
 	"admin/internal/types"
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
 
 	"github.com/go-dev-frame/sponge/pkg/logger"
 	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
@@ -16,19 +23,57 @@ import (
 	"admin/internal/model"
 )
 
+// menu types, the values of t_menu.type
+const (
+	MenuTypeCatalog = "CATALOG"
+	MenuTypeMenu    = "MENU"
+	MenuTypeButton  = "BUTTON"
+	MenuTypeExtLink = "EXTLINK"
+)
+
+var (
+	// ErrMenuCycle a menu is moved under itself or its descendants
+	ErrMenuCycle = errors.New("menu can not be moved under itself or its descendants")
+	// ErrMenuParentType a menu is moved under a button or an external link
+	ErrMenuParentType = errors.New("menu can only be moved under a catalog or a menu")
+	// ErrMenuDependents a menu with descendants or role assignments is deleted without cascade
+	ErrMenuDependents = errors.New("menu has descendants or is assigned to roles")
+	// ErrMenuPermExists a generated button perm is used by another menu
+	ErrMenuPermExists = errors.New("menu perm already exists")
+)
+
+// MenuButtonActions the standard buttons of a MENU page in the order of their sort values,
+// the perm of a button is <prefix>:<action>
+var MenuButtonActions = []string{"add", "edit", "delete", "query"}
+
+// the names of the standard buttons
+var menuButtonNames = map[string][2]string{
+	"add":    {"新增", "Add"},
+	"edit":   {"编辑", "Edit"},
+	"delete": {"删除", "Delete"},
+	"query":  {"查询", "Query"},
+}
+
 var _ MenuDao = (*menuDao)(nil)
 
 // MenuDao defining the dao interface
 type MenuDao interface {
 	Create(ctx context.Context, table *model.Menu) error
+	CreateWithButtons(ctx context.Context, table *model.Menu, buttons []*model.Menu, roleIDs []uint64) error
 	DeleteByID(ctx context.Context, id uint64) error
 	DeleteByIDs(ctx context.Context, ids []uint64) error
+	DeleteTree(ctx context.Context, ids []uint64, cascade bool) (*types.MenuDependents, error)
 	UpdateByID(ctx context.Context, table *model.Menu) error
 	GetByID(ctx context.Context, id uint64) (*model.Menu, error)
 	GetByColumns(ctx context.Context, params *query.Params) ([]*model.Menu, int64, error)
 	GetByParams(ctx context.Context, params *types.ListMenusRequest) ([]*model.Menu, int64, error)
-	Routes(ctx context.Context, roleIds []uint64) ([]model.MenuItem, error)
+	Routes(ctx context.Context, roleIds []uint64, locale string) ([]model.MenuItem, error)
 	Options(ctx context.Context, request *types.OptionMenusRequest) ([]types.Options, error)
+	Move(ctx context.Context, moves []*types.MoveMenuItem) error
+	Export(ctx context.Context) (*types.MenuManifest, error)
+	Import(ctx context.Context, manifest *types.MenuManifest, dryRun bool) (*types.MenuImportReport, error)
+	Perms(ctx context.Context) ([]string, error)
+	WarmCache(ctx context.Context, limit int) (int, error)
 
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Menu) (uint64, error)
 	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
@@ -60,9 +105,120 @@ func (d *menuDao) deleteCache(ctx context.Context, id uint64) error {
 	return nil
 }
 
+// deleteTreeCache invalidate the menus of all role sets after the menus are changed
+func (d *menuDao) deleteTreeCache(ctx context.Context) {
+	if d.cache == nil {
+		return
+	}
+	if err := d.cache.DelTrees(ctx); err != nil {
+		logger.Warn("cache.DelTrees error", logger.Err(err))
+	}
+}
+
 // Create a record, insert the record and the id value is written back to the table
 func (d *menuDao) Create(ctx context.Context, table *model.Menu) error {
-	return d.db.WithContext(ctx).Create(table).Error
+	err := d.db.WithContext(ctx).Create(table).Error
+	if err != nil {
+		return err
+	}
+
+	d.deleteTreeCache(ctx)
+
+	return nil
+}
+
+// CreateWithButtons create a menu with its buttons and grant them to the roles in one transaction, the ids
+// are written back. ErrMenuPermExists if a perm of the buttons is used by another menu,
+// database.ErrRecordNotFound if a role does not exist
+func (d *menuDao) CreateWithButtons(ctx context.Context, table *model.Menu, buttons []*model.Menu, roleIDs []uint64) error {
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(buttons) > 0 {
+			perms := make([]string, 0, len(buttons))
+			for _, button := range buttons {
+				perms = append(perms, button.Perm)
+			}
+			var count int64
+			err := tx.Model(&model.Menu{}).Where("perm IN ?", perms).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrMenuPermExists
+			}
+		}
+		if len(roleIDs) > 0 {
+			var count int64
+			err := tx.Model(&model.Role{}).Where("id IN ?", roleIDs).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if int(count) != len(roleIDs) {
+				return database.ErrRecordNotFound
+			}
+		}
+
+		if err := tx.Create(table).Error; err != nil {
+			return err
+		}
+		menuIDs := []uint64{table.ID}
+		for _, button := range buttons {
+			button.ParentID = int(table.ID)
+			if err := tx.Create(button).Error; err != nil {
+				return err
+			}
+			menuIDs = append(menuIDs, button.ID)
+		}
+
+		var links []*model.RoleMenu
+		for _, roleID := range roleIDs {
+			for _, menuID := range menuIDs {
+				links = append(links, &model.RoleMenu{RoleID: roleID, MenuID: menuID})
+			}
+		}
+		if len(links) > 0 {
+			return tx.Create(links).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	d.deleteTreeCache(ctx)
+
+	return nil
+}
+
+// MenuButtons the standard buttons of a menu, the perms are <prefix>:<action>, all the actions if actions is
+// empty. the names are derived from the name of the menu, e.g. 配置新增 for 配置管理
+func MenuButtons(menu *model.Menu, prefix string, actions []string) []*model.Menu {
+	prefix = strings.TrimSuffix(strings.TrimSuffix(prefix, "*"), ":")
+	wanted := make(map[string]bool, len(actions))
+	for _, action := range actions {
+		wanted[action] = true
+	}
+	name := strings.TrimSuffix(menu.Name, "管理")
+	title := menu.Titles[i18n.En]
+
+	var buttons []*model.Menu
+	for _, action := range MenuButtonActions { // the standard order
+		if len(actions) > 0 && !wanted[action] {
+			continue
+		}
+		names := menuButtonNames[action]
+		visible := 1
+		button := &model.Menu{
+			Name:      name + names[0],
+			Type:      MenuTypeButton,
+			Perm:      prefix + ":" + action,
+			Sort:      len(buttons) + 1,
+			Visible:   &visible,
+			KeepAlive: 1,
+			Titles:    types.LocalText{i18n.En: strings.TrimSpace(names[1] + " " + title)},
+		}
+		buttons = append(buttons, button)
+	}
+	return buttons
 }
 
 // DeleteByID delete a record by id
@@ -74,6 +230,7 @@ func (d *menuDao) DeleteByID(ctx context.Context, id uint64) error {
 
 	// delete cache
 	_ = d.deleteCache(ctx, id)
+	d.deleteTreeCache(ctx)
 
 	return nil
 }
@@ -89,16 +246,132 @@ func (d *menuDao) DeleteByIDs(ctx context.Context, ids []uint64) error {
 	for _, id := range ids {
 		_ = d.deleteCache(ctx, id)
 	}
+	d.deleteTreeCache(ctx)
 
 	return nil
 }
 
+// DeleteTree delete the menus in one transaction. if cascade is false and the menus have descendants or
+// are assigned to roles, nothing is deleted and ErrMenuDependents is returned with the dependents,
+// otherwise the descendants and the role-menu links of all of them are deleted too
+func (d *menuDao) DeleteTree(ctx context.Context, ids []uint64, cascade bool) (*types.MenuDependents, error) {
+	var deleted []uint64
+	dependents := &types.MenuDependents{}
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		menus := []*model.Menu{}
+		err := tx.Select("id", "parent_id", "name", "type").Order("sort asc, id asc").Find(&menus).Error
+		if err != nil {
+			return err
+		}
+		dependents.Descendants = descendantMenus(menus, ids)
+		deleted = append(deleted, ids...)
+		for _, menu := range dependents.Descendants {
+			deleted = append(deleted, menu.ID)
+		}
+
+		links := []*roleMenuLink{}
+		err = tx.Model(&model.RoleMenu{}).
+			Select("t_role_menu.id, t_role_menu.role_id, t_role_menu.menu_id, role.name AS role_name").
+			Joins("LEFT JOIN t_role AS role ON role.id = t_role_menu.role_id").
+			Where("t_role_menu.menu_id IN ?", deleted).
+			Order("t_role_menu.role_id asc, t_role_menu.menu_id asc").
+			Scan(&links).Error
+		if err != nil {
+			return err
+		}
+		dependents.Roles = groupRoleLinks(links)
+
+		if !cascade && !dependents.Empty() {
+			return ErrMenuDependents
+		}
+		if len(links) > 0 {
+			linkIDs := make([]uint64, 0, len(links))
+			for _, link := range links {
+				linkIDs = append(linkIDs, link.ID)
+			}
+			if err = tx.Where("id IN ?", linkIDs).Unscoped().Delete(&model.RoleMenu{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("id IN ?", deleted).Delete(&model.Menu{}).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrMenuDependents) {
+			return dependents, err
+		}
+		return nil, err
+	}
+
+	// delete cache, the menus of the roles are cached in the trees
+	for _, id := range deleted {
+		_ = d.deleteCache(ctx, id)
+	}
+	d.deleteTreeCache(ctx)
+
+	return dependents, nil
+}
+
+// roleMenuLink a role-menu link with the role name
+type roleMenuLink struct {
+	ID       uint64
+	RoleID   uint64
+	MenuID   uint64
+	RoleName string
+}
+
+// descendantMenus the descendants of the menus ordered from the top, the menus themselves are not included
+func descendantMenus(menus []*model.Menu, ids []uint64) []*types.MenuDependent {
+	children := groupByParent(menus)
+	seen := make(map[uint64]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	descendants := []*types.MenuDependent{}
+	queue := append([]uint64{}, ids...)
+	for len(queue) > 0 {
+		pid := queue[0]
+		queue = queue[1:]
+		for _, menu := range children[pid] {
+			if seen[menu.ID] { // also stops a cycle in the stored tree
+				continue
+			}
+			seen[menu.ID] = true
+			descendants = append(descendants, &types.MenuDependent{
+				ID:       menu.ID,
+				ParentID: uint64(menu.ParentID),
+				Name:     menu.Name,
+				Type:     menu.Type,
+			})
+			queue = append(queue, menu.ID)
+		}
+	}
+	return descendants
+}
+
+// groupRoleLinks the menus of the links by role, the links are ordered by role
+func groupRoleLinks(links []*roleMenuLink) []*types.MenuRoleDependent {
+	roles := []*types.MenuRoleDependent{}
+	for _, link := range links {
+		if n := len(roles); n > 0 && roles[n-1].RoleID == link.RoleID {
+			roles[n-1].MenuIDs = append(roles[n-1].MenuIDs, link.MenuID)
+			continue
+		}
+		roles = append(roles, &types.MenuRoleDependent{
+			RoleID:   link.RoleID,
+			RoleName: link.RoleName,
+			MenuIDs:  []uint64{link.MenuID},
+		})
+	}
+	return roles
+}
+
 // UpdateByID update a record by id
 func (d *menuDao) UpdateByID(ctx context.Context, table *model.Menu) error {
 	err := d.updateDataByID(ctx, d.db, table)
 
 	// delete cache
 	_ = d.deleteCache(ctx, table.ID)
+	d.deleteTreeCache(ctx)
 
 	return err
 }
@@ -149,6 +422,9 @@ func (d *menuDao) updateDataByID(ctx context.Context, db *gorm.DB, table *model.
 	if table.Params != nil {
 		update["params"] = table.Params
 	}
+	if table.Titles != nil {
+		update["titles"] = table.Titles
+	}
 
 	return db.WithContext(ctx).Model(table).Updates(update).Error
 }
@@ -307,9 +583,31 @@ func (d *menuDao) GetByParams(ctx context.Context, request *types.ListMenusReque
 	return records, total, err
 }
 
+// WarmCache load the latest records into the cache, return the number of loaded records
+func (d *menuDao) WarmCache(ctx context.Context, limit int) (int, error) {
+	if d.cache == nil {
+		return 0, nil
+	}
+	records := []*model.Menu{}
+	err := d.db.WithContext(ctx).Order("id DESC").Limit(limit).Find(&records).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	if err = d.cache.MultiSet(ctx, records, cache.MenuExpireTime); err != nil {
+		return 0, err
+	}
+	return len(records), nil
+}
+
 // CreateByTx create a record in the database using the provided transaction
 func (d *menuDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Menu) (uint64, error) {
 	err := tx.WithContext(ctx).Create(table).Error
+	if err == nil {
+		d.deleteTreeCache(ctx)
+	}
 	return table.ID, err
 }
 
@@ -322,6 +620,7 @@ func (d *menuDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
 
 	// delete cache
 	_ = d.deleteCache(ctx, id)
+	d.deleteTreeCache(ctx)
 
 	return nil
 }
@@ -332,117 +631,579 @@ func (d *menuDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.Menu
 
 	// delete cache
 	_ = d.deleteCache(ctx, table.ID)
+	d.deleteTreeCache(ctx)
 
 	return err
 }
 
-func (d *menuDao) getListByPid(ctx context.Context, pid uint64, roleIds []uint64, onlyParent bool) ([]*model.Menu, error) {
-	db := d.db.WithContext(ctx).
-		Model(&model.Menu{}).
-		Where("t_menu.parent_id = ?", pid).
-		Order("t_menu.sort asc")
-	if roleIds != nil && len(roleIds) > 0 {
-		db = db.Joins("join t_role_menu on t_role_menu.menu_id = t_menu.id").
-			Where("t_role_menu.role_id in ?", roleIds).
-			Group("t_menu.id")
+// getVisibleMenus all the menus visible to the roles in one query ordered by sort, nil roleIds for all
+// the menus. the result is cached per role set
+func (d *menuDao) getVisibleMenus(ctx context.Context, roleIds []uint64) ([]*model.Menu, error) {
+	if d.cache == nil {
+		return d.queryVisibleMenus(ctx, roleIds)
+	}
+
+	version, err := d.cache.TreeVersion(ctx)
+	if err != nil {
+		logger.Warn("cache.TreeVersion error", logger.Err(err))
+		return d.queryVisibleMenus(ctx, roleIds)
+	}
+	menus, err := d.cache.GetTree(ctx, version, roleIds)
+	if err == nil {
+		return menus, nil
+	}
+	if !errors.Is(err, database.ErrCacheNotFound) {
+		logger.Warn("cache.GetTree error", logger.Err(err), logger.Any("roleIds", roleIds))
+	}
+
+	// for the same role set, prevent high concurrent simultaneous access to database
+	key := "tree:" + version + ":" + fmt.Sprint(roleIds)
+	val, err, _ := d.sfg.Do(key, func() (interface{}, error) {
+		records, err := d.queryVisibleMenus(ctx, roleIds)
+		if err != nil {
+			return nil, err
+		}
+		if err = d.cache.SetTree(ctx, version, roleIds, records, cache.MenuTreeExpireTime); err != nil {
+			logger.Warn("cache.SetTree error", logger.Err(err), logger.Any("roleIds", roleIds))
+		}
+		return records, nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	if onlyParent {
-		db = db.Where("t_menu.type in ?", []string{"CATALOG", "MENU"})
+	return val.([]*model.Menu), nil
+}
+
+func (d *menuDao) queryVisibleMenus(ctx context.Context, roleIds []uint64) ([]*model.Menu, error) {
+	db := d.db.WithContext(ctx).Model(&model.Menu{}).Order("sort asc, id asc")
+	if len(roleIds) > 0 {
+		db = db.Where("id IN (?)", d.db.Model(&model.RoleMenu{}).Select("menu_id").Where("role_id IN ?", roleIds))
 	}
-	var records []*model.Menu
+	records := []*model.Menu{}
 	err := db.Find(&records).Error
 	return records, err
 }
 
-func (d *menuDao) getChildren(ctx context.Context, pid uint64, roleIds []uint64) ([]model.Children, error) {
-	items := make([]model.Children, 0)
-	menus, err := d.getListByPid(ctx, pid, roleIds, false)
-	if err != nil {
-		return make([]model.Children, 0), err
-	}
+// groupByParent the menus by parent id, the order of the menus is kept
+func groupByParent(menus []*model.Menu) map[uint64][]*model.Menu {
+	children := make(map[uint64][]*model.Menu)
 	for _, menu := range menus {
-		params := menu.Params
-		meta := model.ChildrenMeta{
-			Title:      menu.Name,
-			Icon:       menu.Icon,
-			Hidden:     *menu.Visible != 1,
-			KeepAlive:  menu.KeepAlive == 1,
-			AlwaysShow: menu.AlwaysShow == 1,
-			Params:     &params,
-		}
-		item := model.Children{
-			Path:      menu.Path,
-			Name:      menu.Path,
-			Component: menu.Component,
-			Meta:      meta,
-		}
-		items = append(items, item)
+		pid := uint64(menu.ParentID)
+		children[pid] = append(children[pid], menu)
 	}
-	return items, nil
+	return children
 }
 
-func (d *menuDao) Routes(ctx context.Context, roleIds []uint64) ([]model.MenuItem, error) {
-	tops, err := d.getListByPid(ctx, 0, roleIds, false)
+// Routes the menus of the roles as the routes of the web, the titles are in the locale.
+// the tree has any depth, the buttons are not routes, a menu whose parent is not visible is dropped
+func (d *menuDao) Routes(ctx context.Context, roleIds []uint64, locale string) ([]model.MenuItem, error) {
+	menus, err := d.getVisibleMenus(ctx, roleIds)
 	if err != nil {
 		return nil, err
 	}
-	var items []model.MenuItem
-	for _, top := range tops {
+	children := groupByParent(menus)
+
+	items := []model.MenuItem{}
+	for _, top := range children[0] {
+		if top.Type == MenuTypeButton {
+			continue
+		}
 		params := top.Params
-		meta := model.MenuMeta{
-			Title:      top.Name,
-			Icon:       top.Icon,
-			Hidden:     *top.Visible != 1,
-			AlwaysShow: top.AlwaysShow == 1,
-			Params:     &params,
-		}
-		children, _ := d.getChildren(ctx, top.ID, roleIds)
-		item := model.MenuItem{
+		items = append(items, model.MenuItem{
 			Path:      top.Path,
 			Name:      top.Path,
 			Component: top.Component,
 			Redirect:  top.Redirect,
-			Meta:      meta,
-			Children:  children,
+			Meta: model.MenuMeta{
+				Title:      i18n.Pick(top.Titles, locale, top.Name),
+				Icon:       top.Icon,
+				Hidden:     top.Visible == nil || *top.Visible != 1,
+				AlwaysShow: top.AlwaysShow == 1,
+				Params:     &params,
+			},
+			Children: childRoutes(children, top.ID, locale),
+		})
+	}
+	return items, nil
+}
+
+func childRoutes(children map[uint64][]*model.Menu, pid uint64, locale string) []model.Children {
+	items := make([]model.Children, 0)
+	for _, menu := range children[pid] {
+		if menu.Type == MenuTypeButton {
+			continue
+		}
+		params := menu.Params
+		item := model.Children{
+			Path:      menu.Path,
+			Name:      menu.Path,
+			Component: menu.Component,
+			Redirect:  menu.Redirect,
+			Meta: model.ChildrenMeta{
+				Title:      i18n.Pick(menu.Titles, locale, menu.Name),
+				Icon:       menu.Icon,
+				Hidden:     menu.Visible == nil || *menu.Visible != 1,
+				KeepAlive:  menu.KeepAlive == 1,
+				AlwaysShow: menu.AlwaysShow == 1,
+				Params:     &params,
+			},
+		}
+		if grandchildren := childRoutes(children, menu.ID, locale); len(grandchildren) > 0 {
+			item.Children = grandchildren
 		}
 		items = append(items, item)
 	}
-	return items, nil
+	return items
 }
 
-func (d *menuDao) childrenOption(ctx context.Context, pid uint64, request *types.OptionMenusRequest) ([]types.Options, error) {
-	items := make([]types.Options, 0)
-	menus, err := d.getListByPid(ctx, pid, nil, request.OnlyParent)
+// Options the tree of all the menus, only the catalogs and the menus if request.OnlyParent
+func (d *menuDao) Options(ctx context.Context, request *types.OptionMenusRequest) ([]types.Options, error) {
+	menus, err := d.getVisibleMenus(ctx, nil)
 	if err != nil {
-		return items, err
+		return make([]types.Options, 0), err
 	}
-	for _, menu := range menus {
-		children, _ := d.childrenOption(ctx, menu.ID, request)
-		item := types.Options{
+	return menuOptions(groupByParent(menus), 0, request.OnlyParent), nil
+}
+
+func menuOptions(children map[uint64][]*model.Menu, pid uint64, onlyParent bool) []types.Options {
+	items := make([]types.Options, 0)
+	for _, menu := range children[pid] {
+		if onlyParent && menu.Type != MenuTypeCatalog && menu.Type != MenuTypeMenu {
+			continue
+		}
+		items = append(items, types.Options{
 			Label:    menu.Name,
 			Value:    menu.ID,
-			Children: children,
+			Children: menuOptions(children, menu.ID, onlyParent),
+		})
+	}
+	return items
+}
+
+// Move move the menus with their subtrees in order in one transaction, the sort values of the siblings
+// under the old and the new parents are renumbered from 1. database.ErrRecordNotFound if a menu or
+// a parent does not exist, ErrMenuCycle if a menu is moved under itself or its descendants,
+// ErrMenuParentType if a parent is neither a catalog nor a menu
+func (d *menuDao) Move(ctx context.Context, moves []*types.MoveMenuItem) error {
+	var changed []*model.Menu
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		menus := []*model.Menu{}
+		err := tx.Select("id", "parent_id", "type", "sort").Order("sort asc, id asc").Find(&menus).Error
+		if err != nil {
+			return err
 		}
-		items = append(items, item)
+		changed, err = moveMenus(menus, moves)
+		if err != nil {
+			return err
+		}
+		for _, menu := range changed {
+			err = tx.Model(&model.Menu{}).Where("id = ?", menu.ID).
+				Updates(map[string]interface{}{"parent_id": menu.ParentID, "sort": menu.Sort}).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-	return items, nil
+
+	// delete cache
+	for _, menu := range changed {
+		_ = d.deleteCache(ctx, menu.ID)
+	}
+	d.deleteTreeCache(ctx)
+
+	return nil
 }
 
-func (d *menuDao) Options(ctx context.Context, request *types.OptionMenusRequest) ([]types.Options, error) {
-	tops, err := d.getListByPid(ctx, 0, nil, request.OnlyParent)
+// moveMenus apply the moves to the menus ordered by sort, return the menus whose parent or sort is changed
+func moveMenus(menus []*model.Menu, moves []*types.MoveMenuItem) ([]*model.Menu, error) {
+	parents := make(map[uint64]uint64, len(menus))
+	menuTypes := make(map[uint64]string, len(menus))
+	children := make(map[uint64][]uint64)
+	for _, menu := range menus {
+		pid := uint64(menu.ParentID)
+		parents[menu.ID] = pid
+		menuTypes[menu.ID] = menu.Type
+		children[pid] = append(children[pid], menu.ID)
+	}
+
+	renumber := map[uint64]bool{}
+	for _, move := range moves {
+		oldParent, ok := parents[move.ID]
+		if !ok {
+			return nil, database.ErrRecordNotFound
+		}
+		if move.ParentID != 0 {
+			if _, ok = parents[move.ParentID]; !ok {
+				return nil, database.ErrRecordNotFound
+			}
+			// only catalogs and menus can be rendered as parents by the routes and the frontend
+			if t := menuTypes[move.ParentID]; t != MenuTypeCatalog && t != MenuTypeMenu {
+				return nil, ErrMenuParentType
+			}
+		}
+		// walk up from the new parent, the steps are bounded in case the stored tree has a cycle
+		for p, steps := move.ParentID, 0; p != 0 && steps <= len(parents); p, steps = parents[p], steps+1 {
+			if p == move.ID {
+				return nil, ErrMenuCycle
+			}
+		}
+
+		siblings := children[oldParent]
+		for i, id := range siblings {
+			if id == move.ID {
+				children[oldParent] = append(siblings[:i:i], siblings[i+1:]...)
+				break
+			}
+		}
+		siblings = children[move.ParentID]
+		index := move.Index
+		if index > len(siblings) {
+			index = len(siblings)
+		}
+		siblings = append(siblings[:index:index], append([]uint64{move.ID}, siblings[index:]...)...)
+		children[move.ParentID] = siblings
+		parents[move.ID] = move.ParentID
+		renumber[oldParent], renumber[move.ParentID] = true, true
+	}
+
+	sorts := make(map[uint64]int)
+	for parent := range renumber {
+		for i, id := range children[parent] {
+			sorts[id] = i + 1
+		}
+	}
+	var changed []*model.Menu
+	for _, menu := range menus { // keep the order of the menus
+		sort, ok := sorts[menu.ID]
+		if !ok || (uint64(menu.ParentID) == parents[menu.ID] && menu.Sort == sort) {
+			continue
+		}
+		menu.ParentID = int(parents[menu.ID])
+		menu.Sort = sort
+		changed = append(changed, menu)
+	}
+	return changed, nil
+}
+
+// Export the menu tree as a manifest ordered by sort, a menu whose parent does not exist is dropped.
+// the menus which can not be matched when imported again, without a key or with the key of a menu before
+// them, are not exported together with their children, they are listed in Skipped instead
+func (d *menuDao) Export(ctx context.Context) (*types.MenuManifest, error) {
+	menus := []*model.Menu{}
+	err := d.db.WithContext(ctx).Order("sort asc, id asc").Find(&menus).Error
 	if err != nil {
-		return make([]types.Options, 0), err
+		return nil, err
 	}
+	ex := &menuExport{children: groupByParent(menus), keys: map[string]bool{}}
+	return &types.MenuManifest{Menus: ex.items(0, ""), Skipped: ex.skipped}, nil
+}
+
+// Perms the distinct perms of all the menus, the menus without a perm are skipped
+func (d *menuDao) Perms(ctx context.Context) ([]string, error) {
+	perms := []string{}
+	err := d.db.WithContext(ctx).Model(&model.Menu{}).Where("perm <> ''").
+		Distinct().Order("perm asc").Pluck("perm", &perms).Error
+	return perms, err
+}
+
+// menuExport the menus of a manifest, the keys are unique in a manifest
+type menuExport struct {
+	children map[uint64][]*model.Menu
+	keys     map[string]bool
+	skipped  []string
+}
 
-	var items []types.Options
-	for _, top := range tops {
-		children, _ := d.childrenOption(ctx, top.ID, request)
-		item := types.Options{
-			Label:    top.Name,
-			Value:    top.ID,
-			Children: children,
+func (ex *menuExport) items(pid uint64, parentKey string) []*types.MenuManifestItem {
+	items := make([]*types.MenuManifestItem, 0, len(ex.children[pid]))
+	for _, menu := range ex.children[pid] {
+		key := menumanifest.Key(parentKey, menu.Type, menu.Path, menu.Perm)
+		if key == "" {
+			ex.skipped = append(ex.skipped, fmt.Sprintf("%s (id %d): neither a path nor a perm", menu.Name, menu.ID))
+			continue
 		}
-		items = append(items, item)
+		if ex.keys[key] {
+			ex.skipped = append(ex.skipped, fmt.Sprintf("%s (id %d): duplicate key %s", menu.Name, menu.ID, key))
+			continue
+		}
+		ex.keys[key] = true
+
+		visible, keepAlive := 0, menu.KeepAlive
+		if menu.Visible != nil {
+			visible = *menu.Visible
+		}
+		items = append(items, &types.MenuManifestItem{
+			Name:       menu.Name,
+			Type:       menu.Type,
+			Path:       menu.Path,
+			Component:  menu.Component,
+			Perm:       menu.Perm,
+			Sort:       menu.Sort,
+			Visible:    &visible,
+			Icon:       menu.Icon,
+			Redirect:   menu.Redirect,
+			AlwaysShow: menu.AlwaysShow,
+			KeepAlive:  &keepAlive,
+			Params:     menu.Params,
+			Titles:     menu.Titles,
+			Children:   ex.items(menu.ID, key),
+		})
 	}
-	return items, nil
+	return items
+}
+
+// Import upsert the menus of the manifest in one transaction, the catalogs, menus and external links are
+// matched by path and the buttons by perm under their parent. a matched menu keeps its id, so the roles keep their menus,
+// the menus not in the manifest are kept. nothing is written if dryRun is true, only the report is returned
+func (d *menuDao) Import(ctx context.Context, manifest *types.MenuManifest, dryRun bool) (*types.MenuImportReport, error) {
+	report := &types.MenuImportReport{
+		DryRun:  dryRun,
+		Created: []*types.MenuImportChange{},
+		Updated: []*types.MenuImportChange{},
+	}
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		menus := []*model.Menu{}
+		err := tx.Order("id asc").Find(&menus).Error
+		if err != nil {
+			return err
+		}
+		im := &menuImport{existing: make(map[string]*model.Menu, len(menus)), report: report}
+		keys := menuKeys(menus)
+		for _, menu := range menus {
+			key := keys[menu.ID]
+			if _, ok := im.existing[key]; key != "" && !ok { // the oldest one of the duplicates
+				im.existing[key] = menu
+			}
+		}
+		if !dryRun {
+			im.create = func(menu *model.Menu) error {
+				if err := tx.Create(menu).Error; err != nil {
+					return err
+				}
+				if menu.KeepAlive == 0 { // the zero value is replaced by the column default when inserted
+					return tx.Model(&model.Menu{}).Where("id = ?", menu.ID).Update("keep_alive", 0).Error
+				}
+				return nil
+			}
+			im.update = func(menu *model.Menu) error {
+				return tx.Model(&model.Menu{}).Where("id = ?", menu.ID).Updates(manifestColumns(menu)).Error
+			}
+		}
+		return im.walk(manifest.Menus, 0, "")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun && len(report.Created)+len(report.Updated) > 0 {
+		// delete cache
+		for _, change := range report.Updated {
+			_ = d.deleteCache(ctx, change.ID)
+		}
+		d.deleteTreeCache(ctx)
+	}
+
+	return report, nil
+}
+
+// menuKeys the manifest keys of the menus by id, a menu matched by its perm has no key if its parent does not
+// exist or has no key
+func menuKeys(menus []*model.Menu) map[uint64]string {
+	byID := make(map[uint64]*model.Menu, len(menus))
+	for _, menu := range menus {
+		byID[menu.ID] = menu
+	}
+	keys := make(map[uint64]string, len(menus))
+	var keyOf func(menu *model.Menu) string
+	keyOf = func(menu *model.Menu) string {
+		if key, ok := keys[menu.ID]; ok {
+			return key
+		}
+		keys[menu.ID] = "" // a cycle of parents has no key
+		parentKey := ""
+		if menu.ParentID != 0 {
+			if parent := byID[uint64(menu.ParentID)]; parent != nil {
+				parentKey = keyOf(parent)
+			}
+		}
+		key := menumanifest.Key(parentKey, menu.Type, menu.Path, menu.Perm)
+		if menu.ParentID != 0 && parentKey == "" && !strings.HasPrefix(key, "path:") {
+			key = ""
+		}
+		keys[menu.ID] = key
+		return key
+	}
+	for _, menu := range menus {
+		keyOf(menu)
+	}
+	return keys
+}
+
+// menuImport the menus of a manifest compared with the existing menus, create and update are nil in a dry run
+type menuImport struct {
+	existing map[string]*model.Menu
+	report   *types.MenuImportReport
+	create   func(menu *model.Menu) error
+	update   func(menu *model.Menu) error
+
+	placeholders map[uint64]bool // ids of the menus which would be created by a dry run
+}
+
+// placeholder the id of a menu which would be created in a dry run, it does not clash with the existing ids
+func (im *menuImport) placeholder() uint64 {
+	if im.placeholders == nil {
+		im.placeholders = map[uint64]bool{}
+	}
+	id := uint64(math.MaxInt32) - uint64(len(im.placeholders))
+	im.placeholders[id] = true
+	return id
+}
+
+// walk the items under the parent. in a dry run a new menu gets a placeholder id, the existing menus moved
+// under it are not reported as a parentId change because the parent is created by the same import
+func (im *menuImport) walk(items []*types.MenuManifestItem, parentID uint64, parentKey string) error {
+	for i, item := range items {
+		menu := manifestMenu(item, parentID, i+1)
+		key := menumanifest.Key(parentKey, item.Type, item.Path, item.Perm)
+		change := &types.MenuImportChange{Key: key, Name: item.Name}
+
+		if old, ok := im.existing[key]; ok {
+			menu.ID = old.ID
+			change.ID = old.ID
+			change.Fields = diffMenu(old, menu)
+			if im.placeholders[parentID] {
+				change.Fields = slices.DeleteFunc(change.Fields, func(field string) bool { return field == "parentId" })
+			}
+			if len(change.Fields) == 0 {
+				im.report.Unchanged++
+			} else {
+				im.report.Updated = append(im.report.Updated, change)
+				if im.update != nil {
+					if err := im.update(menu); err != nil {
+						return err
+					}
+				}
+			}
+		} else {
+			im.report.Created = append(im.report.Created, change)
+			if im.create != nil {
+				if err := im.create(menu); err != nil {
+					return err
+				}
+				change.ID = menu.ID
+			} else {
+				menu.ID = im.placeholder()
+			}
+		}
+
+		if err := im.walk(item.Children, menu.ID, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// manifestMenu the menu of an item, the sort is the position under the parent if the item has none
+func manifestMenu(item *types.MenuManifestItem, parentID uint64, position int) *model.Menu {
+	visible, keepAlive, sort := 1, 1, item.Sort
+	if item.Visible != nil {
+		visible = *item.Visible
+	}
+	if item.KeepAlive != nil {
+		keepAlive = *item.KeepAlive
+	}
+	if sort == 0 {
+		sort = position
+	}
+	return &model.Menu{
+		ParentID:   int(parentID),
+		Name:       item.Name,
+		Type:       item.Type,
+		Path:       item.Path,
+		Component:  item.Component,
+		Perm:       item.Perm,
+		Sort:       sort,
+		Visible:    &visible,
+		Icon:       item.Icon,
+		Redirect:   item.Redirect,
+		AlwaysShow: item.AlwaysShow,
+		KeepAlive:  keepAlive,
+		Params:     item.Params,
+		Titles:     item.Titles,
+	}
+}
+
+// manifestColumns all the columns set by the manifest, the zero values included
+func manifestColumns(menu *model.Menu) map[string]interface{} {
+	update := map[string]interface{}{
+		"parent_id":   menu.ParentID,
+		"name":        menu.Name,
+		"type":        menu.Type,
+		"path":        menu.Path,
+		"component":   menu.Component,
+		"perm":        menu.Perm,
+		"sort":        menu.Sort,
+		"visible":     menu.Visible,
+		"icon":        menu.Icon,
+		"redirect":    menu.Redirect,
+		"always_show": menu.AlwaysShow,
+		"keep_alive":  menu.KeepAlive,
+		"params":      nil,
+		"titles":      nil,
+	}
+	if len(menu.Params) > 0 {
+		update["params"] = menu.Params
+	}
+	if len(menu.Titles) > 0 {
+		update["titles"] = menu.Titles
+	}
+	return update
+}
+
+// diffMenu the json names of the fields changed from old to menu
+func diffMenu(old *model.Menu, menu *model.Menu) []string {
+	var fields []string
+	add := func(changed bool, field string) {
+		if changed {
+			fields = append(fields, field)
+		}
+	}
+	intValue := func(v *int) int {
+		if v == nil {
+			return 0
+		}
+		return *v
+	}
+	add(old.ParentID != menu.ParentID, "parentId")
+	add(old.Name != menu.Name, "name")
+	add(old.Type != menu.Type, "type")
+	add(old.Path != menu.Path, "path")
+	add(old.Component != menu.Component, "component")
+	add(old.Perm != menu.Perm, "perm")
+	add(old.Sort != menu.Sort, "sort")
+	add(intValue(old.Visible) != intValue(menu.Visible), "visible")
+	add(old.Icon != menu.Icon, "icon")
+	add(old.Redirect != menu.Redirect, "redirect")
+	add(old.AlwaysShow != menu.AlwaysShow, "alwaysShow")
+	add(old.KeepAlive != menu.KeepAlive, "keepAlive")
+	add(!sameJSON(old.Params, menu.Params), "params")
+	add(!sameJSON(old.Titles, menu.Titles), "titles")
+	return fields
+}
+
+// sameJSON compare two json values, null and the empty array or object are the same
+func sameJSON(a interface{}, b interface{}) bool {
+	normalize := func(v interface{}) string {
+		data, _ := json.Marshal(v)
+		switch s := string(data); s {
+		case "null", "[]", "{}":
+			return ""
+		default:
+			return s
+		}
+	}
+	return normalize(a) == normalize(b)
 }
@@ -7,12 +7,15 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
 	"github.com/go-dev-frame/sponge/pkg/sgorm/query"
 	"github.com/go-dev-frame/sponge/pkg/utils"
 	"github.com/stretchr/testify/assert"
 
 	"admin/internal/cache"
 	"admin/internal/model"
+	"admin/internal/pkg/menumanifest"
+	"admin/internal/types"
 )
 
 func newMenuDao() *gotest.Dao {
@@ -233,3 +236,370 @@ func Test_menuDao_UpdateByTx(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func Test_menuDao_Routes(t *testing.T) {
+	d := newMenuDao()
+	defer d.Close()
+
+	columns := []string{"id", "parent_id", "name", "type", "path", "visible", "titles", "sort"}
+	menuRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows(columns).
+			AddRow(1, 0, "系统管理", MenuTypeCatalog, "/system", 1, `{"en": "System"}`, 1).
+			AddRow(2, 1, "日志", MenuTypeCatalog, "log", 1, nil, 1).
+			AddRow(3, 2, "操作日志", MenuTypeMenu, "audit", 1, nil, 1).
+			AddRow(4, 3, "导出", MenuTypeButton, "", 1, nil, 1).
+			AddRow(5, 9, "父级不可见", MenuTypeMenu, "orphan", 1, nil, 1)
+	}
+	d.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(menuRows())
+
+	items, err := d.IDao.(MenuDao).Routes(d.Ctx, []uint64{2, 1}, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, items, 1) && assert.Len(t, items[0].Children, 1) {
+		assert.Equal(t, "System", items[0].Meta.Title)
+		log := items[0].Children[0]
+		assert.Equal(t, "日志", log.Meta.Title) // no translation
+		if assert.Len(t, log.Children, 1) {
+			assert.Equal(t, "audit", log.Children[0].Path)
+			assert.Empty(t, log.Children[0].Children) // buttons are not routes
+		}
+	}
+
+	// cached per role set, the order of the role ids does not matter
+	items, err = d.IDao.(MenuDao).Routes(d.Ctx, []uint64{1, 2}, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "系统管理", items[0].Meta.Title)
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+
+	options, err := d.IDao.(MenuDao).Options(d.Ctx, &types.OptionMenusRequest{OnlyParent: true})
+	assert.Error(t, err) // another role set, not cached
+	assert.Empty(t, options)
+
+	// invalidated after a menu is changed
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectCommit()
+	assert.NoError(t, d.IDao.(MenuDao).UpdateByID(d.Ctx, &model.Menu{Sort: 2, Model: sgorm.Model{ID: 3}}))
+	d.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(menuRows())
+	_, err = d.IDao.(MenuDao).Routes(d.Ctx, []uint64{1, 2}, "")
+	assert.NoError(t, err)
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+}
+
+func Test_menuOptions(t *testing.T) {
+	menus := []*model.Menu{
+		{Model: sgorm.Model{ID: 1}, ParentID: 0, Name: "系统管理", Type: MenuTypeCatalog},
+		{Model: sgorm.Model{ID: 2}, ParentID: 1, Name: "日志", Type: MenuTypeCatalog},
+		{Model: sgorm.Model{ID: 3}, ParentID: 2, Name: "操作日志", Type: MenuTypeMenu},
+		{Model: sgorm.Model{ID: 4}, ParentID: 3, Name: "导出", Type: MenuTypeButton},
+	}
+	options := menuOptions(groupByParent(menus), 0, false)
+	assert.Equal(t, "导出", options[0].Children[0].Children[0].Children[0].Label)
+
+	options = menuOptions(groupByParent(menus), 0, true)
+	assert.Empty(t, options[0].Children[0].Children[0].Children)
+}
+
+func Test_moveMenus(t *testing.T) {
+	newMenus := func() []*model.Menu {
+		return []*model.Menu{
+			{Model: sgorm.Model{ID: 1}, ParentID: 0, Type: MenuTypeCatalog, Sort: 1},
+			{Model: sgorm.Model{ID: 2}, ParentID: 1, Type: MenuTypeMenu, Sort: 1},
+			{Model: sgorm.Model{ID: 3}, ParentID: 1, Type: MenuTypeMenu, Sort: 2},
+			{Model: sgorm.Model{ID: 4}, ParentID: 0, Type: MenuTypeCatalog, Sort: 2},
+			{Model: sgorm.Model{ID: 5}, ParentID: 2, Type: MenuTypeButton, Sort: 1},
+			{Model: sgorm.Model{ID: 6}, ParentID: 4, Type: MenuTypeExtLink, Sort: 1},
+		}
+	}
+
+	// reorder the siblings
+	changed, err := moveMenus(newMenus(), []*types.MoveMenuItem{{ID: 3, ParentID: 1, Index: 0}})
+	assert.NoError(t, err)
+	assert.Len(t, changed, 2)
+	assert.Equal(t, uint64(2), changed[0].ID)
+	assert.Equal(t, 2, changed[0].Sort)
+	assert.Equal(t, 1, changed[1].Sort)
+
+	// move to the root, the index is clamped
+	changed, err = moveMenus(newMenus(), []*types.MoveMenuItem{{ID: 2, ParentID: 0, Index: 100}})
+	assert.NoError(t, err)
+	assert.Len(t, changed, 2)
+	assert.Equal(t, 0, changed[0].ParentID)
+	assert.Equal(t, 3, changed[0].Sort)
+	assert.Equal(t, uint64(3), changed[1].ID)
+	assert.Equal(t, 1, changed[1].Sort)
+
+	// the same position
+	changed, err = moveMenus(newMenus(), []*types.MoveMenuItem{{ID: 4, ParentID: 0, Index: 1}})
+	assert.NoError(t, err)
+	assert.Empty(t, changed)
+
+	_, err = moveMenus(newMenus(), []*types.MoveMenuItem{{ID: 1, ParentID: 3}})
+	assert.ErrorIs(t, err, ErrMenuCycle)
+	_, err = moveMenus(newMenus(), []*types.MoveMenuItem{{ID: 1, ParentID: 1}})
+	assert.ErrorIs(t, err, ErrMenuCycle)
+	_, err = moveMenus(newMenus(), []*types.MoveMenuItem{{ID: 7, ParentID: 0}})
+	assert.ErrorIs(t, err, database.ErrRecordNotFound)
+	_, err = moveMenus(newMenus(), []*types.MoveMenuItem{{ID: 2, ParentID: 7}})
+	assert.ErrorIs(t, err, database.ErrRecordNotFound)
+
+	// the new parent is a button or an external link
+	_, err = moveMenus(newMenus(), []*types.MoveMenuItem{{ID: 3, ParentID: 5}})
+	assert.ErrorIs(t, err, ErrMenuParentType)
+	_, err = moveMenus(newMenus(), []*types.MoveMenuItem{{ID: 3, ParentID: 6}})
+	assert.ErrorIs(t, err, ErrMenuParentType)
+}
+
+func Test_menuDao_Import(t *testing.T) {
+	d := newMenuDao()
+	defer d.Close()
+
+	columns := []string{"id", "parent_id", "name", "type", "path", "component", "perm", "sort", "visible", "keep_alive", "titles"}
+	menuRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows(columns).
+			AddRow(1, 0, "系统管理", MenuTypeCatalog, "/system", "Layout", "", 1, 1, 1, nil).
+			AddRow(2, 1, "新增", MenuTypeButton, "", "", "sys:a", 1, 1, 1, nil).
+			AddRow(3, 0, "不在清单中", MenuTypeMenu, "other", "", "", 2, 1, 1, nil)
+	}
+	manifest := &types.MenuManifest{Menus: []*types.MenuManifestItem{
+		{Name: "系统管理", Type: MenuTypeCatalog, Path: "/system", Component: "Layout", Titles: types.LocalText{"en": "System"},
+			Children: []*types.MenuManifestItem{
+				{Name: "新增", Type: MenuTypeButton, Perm: "sys:a"},
+				{Name: "删除", Type: MenuTypeButton, Perm: "sys:b"},
+			}},
+	}}
+
+	// dry run
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(menuRows())
+	d.SQLMock.ExpectCommit()
+	report, err := d.IDao.(MenuDao).Import(d.Ctx, manifest, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, report.DryRun)
+	assert.Equal(t, 1, report.Unchanged)
+	if assert.Len(t, report.Updated, 1) {
+		assert.Equal(t, "path:/system", report.Updated[0].Key)
+		assert.Equal(t, uint64(1), report.Updated[0].ID)
+		assert.Equal(t, []string{"titles"}, report.Updated[0].Fields)
+	}
+	if assert.Len(t, report.Created, 1) {
+		assert.Equal(t, "path:/system > perm:sys:b", report.Created[0].Key)
+		assert.Zero(t, report.Created[0].ID)
+	}
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+
+	// write, the menu not in the manifest is kept
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(menuRows())
+	d.SQLMock.ExpectExec("UPDATE .*").WillReturnResult(sqlmock.NewResult(1, 1))
+	d.SQLMock.ExpectExec("INSERT INTO .*").WillReturnResult(sqlmock.NewResult(4, 1))
+	d.SQLMock.ExpectCommit()
+	report, err = d.IDao.(MenuDao).Import(d.Ctx, manifest, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, report.Created, 1) {
+		assert.Equal(t, uint64(4), report.Created[0].ID)
+	}
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+}
+
+func Test_menuDao_Import_scoped(t *testing.T) {
+	d := newMenuDao()
+	defer d.Close()
+
+	columns := []string{"id", "parent_id", "name", "type", "path", "component", "perm", "sort", "visible", "keep_alive"}
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, 0, "用户", MenuTypeMenu, "/user", "", "", 1, 1, 1).
+		AddRow(2, 1, "导出", MenuTypeButton, "", "", "sys:export", 1, 1, 1).
+		AddRow(3, 0, "角色", MenuTypeMenu, "/role", "", "", 2, 1, 1).
+		AddRow(4, 3, "导出", MenuTypeButton, "", "", "sys:export", 1, 1, 1).
+		AddRow(5, 0, "日志", MenuTypeMenu, "/log", "", "", 3, 1, 1)
+	manifest := &types.MenuManifest{Menus: []*types.MenuManifestItem{
+		{Name: "用户", Type: MenuTypeMenu, Path: "/user", Sort: 1, Children: []*types.MenuManifestItem{
+			{Name: "导出", Type: MenuTypeButton, Perm: "sys:export"},
+		}},
+		{Name: "角色", Type: MenuTypeMenu, Path: "/role", Sort: 2, Children: []*types.MenuManifestItem{
+			{Name: "导出角色", Type: MenuTypeButton, Perm: "sys:export"},
+		}},
+		// a new catalog the existing menu is moved under
+		{Name: "监控", Type: MenuTypeCatalog, Path: "/monitor", Sort: 4, Children: []*types.MenuManifestItem{
+			{Name: "日志", Type: MenuTypeMenu, Path: "/log", Sort: 3},
+		}},
+	}}
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+	d.SQLMock.ExpectCommit()
+	report, err := d.IDao.(MenuDao).Import(d.Ctx, manifest, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the buttons sharing a perm are matched under their own parent
+	if assert.Len(t, report.Updated, 1) {
+		assert.Equal(t, "path:/role > perm:sys:export", report.Updated[0].Key)
+		assert.Equal(t, uint64(4), report.Updated[0].ID)
+		assert.Equal(t, []string{"name"}, report.Updated[0].Fields)
+	}
+	if assert.Len(t, report.Created, 1) {
+		assert.Equal(t, "path:/monitor", report.Created[0].Key)
+	}
+	assert.Equal(t, 4, report.Unchanged)
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+}
+
+func Test_menuDao_Export(t *testing.T) {
+	d := newMenuDao()
+	defer d.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "parent_id", "name", "type", "path", "perm", "sort", "visible", "keep_alive", "params"}).
+		AddRow(1, 0, "系统管理", MenuTypeCatalog, "/system", "", 1, 1, 1, nil).
+		AddRow(2, 1, "新增", MenuTypeButton, "", "sys:a", 1, 0, 1, `[{"key": "a", "value": "1"}]`).
+		AddRow(3, 9, "父级不存在", MenuTypeMenu, "orphan", "", 1, 1, 1, nil).
+		AddRow(4, 1, "新增", MenuTypeButton, "", "sys:a", 2, 1, 1, nil).
+		AddRow(5, 1, "无权限", MenuTypeButton, "", "", 3, 1, 1, nil)
+	d.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	manifest, err := d.IDao.(MenuDao).Export(d.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, manifest.Menus, 1) && assert.Len(t, manifest.Menus[0].Children, 1) {
+		button := manifest.Menus[0].Children[0]
+		assert.Equal(t, "sys:a", button.Perm)
+		assert.Equal(t, 0, *button.Visible)
+		assert.Equal(t, "1", button.Params[0]["value"])
+	}
+	// the menus which can not be imported again are listed instead
+	assert.Equal(t, []string{
+		"新增 (id 4): duplicate key path:/system > perm:sys:a",
+		"无权限 (id 5): neither a path nor a perm",
+	}, manifest.Skipped)
+	assert.NoError(t, menumanifest.Validate(manifest.Menus))
+}
+
+func Test_diffMenu(t *testing.T) {
+	visible := 1
+	old := &model.Menu{Name: "a", Visible: &visible, KeepAlive: 1, Params: types.LocalJSON{}}
+	menu := manifestMenu(&types.MenuManifestItem{Name: "a"}, 0, 1)
+	assert.Equal(t, []string{"sort"}, diffMenu(old, menu))
+
+	old.Sort = 1
+	assert.Empty(t, diffMenu(old, menu))
+
+	menu.ParentID, menu.Params = 2, types.LocalJSON{{"key": "a"}}
+	assert.Equal(t, []string{"parentId", "params"}, diffMenu(old, menu))
+}
+
+func Test_descendantMenus(t *testing.T) {
+	menus := []*model.Menu{
+		{Model: sgorm.Model{ID: 1}, ParentID: 0},
+		{Model: sgorm.Model{ID: 2}, ParentID: 1},
+		{Model: sgorm.Model{ID: 3}, ParentID: 2},
+		{Model: sgorm.Model{ID: 4}, ParentID: 0},
+		{Model: sgorm.Model{ID: 5}, ParentID: 6}, // a cycle
+		{Model: sgorm.Model{ID: 6}, ParentID: 5},
+	}
+	ids := func(dependents []*types.MenuDependent) []uint64 {
+		var ids []uint64
+		for _, d := range dependents {
+			ids = append(ids, d.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []uint64{2, 3}, ids(descendantMenus(menus, []uint64{1})))
+	assert.Equal(t, []uint64{3}, ids(descendantMenus(menus, []uint64{2, 1})))
+	assert.Empty(t, descendantMenus(menus, []uint64{4}))
+	assert.Equal(t, []uint64{6}, ids(descendantMenus(menus, []uint64{5})))
+
+	roles := groupRoleLinks([]*roleMenuLink{
+		{RoleID: 1, MenuID: 1, RoleName: "a"},
+		{RoleID: 1, MenuID: 2, RoleName: "a"},
+		{RoleID: 2, MenuID: 2, RoleName: "b"},
+	})
+	if assert.Len(t, roles, 2) {
+		assert.Equal(t, []uint64{1, 2}, roles[0].MenuIDs)
+		assert.Equal(t, "b", roles[1].RoleName)
+	}
+}
+
+func Test_MenuButtons(t *testing.T) {
+	menu := &model.Menu{Name: "配置管理", Titles: types.LocalText{"en": "Configs"}}
+	buttons := MenuButtons(menu, "sys:config:*", nil)
+	if assert.Len(t, buttons, 4) {
+		assert.Equal(t, "配置新增", buttons[0].Name)
+		assert.Equal(t, "sys:config:add", buttons[0].Perm)
+		assert.Equal(t, "Add Configs", buttons[0].Titles["en"])
+		assert.Equal(t, "sys:config:query", buttons[3].Perm)
+		assert.Equal(t, 4, buttons[3].Sort)
+	}
+
+	// the standard order, not the order of the request
+	buttons = MenuButtons(&model.Menu{Name: "日志"}, "sys:log", []string{"query", "delete"})
+	if assert.Len(t, buttons, 2) {
+		assert.Equal(t, "日志删除", buttons[0].Name)
+		assert.Equal(t, "Delete", buttons[0].Titles["en"])
+		assert.Equal(t, 1, buttons[0].Sort)
+		assert.Equal(t, "sys:log:query", buttons[1].Perm)
+	}
+}
+
+func Test_menuDao_CreateWithButtons(t *testing.T) {
+	d := newMenuDao()
+	defer d.Close()
+
+	menu := &model.Menu{Name: "配置管理", Type: MenuTypeMenu, Path: "system/config"}
+	buttons := MenuButtons(menu, "sys:config", []string{"add", "edit"})
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT count.*").WithArgs("sys:config:add", "sys:config:edit").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	d.SQLMock.ExpectQuery("SELECT count.*").WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	d.SQLMock.ExpectExec("INSERT INTO `t_menu`.*").WillReturnResult(sqlmock.NewResult(10, 1))
+	d.SQLMock.ExpectExec("INSERT INTO `t_menu`.*").WillReturnResult(sqlmock.NewResult(11, 1))
+	d.SQLMock.ExpectExec("INSERT INTO `t_menu`.*").WillReturnResult(sqlmock.NewResult(12, 1))
+	d.SQLMock.ExpectExec("INSERT INTO `t_role_menu`.*").WillReturnResult(sqlmock.NewResult(1, 6))
+	d.SQLMock.ExpectCommit()
+
+	err := d.IDao.(MenuDao).CreateWithButtons(d.Ctx, menu, buttons, []uint64{1, 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(10), menu.ID)
+	assert.Equal(t, 10, buttons[1].ParentID)
+	assert.Equal(t, uint64(12), buttons[1].ID)
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+
+	// a perm exists
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT count.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	d.SQLMock.ExpectRollback()
+	err = d.IDao.(MenuDao).CreateWithButtons(d.Ctx, &model.Menu{}, buttons, nil)
+	assert.ErrorIs(t, err, ErrMenuPermExists)
+
+	// a role does not exist
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT count.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	d.SQLMock.ExpectRollback()
+	err = d.IDao.(MenuDao).CreateWithButtons(d.Ctx, &model.Menu{}, nil, []uint64{1, 3})
+	assert.ErrorIs(t, err, database.ErrRecordNotFound)
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+}
+
+func Test_menuDao_Perms(t *testing.T) {
+	d := newMenuDao()
+	defer d.Close()
+
+	rows := sqlmock.NewRows([]string{"perm"}).AddRow("sys:menu:add").AddRow("sys:menu:edit")
+	d.SQLMock.ExpectQuery("SELECT DISTINCT .*").WillReturnRows(rows)
+
+	perms, err := d.IDao.(MenuDao).Perms(d.Ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"sys:menu:add", "sys:menu:edit"}, perms)
+}
@@ -0,0 +1,209 @@
+package dao
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-dev-frame/sponge/pkg/utils"
+	"gorm.io/gorm"
+
+	"admin/internal/model"
+	"admin/internal/types"
+)
+
+// reasons a perm is not granted
+const (
+	PermReasonNotFound    = "not_found"    // no menu has the perm
+	PermReasonNotAssigned = "not_assigned" // no role of the platform is assigned the menus
+)
+
+var _ PermissionDao = (*permissionDao)(nil)
+
+// PermissionDao defining the dao interface
+type PermissionDao interface {
+	Explain(ctx context.Context, request *types.ExplainPermissionRequest) (*types.PermissionExplanationReport, error)
+}
+
+// the explanations are read from the database, so they are not cached
+type permissionDao struct {
+	db *gorm.DB
+}
+
+// NewPermissionDao creating the dao interface
+func NewPermissionDao(db *gorm.DB) PermissionDao {
+	return &permissionDao{db: db}
+}
+
+// permissionLink a menu assigned to a role
+type permissionLink struct {
+	RoleID      uint64
+	MenuID      uint64
+	MenuName    string
+	Perm        string
+	Visible     int
+	MenuDeleted bool
+}
+
+// permissionMenu a menu to explain
+type permissionMenu struct {
+	ID   uint64
+	Name string
+	Perm string
+}
+
+// Explain the effective perms of the platform, every menu assigned to a role of the platform grants its perm
+// whatever the status of the role and the visibility of the menu are, the same as GetPermissionsByIds, which
+// does not skip the soft deleted links and menus either, so the menus are explained by the same rule. the status
+// and the visibility are returned with every grant for information. the perm or the menu of the request is
+// explained, all the perms of the menus if neither is given. database.ErrRecordNotFound if the platform does not exist
+func (d *permissionDao) Explain(ctx context.Context, request *types.ExplainPermissionRequest) (*types.PermissionExplanationReport, error) {
+	db := d.db.WithContext(ctx)
+	platform := &model.Platform{}
+	err := db.Select("id", "username", "role_id", "status").Where("id = ?", request.PlatformID).First(platform).Error
+	if err != nil {
+		return nil, err
+	}
+	report := &types.PermissionExplanationReport{
+		PlatformID:     platform.ID,
+		Username:       platform.Username,
+		Roles:          []*types.PermissionRole{},
+		MissingRoleIDs: []uint64{},
+		Perms:          []string{},
+		Explanations:   []*types.PermissionExplanation{},
+	}
+	if platform.Status != nil {
+		report.Status = *platform.Status
+	}
+
+	roles := map[uint64]*types.PermissionRole{}
+	links := []*permissionLink{}
+	if len(platform.RoleID) > 0 {
+		records := []*model.Role{}
+		err = db.Where("id IN ?", []uint64(platform.RoleID)).Order("sort asc, id asc").Find(&records).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, role := range records {
+			roles[role.ID] = &types.PermissionRole{ID: role.ID, Name: role.Name, Code: role.Code, Status: role.Status}
+			report.Roles = append(report.Roles, roles[role.ID])
+		}
+		for _, id := range platform.RoleID {
+			if roles[id] == nil {
+				report.MissingRoleIDs = append(report.MissingRoleIDs, id)
+			}
+		}
+
+		if len(roles) > 0 {
+			roleIDs := make([]uint64, 0, len(report.Roles))
+			for _, role := range report.Roles {
+				roleIDs = append(roleIDs, role.ID)
+			}
+			// the same joins as GetPermissionsByIds, which grants the perms at login
+			err = db.Model(&model.RoleMenu{}).Unscoped().
+				Select("t_role_menu.role_id, t_role_menu.menu_id, menu.name AS menu_name, menu.perm, menu.visible, "+
+					"menu.deleted_at IS NOT NULL AS menu_deleted").
+				Joins("JOIN t_menu AS menu ON menu.id = t_role_menu.menu_id").
+				Where("t_role_menu.role_id IN ?", roleIDs).
+				Order("t_role_menu.role_id asc, t_role_menu.menu_id asc").
+				Scan(&links).Error
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	menus := []*permissionMenu{}
+	query := db.Model(&model.Menu{}).Unscoped().Select("id", "name", "perm").Order("perm asc, id asc")
+	switch {
+	case request.MenuID > 0:
+		query = query.Where("id = ?", request.MenuID)
+	case request.Perm != "":
+		query = query.Where("perm = ?", request.Perm)
+	default:
+		query = query.Where("perm <> ''")
+	}
+	if err = query.Scan(&menus).Error; err != nil {
+		return nil, err
+	}
+
+	report.Perms = effectivePerms(links)
+	report.Explanations = explainPerms(request, menus, links, roles)
+	return report, nil
+}
+
+func effectivePerms(links []*permissionLink) []string {
+	perms := []string{}
+	seen := map[string]bool{}
+	for _, link := range links {
+		if link.Perm == "" || seen[link.Perm] {
+			continue
+		}
+		seen[link.Perm] = true
+		perms = append(perms, link.Perm)
+	}
+	sort.Strings(perms)
+	return perms
+}
+
+// explainPerms explain the perms of the menus, a menu without a perm is explained by itself
+func explainPerms(request *types.ExplainPermissionRequest, menus []*permissionMenu,
+	links []*permissionLink, roles map[uint64]*types.PermissionRole) []*types.PermissionExplanation {
+	explanations := []*types.PermissionExplanation{}
+	byKey := map[string]*types.PermissionExplanation{}
+	menuKeys := map[uint64]string{}
+	for _, menu := range menus {
+		key := "perm:" + menu.Perm
+		if menu.Perm == "" {
+			key = "menu:" + utils.Uint64ToStr(menu.ID)
+		}
+		menuKeys[menu.ID] = key
+		e := byKey[key]
+		if e == nil {
+			e = &types.PermissionExplanation{
+				Perm:      menu.Perm,
+				MenuIDs:   []uint64{},
+				GrantedBy: []*types.PermissionGrant{},
+			}
+			byKey[key] = e
+			explanations = append(explanations, e)
+		}
+		e.MenuIDs = append(e.MenuIDs, menu.ID)
+	}
+	if len(menus) == 0 && (request.MenuID > 0 || request.Perm != "") {
+		explanations = append(explanations, &types.PermissionExplanation{
+			Perm:      request.Perm,
+			MenuIDs:   []uint64{},
+			GrantedBy: []*types.PermissionGrant{},
+			Reason:    PermReasonNotFound,
+		})
+		return explanations
+	}
+
+	for _, link := range links {
+		key, ok := menuKeys[link.MenuID]
+		if !ok {
+			continue
+		}
+		e := byKey[key]
+		grant := &types.PermissionGrant{
+			RoleID:      link.RoleID,
+			MenuID:      link.MenuID,
+			MenuName:    link.MenuName,
+			MenuVisible: link.Visible,
+			MenuDeleted: link.MenuDeleted,
+		}
+		if role := roles[link.RoleID]; role != nil {
+			grant.RoleName = role.Name
+			grant.RoleStatus = role.Status
+		}
+		e.GrantedBy = append(e.GrantedBy, grant)
+	}
+
+	for _, e := range explanations {
+		e.Granted = len(e.GrantedBy) > 0
+		if !e.Granted {
+			e.Reason = PermReasonNotAssigned
+		}
+	}
+	return explanations
+}
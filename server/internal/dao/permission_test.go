@@ -0,0 +1,119 @@
+package dao
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/stretchr/testify/assert"
+
+	"admin/internal/database"
+	"admin/internal/model"
+	"admin/internal/types"
+)
+
+func newPermissionDao() *gotest.Dao {
+	testData := &model.Platform{}
+	testData.ID = 1
+
+	c := gotest.NewCache(map[string]interface{}{"no cache": testData})
+	d := gotest.NewDao(c, testData)
+	d.IDao = NewPermissionDao(d.DB)
+
+	return d
+}
+
+func Test_permissionDao_Explain(t *testing.T) {
+	d := newPermissionDao()
+	defer d.Close()
+
+	d.SQLMock.ExpectQuery("SELECT `id`,`username`,`role_id`,`status` FROM `t_platform` .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "role_id", "status"}).AddRow(1, "admin", "[1,2,3]", 1))
+	d.SQLMock.ExpectQuery("SELECT \\* FROM `t_role` .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "code", "status"}).
+			AddRow(1, "管理员", "ADMIN", 1).AddRow(2, "访客", "GUEST", 0))
+	// the links of the existing roles, the same as GetPermissionsByIds
+	d.SQLMock.ExpectQuery("SELECT t_role_menu.role_id.*").
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"role_id", "menu_id", "menu_name", "perm", "visible", "menu_deleted"}).
+			AddRow(1, 3, "菜单新增", "sys:menu:add", 1, false).
+			AddRow(1, 4, "菜单编辑", "sys:menu:edit", 0, false).
+			AddRow(1, 7, "菜单导出", "sys:menu:export", 1, true).
+			AddRow(2, 5, "菜单删除", "sys:menu:delete", 1, false))
+	// the deleted menus are explained too, the same scope as the links
+	d.SQLMock.ExpectQuery("SELECT `id`,`name`,`perm` FROM `t_menu` WHERE perm <> '' ORDER BY .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "perm"}).
+			AddRow(3, "菜单新增", "sys:menu:add").
+			AddRow(5, "菜单删除", "sys:menu:delete").
+			AddRow(4, "菜单编辑", "sys:menu:edit").
+			AddRow(7, "菜单导出", "sys:menu:export").
+			AddRow(6, "配置新增", "sys:config:add"))
+
+	report, err := d.IDao.(PermissionDao).Explain(d.Ctx, &types.ExplainPermissionRequest{PlatformID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "admin", report.Username)
+	assert.Len(t, report.Roles, 2)
+	assert.Equal(t, []uint64{3}, report.MissingRoleIDs)
+	// the disabled role, the hidden menu and the deleted menu grant their perms too
+	assert.Equal(t, []string{"sys:menu:add", "sys:menu:delete", "sys:menu:edit", "sys:menu:export"}, report.Perms)
+
+	reasons := map[string]string{}
+	for _, e := range report.Explanations {
+		reasons[e.Perm] = e.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"sys:menu:add":    "",
+		"sys:menu:delete": "",
+		"sys:menu:edit":   "",
+		"sys:menu:export": "",
+		"sys:config:add":  PermReasonNotAssigned,
+	}, reasons)
+	if assert.Len(t, report.Explanations[0].GrantedBy, 1) {
+		assert.Equal(t, "管理员", report.Explanations[0].GrantedBy[0].RoleName)
+	}
+	grants := map[string]*types.PermissionGrant{}
+	for _, e := range report.Explanations {
+		if e.Granted {
+			grants[e.Perm] = e.GrantedBy[0]
+		}
+	}
+	assert.Equal(t, &types.PermissionGrant{RoleID: 2, RoleName: "访客", RoleStatus: 0, MenuID: 5, MenuName: "菜单删除", MenuVisible: 1},
+		grants["sys:menu:delete"])
+	assert.Equal(t, 0, grants["sys:menu:edit"].MenuVisible)
+	assert.True(t, grants["sys:menu:export"].MenuDeleted)
+	assert.Equal(t, 1, grants["sys:menu:add"].RoleStatus)
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+
+	// the platform does not exist
+	d.SQLMock.ExpectQuery("SELECT .* FROM `t_platform` .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	_, err = d.IDao.(PermissionDao).Explain(d.Ctx, &types.ExplainPermissionRequest{PlatformID: 9})
+	assert.ErrorIs(t, err, database.ErrRecordNotFound)
+}
+
+func Test_explainPerms(t *testing.T) {
+	roles := map[uint64]*types.PermissionRole{1: {ID: 1, Name: "管理员", Status: 1}}
+	links := []*permissionLink{{RoleID: 1, MenuID: 2, MenuName: "菜单管理"}}
+
+	// a menu without a perm is explained by itself
+	request := &types.ExplainPermissionRequest{PlatformID: 1, MenuID: 2}
+	explanations := explainPerms(request, []*permissionMenu{{ID: 2, Name: "菜单管理"}}, links, roles)
+	if assert.Len(t, explanations, 1) {
+		assert.True(t, explanations[0].Granted)
+		assert.Equal(t, []uint64{2}, explanations[0].MenuIDs)
+	}
+
+	// no menu has the perm
+	request = &types.ExplainPermissionRequest{PlatformID: 1, Perm: "sys:unknown"}
+	explanations = explainPerms(request, nil, links, roles)
+	if assert.Len(t, explanations, 1) {
+		assert.False(t, explanations[0].Granted)
+		assert.Equal(t, "sys:unknown", explanations[0].Perm)
+		assert.Equal(t, PermReasonNotFound, explanations[0].Reason)
+	}
+
+	// no perm is explained without a filter if there are no menus
+	explanations = explainPerms(&types.ExplainPermissionRequest{PlatformID: 1}, nil, links, roles)
+	assert.Empty(t, explanations)
+}
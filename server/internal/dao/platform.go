@@ -31,6 +31,7 @@ type PlatformDao interface {
 	GetByParams(ctx context.Context, params *types.ListPlatformsRequest) ([]*model.Platform, int64, error)
 	GetByUsername(ctx context.Context, username string) (*model.Platform, error)
 	Options(ctx context.Context, roleCode string) ([]types.Options, error)
+	WarmCache(ctx context.Context, limit int) (int, error)
 
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Platform) (uint64, error)
 	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
@@ -139,6 +140,9 @@ func (d *platformDao) updateDataByID(ctx context.Context, db *gorm.DB, table *mo
 	if table.Mobile != "" {
 		update["mobile"] = table.Mobile
 	}
+	if table.Locale != "" {
+		update["locale"] = table.Locale
+	}
 
 	return db.WithContext(ctx).Model(table).Updates(update).Error
 }
@@ -305,6 +309,25 @@ func (d *platformDao) GetByParams(ctx context.Context, request *types.ListPlatfo
 	return records, total, err
 }
 
+// WarmCache load the latest records into the cache, return the number of loaded records
+func (d *platformDao) WarmCache(ctx context.Context, limit int) (int, error) {
+	if d.cache == nil {
+		return 0, nil
+	}
+	records := []*model.Platform{}
+	err := d.db.WithContext(ctx).Order("id DESC").Limit(limit).Find(&records).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	if err = d.cache.MultiSet(ctx, records, cache.PlatformExpireTime); err != nil {
+		return 0, err
+	}
+	return len(records), nil
+}
+
 // CreateByTx create a record in the database using the provided transaction
 func (d *platformDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Platform) (uint64, error) {
 	err := tx.WithContext(ctx).Create(table).Error
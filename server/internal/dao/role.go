@@ -30,6 +30,7 @@ type RoleDao interface {
 	GetByColumns(ctx context.Context, params *query.Params) ([]*model.Role, int64, error)
 	GetByParams(ctx context.Context, params *types.ListRolesRequest) ([]*model.Role, int64, error)
 	GetPermissionsByIds(ctx context.Context, ids []uint64) ([]string, error)
+	WarmCache(ctx context.Context, limit int) (int, error)
 
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Role) (uint64, error)
 	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
@@ -360,6 +361,25 @@ func (d *roleDao) GetByParams(ctx context.Context, request *types.ListRolesReque
 	return records, total, err
 }
 
+// WarmCache load the latest records into the cache, return the number of loaded records
+func (d *roleDao) WarmCache(ctx context.Context, limit int) (int, error) {
+	if d.cache == nil {
+		return 0, nil
+	}
+	records := []*model.Role{}
+	err := d.db.WithContext(ctx).Order("id DESC").Limit(limit).Find(&records).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	if err = d.cache.MultiSet(ctx, records, cache.RoleExpireTime); err != nil {
+		return 0, err
+	}
+	return len(records), nil
+}
+
 // CreateByTx create a record in the database using the provided transaction
 func (d *roleDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.Role) (uint64, error) {
 	err := tx.WithContext(ctx).Create(table).Error
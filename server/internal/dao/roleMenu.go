@@ -11,6 +11,7 @@ import (
 	"github.com/go-dev-frame/sponge/pkg/utils"
 	"golang.org/x/sync/singleflight"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"admin/internal/cache"
 	"admin/internal/model"
@@ -27,7 +28,8 @@ type RoleMenuDao interface {
 	GetByID(ctx context.Context, id uint64) (*model.RoleMenu, error)
 	GetByColumns(ctx context.Context, params *query.Params) ([]*model.RoleMenu, int64, error)
 	GetByParams(ctx context.Context, params *types.ListRoleMenusRequest) ([]*model.RoleMenu, int64, error)
-	UpdateByRoleIds(ctx context.Context, roleId uint64, menuIds []uint64) error
+	SyncByRoleID(ctx context.Context, roleID uint64, menuIDs []uint64) (*types.RoleMenuChanges, error)
+	WarmCache(ctx context.Context, limit int) (int, error)
 
 	CreateByTx(ctx context.Context, tx *gorm.DB, table *model.RoleMenu) (uint64, error)
 	DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) error
@@ -59,9 +61,26 @@ func (d *roleMenuDao) deleteCache(ctx context.Context, id uint64) error {
 	return nil
 }
 
+// deleteTreeCache invalidate the menus of all role sets after the role-menu links are changed
+func (d *roleMenuDao) deleteTreeCache(ctx context.Context) {
+	if d.cache == nil {
+		return
+	}
+	if err := d.cache.DelTrees(ctx); err != nil {
+		logger.Warn("cache.DelTrees error", logger.Err(err))
+	}
+}
+
 // Create a record, insert the record and the id value is written back to the table
 func (d *roleMenuDao) Create(ctx context.Context, table *model.RoleMenu) error {
-	return d.db.WithContext(ctx).Create(table).Error
+	err := d.db.WithContext(ctx).Create(table).Error
+	if err != nil {
+		return err
+	}
+
+	d.deleteTreeCache(ctx)
+
+	return nil
 }
 
 // DeleteByID delete a record by id
@@ -73,6 +92,7 @@ func (d *roleMenuDao) DeleteByID(ctx context.Context, id uint64) error {
 
 	// delete cache
 	_ = d.deleteCache(ctx, id)
+	d.deleteTreeCache(ctx)
 
 	return nil
 }
@@ -88,6 +108,7 @@ func (d *roleMenuDao) DeleteByIDs(ctx context.Context, ids []uint64) error {
 	for _, id := range ids {
 		_ = d.deleteCache(ctx, id)
 	}
+	d.deleteTreeCache(ctx)
 
 	return nil
 }
@@ -98,6 +119,7 @@ func (d *roleMenuDao) UpdateByID(ctx context.Context, table *model.RoleMenu) err
 
 	// delete cache
 	_ = d.deleteCache(ctx, table.ID)
+	d.deleteTreeCache(ctx)
 
 	return err
 }
@@ -269,9 +291,31 @@ func (d *roleMenuDao) GetByParams(ctx context.Context, request *types.ListRoleMe
 	return records, total, err
 }
 
+// WarmCache load the latest records into the cache, return the number of loaded records
+func (d *roleMenuDao) WarmCache(ctx context.Context, limit int) (int, error) {
+	if d.cache == nil {
+		return 0, nil
+	}
+	records := []*model.RoleMenu{}
+	err := d.db.WithContext(ctx).Order("id DESC").Limit(limit).Find(&records).Error
+	if err != nil {
+		return 0, err
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+	if err = d.cache.MultiSet(ctx, records, cache.RoleMenuExpireTime); err != nil {
+		return 0, err
+	}
+	return len(records), nil
+}
+
 // CreateByTx create a record in the database using the provided transaction
 func (d *roleMenuDao) CreateByTx(ctx context.Context, tx *gorm.DB, table *model.RoleMenu) (uint64, error) {
 	err := tx.WithContext(ctx).Create(table).Error
+	if err == nil {
+		d.deleteTreeCache(ctx)
+	}
 	return table.ID, err
 }
 
@@ -284,6 +328,7 @@ func (d *roleMenuDao) DeleteByTx(ctx context.Context, tx *gorm.DB, id uint64) er
 
 	// delete cache
 	_ = d.deleteCache(ctx, id)
+	d.deleteTreeCache(ctx)
 
 	return nil
 }
@@ -294,29 +339,93 @@ func (d *roleMenuDao) UpdateByTx(ctx context.Context, tx *gorm.DB, table *model.
 
 	// delete cache
 	_ = d.deleteCache(ctx, table.ID)
+	d.deleteTreeCache(ctx)
 
 	return err
 }
 
-func (d *roleMenuDao) UpdateByRoleIds(ctx context.Context, roleId uint64, menuIds []uint64) error {
-	tx := d.db.Begin()
+// SyncByRoleID make the menus of the role exactly menuIDs in one transaction, only the missing links are
+// inserted and the links of the other menus are deleted, the duplicate links of a menu are cleaned up.
+// the role row is locked so the syncs of a role are serialized. database.ErrRecordNotFound if the role or
+// an added menu does not exist
+func (d *roleMenuDao) SyncByRoleID(ctx context.Context, roleID uint64, menuIDs []uint64) (*types.RoleMenuChanges, error) {
+	changes := &types.RoleMenuChanges{RoleID: roleID, Added: []uint64{}, Removed: []uint64{}}
+	var deletedIDs []uint64
+	err := d.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		role := &model.Role{}
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", roleID).First(role).Error
+		if err != nil {
+			return err
+		}
 
-	if err := d.DeleteByTx(ctx, tx, roleId); err != nil {
-		tx.Rollback()
-		return err
+		links := []*model.RoleMenu{}
+		err = tx.Select("id", "menu_id").Where("role_id = ?", roleID).Order("id asc").Find(&links).Error
+		if err != nil {
+			return err
+		}
+
+		wanted := make(map[uint64]bool, len(menuIDs))
+		for _, menuID := range menuIDs {
+			wanted[menuID] = true
+		}
+		existing := make(map[uint64]bool, len(links))
+		for _, link := range links {
+			switch {
+			case existing[link.MenuID]: // the oldest link of a menu is kept
+				deletedIDs = append(deletedIDs, link.ID)
+				if wanted[link.MenuID] {
+					changes.Deduplicated++
+				}
+			case !wanted[link.MenuID]:
+				deletedIDs = append(deletedIDs, link.ID)
+				changes.Removed = append(changes.Removed, link.MenuID)
+			}
+			existing[link.MenuID] = true
+		}
+		for _, menuID := range menuIDs {
+			if !existing[menuID] {
+				existing[menuID] = true
+				changes.Added = append(changes.Added, menuID)
+			}
+		}
+
+		if len(changes.Added) > 0 {
+			var count int64
+			err = tx.Model(&model.Menu{}).Where("id IN ?", changes.Added).Count(&count).Error
+			if err != nil {
+				return err
+			}
+			if int(count) != len(changes.Added) {
+				return database.ErrRecordNotFound
+			}
+		}
+
+		if len(deletedIDs) > 0 {
+			err = tx.Where("id IN ?", deletedIDs).Unscoped().Delete(&model.RoleMenu{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if len(changes.Added) > 0 {
+			items := make([]*model.RoleMenu, 0, len(changes.Added))
+			for _, menuID := range changes.Added {
+				items = append(items, &model.RoleMenu{RoleID: roleID, MenuID: menuID})
+			}
+			return tx.Create(items).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	var items []model.RoleMenu
-	for _, menuId := range menuIds {
-		items = append(items, model.RoleMenu{
-			RoleID: roleId,
-			MenuID: menuId,
-		})
+	// delete cache
+	for _, id := range deletedIDs {
+		_ = d.deleteCache(ctx, id)
 	}
-	if err := tx.WithContext(ctx).Create(items).Error; err != nil {
-		tx.Rollback()
-		return err
+	if changes.Changed() {
+		d.deleteTreeCache(ctx)
 	}
-	tx.Commit()
-	return nil
+
+	return changes, nil
 }
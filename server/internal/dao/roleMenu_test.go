@@ -232,3 +232,83 @@ func Test_roleMenuDao_UpdateByTx(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func Test_roleMenuDao_SyncByRoleID(t *testing.T) {
+	d := newRoleMenuDao()
+	defer d.Close()
+
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT `id` FROM `t_role` .* FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	d.SQLMock.ExpectQuery("SELECT `id`,`menu_id` FROM `t_role_menu` .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "menu_id"}).
+			AddRow(1, 1).AddRow(2, 2).AddRow(3, 2).AddRow(4, 3).AddRow(5, 3))
+	d.SQLMock.ExpectQuery("SELECT count.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	d.SQLMock.ExpectExec("DELETE FROM `t_role_menu` WHERE id IN .*").
+		WithArgs(3, 4, 5).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	d.SQLMock.ExpectExec("INSERT INTO `t_role_menu`.*").WillReturnResult(sqlmock.NewResult(6, 1))
+	d.SQLMock.ExpectCommit()
+
+	changes, err := d.IDao.(RoleMenuDao).SyncByRoleID(d.Ctx, 1, []uint64{1, 2, 4, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []uint64{4}, changes.Added)
+	assert.Equal(t, []uint64{3}, changes.Removed)
+	assert.Equal(t, 1, changes.Deduplicated)
+	assert.NoError(t, d.SQLMock.ExpectationsWereMet())
+
+	// clear all the menus
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT `id` FROM `t_role` .* FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	d.SQLMock.ExpectQuery("SELECT `id`,`menu_id` FROM `t_role_menu` .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "menu_id"}).AddRow(1, 1))
+	d.SQLMock.ExpectExec("DELETE FROM `t_role_menu` WHERE id IN .*").
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	d.SQLMock.ExpectCommit()
+
+	changes, err = d.IDao.(RoleMenuDao).SyncByRoleID(d.Ctx, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, changes.Added)
+	assert.Equal(t, []uint64{1}, changes.Removed)
+
+	// unchanged, nothing is written
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT `id` FROM `t_role` .* FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	d.SQLMock.ExpectQuery("SELECT `id`,`menu_id` FROM `t_role_menu` .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "menu_id"}).AddRow(1, 1))
+	d.SQLMock.ExpectCommit()
+
+	changes, err = d.IDao.(RoleMenuDao).SyncByRoleID(d.Ctx, 1, []uint64{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, changes.Changed())
+
+	// a menu does not exist
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT `id` FROM `t_role` .* FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	d.SQLMock.ExpectQuery("SELECT `id`,`menu_id` FROM `t_role_menu` .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "menu_id"}))
+	d.SQLMock.ExpectQuery("SELECT count.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	d.SQLMock.ExpectRollback()
+
+	_, err = d.IDao.(RoleMenuDao).SyncByRoleID(d.Ctx, 1, []uint64{9})
+	assert.ErrorIs(t, err, database.ErrRecordNotFound)
+
+	// the role does not exist
+	d.SQLMock.ExpectBegin()
+	d.SQLMock.ExpectQuery("SELECT `id` FROM `t_role` .* FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	d.SQLMock.ExpectRollback()
+
+	_, err = d.IDao.(RoleMenuDao).SyncByRoleID(d.Ctx, 9, []uint64{1})
+	assert.ErrorIs(t, err, database.ErrRecordNotFound)
+}
@@ -233,3 +233,19 @@ func Test_roleDao_UpdateByTx(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func Test_roleDao_WarmCache(t *testing.T) {
+	d := newRoleDao()
+	defer d.Close()
+
+	d.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(2, "editor").AddRow(1, "admin"))
+	n, err := d.IDao.(RoleDao).WarmCache(d.Ctx, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	record, err := d.Cache.ICache.(cache.RoleCache).Get(d.Ctx, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "editor", record.Name)
+}
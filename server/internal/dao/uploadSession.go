@@ -0,0 +1,57 @@
+package dao
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"admin/internal/model"
+)
+
+var _ UploadSessionDao = (*uploadSessionDao)(nil)
+
+// UploadSessionDao defining the dao interface
+type UploadSessionDao interface {
+	Create(ctx context.Context, table *model.UploadSession) error
+	GetByUploadID(ctx context.Context, uploadID string) (*model.UploadSession, error)
+	DeleteByUploadID(ctx context.Context, uploadID string) error
+	GetExpired(ctx context.Context, before time.Time, limit int) ([]*model.UploadSession, error)
+}
+
+// upload sessions are short-lived and written on every initiate, so they are not cached
+type uploadSessionDao struct {
+	db *gorm.DB
+}
+
+// NewUploadSessionDao creating the dao interface
+func NewUploadSessionDao(db *gorm.DB) UploadSessionDao {
+	return &uploadSessionDao{db: db}
+}
+
+// Create a record, insert the record and the id value is written back to the table
+func (d *uploadSessionDao) Create(ctx context.Context, table *model.UploadSession) error {
+	return d.db.WithContext(ctx).Create(table).Error
+}
+
+// GetByUploadID get a record by upload id
+func (d *uploadSessionDao) GetByUploadID(ctx context.Context, uploadID string) (*model.UploadSession, error) {
+	record := &model.UploadSession{}
+	err := d.db.WithContext(ctx).Where("upload_id = ?", uploadID).First(record).Error
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// DeleteByUploadID delete a record by upload id, the record is removed permanently
+func (d *uploadSessionDao) DeleteByUploadID(ctx context.Context, uploadID string) error {
+	return d.db.WithContext(ctx).Unscoped().Where("upload_id = ?", uploadID).Delete(&model.UploadSession{}).Error
+}
+
+// GetExpired get sessions that expired before the time
+func (d *uploadSessionDao) GetExpired(ctx context.Context, before time.Time, limit int) ([]*model.UploadSession, error) {
+	var records []*model.UploadSession
+	err := d.db.WithContext(ctx).Where("expired_at < ?", before).Order("id").Limit(limit).Find(&records).Error
+	return records, err
+}
@@ -17,6 +17,25 @@
 SET NAMES utf8mb4;
 SET FOREIGN_KEY_CHECKS = 0;
 
+-- ----------------------------
+-- Table structure for t_audit_log
+-- ----------------------------
+DROP TABLE IF EXISTS `t_audit_log`;
+CREATE TABLE `t_audit_log` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime NOT NULL COMMENT '操作时间',
+  `updated_at` datetime NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '删除时间',
+  `account_id` int NOT NULL DEFAULT '0' COMMENT '操作人',
+  `action` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '操作, 如 cache.evictKey',
+  `target` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '操作对象',
+  `detail` text COLLATE utf8mb4_unicode_ci COMMENT '结果或错误信息',
+  `ip` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' COMMENT '客户端IP',
+  PRIMARY KEY (`id`),
+  KEY `idx_action` (`action`),
+  KEY `idx_account_id` (`account_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='审计日志';
+
 -- ----------------------------
 -- Table structure for t_config
 -- ----------------------------
@@ -30,16 +49,107 @@ CREATE TABLE `t_config` (
   `description` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '描述',
   `key` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '配置键',
   `value` text COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '配置值',
-  PRIMARY KEY (`id`)
-) ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='系统配置';
+  `type` varchar(16) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'string' COMMENT '值类型, string int bool float json url enum secret',
+  `constraints` json DEFAULT NULL COMMENT '值约束',
+  `group` varchar(32) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' COMMENT '分组, 如 site, upload',
+  `public` tinyint NOT NULL DEFAULT '0' COMMENT '公开, 未登录也可以通过 /api/v1/config/public 获取',
+  PRIMARY KEY (`id`),
+  KEY `idx_group` (`group`)
+) ENGINE=InnoDB AUTO_INCREMENT=9 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='系统配置';
 
 -- ----------------------------
 -- Records of t_config
 -- ----------------------------
 BEGIN;
-INSERT INTO `t_config` (`id`, `created_at`, `updated_at`, `deleted_at`, `name`, `description`, `key`, `value`) VALUES (2, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, '图片域名', '图片域名', 'imageDomain', 'http://127.0.0.1:9501');
+INSERT INTO `t_config` (`id`, `created_at`, `updated_at`, `deleted_at`, `name`, `description`, `key`, `value`, `type`, `group`, `public`) VALUES (2, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, '图片域名', '图片域名', 'imageDomain', 'http://127.0.0.1:9501', 'url', 'site', 0);
+INSERT INTO `t_config` (`id`, `created_at`, `updated_at`, `deleted_at`, `name`, `description`, `key`, `value`, `type`, `group`, `public`) VALUES (3, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, '头像上传策略', '最大字节数及允许的MIME类型', 'uploadPolicy.avatar', '{\"maxSize\":2097152,\"allowTypes\":[\"image/png\",\"image/jpeg\",\"image/gif\",\"image/webp\"]}', 'json', 'upload', 0);
+INSERT INTO `t_config` (`id`, `created_at`, `updated_at`, `deleted_at`, `name`, `description`, `key`, `value`, `type`, `group`, `public`) VALUES (4, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, '附件上传策略', '最大字节数及允许的MIME类型, allowTypes为空表示不限制(脚本及可执行文件始终禁止)', 'uploadPolicy.attachment', '{\"maxSize\":20971520,\"allowTypes\":[]}', 'json', 'upload', 0);
+INSERT INTO `t_config` (`id`, `created_at`, `updated_at`, `deleted_at`, `name`, `description`, `key`, `value`, `type`, `group`, `public`) VALUES (5, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, '图片处理', '上传图片的缩略图、WebP、EXIF去除及水印, 变体通过 原图地址!变体后缀 访问, 如 a.jpg!small.jpg', 'imagePipeline', '{\"stripExif\":true,\"webp\":false,\"quality\":85,\"thumbnails\":[{\"name\":\"small\",\"width\":150,\"height\":150,\"crop\":true},{\"name\":\"medium\",\"width\":800,\"height\":800,\"crop\":false}]}', 'json', 'upload', 0);
+INSERT INTO `t_config` (`id`, `created_at`, `updated_at`, `deleted_at`, `name`, `description`, `key`, `value`, `type`, `group`, `public`) VALUES (6, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, '孤立文件回收', '定时回收超过宽限期(小时)且未被引用的文件, categories为回收的上传类别, dryRun为true时只生成报告, deleteUnregistered为true时才删除未登记在t_file中的文件', 'uploadGC', '{\"graceHours\":24,\"categories\":[\"avatar\"],\"dryRun\":true,\"deleteUnregistered\":false}', 'json', 'upload', 0);
+INSERT INTO `t_config` (`id`, `created_at`, `updated_at`, `deleted_at`, `name`, `description`, `key`, `value`, `type`, `group`, `public`) VALUES (7, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, '站点名称', '登录页及浏览器标题显示的名称', 'siteName', 'Admin', 'string', 'site', 1);
+INSERT INTO `t_config` (`id`, `created_at`, `updated_at`, `deleted_at`, `name`, `description`, `key`, `value`, `type`, `group`, `public`) VALUES (8, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, '站点Logo', '登录页及侧边栏显示的Logo地址', 'siteLogo', '', 'string', 'site', 1);
 COMMIT;
 
+-- ----------------------------
+-- Table structure for t_dict
+-- ----------------------------
+DROP TABLE IF EXISTS `t_dict`;
+CREATE TABLE `t_dict` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime NOT NULL COMMENT '创建时间',
+  `updated_at` datetime NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '删除时间',
+  `name` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '字典名称',
+  `code` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '字典编码, 不能与代码枚举重名',
+  `value_type` varchar(16) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'string' COMMENT '值类型, string int',
+  `description` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' COMMENT '描述',
+  `status` tinyint NOT NULL DEFAULT '1' COMMENT '状态',
+  PRIMARY KEY (`id`),
+  KEY `idx_code` (`code`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='数据字典';
+
+-- ----------------------------
+-- Table structure for t_dict_item
+-- ----------------------------
+DROP TABLE IF EXISTS `t_dict_item`;
+CREATE TABLE `t_dict_item` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime NOT NULL COMMENT '创建时间',
+  `updated_at` datetime NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '删除时间',
+  `dict_id` int NOT NULL DEFAULT '0' COMMENT '字典ID',
+  `label` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '标签',
+  `value` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '值',
+  `sort` int NOT NULL DEFAULT '1' COMMENT '排序',
+  `status` tinyint NOT NULL DEFAULT '1' COMMENT '状态',
+  `color` varchar(32) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' COMMENT '颜色',
+  `tag_type` varchar(16) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' COMMENT '标签类型, primary success info warning danger',
+  `labels` json DEFAULT NULL COMMENT '其他语言的标签',
+  PRIMARY KEY (`id`),
+  KEY `idx_dict_id` (`dict_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='数据字典项';
+
+-- ----------------------------
+-- Table structure for t_file
+-- ----------------------------
+DROP TABLE IF EXISTS `t_file`;
+CREATE TABLE `t_file` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime NOT NULL COMMENT '创建时间',
+  `updated_at` datetime NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '删除时间',
+  `uploader_id` int NOT NULL DEFAULT '0' COMMENT '上传人',
+  `name` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '原始文件名',
+  `size` bigint NOT NULL DEFAULT '0' COMMENT '文件大小(字节)',
+  `mime_type` varchar(128) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT 'MIME类型',
+  `hash` char(64) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT 'SHA-256',
+  `storage_key` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '存储key',
+  `variants` json DEFAULT NULL COMMENT '图片变体后缀',
+  `private` tinyint NOT NULL DEFAULT '0' COMMENT '私有文件, 只能通过签名链接下载',
+  `owner_id` int NOT NULL DEFAULT '0' COMMENT '私有文件的所有人, 公开文件为0',
+  `category` varchar(32) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' COMMENT '上传类别, avatar或attachment',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_hash_private_owner` (`hash`,`private`,`owner_id`),
+  KEY `idx_storage_key` (`storage_key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='文件';
+
+-- ----------------------------
+-- Table structure for t_file_ref
+-- ----------------------------
+DROP TABLE IF EXISTS `t_file_ref`;
+CREATE TABLE `t_file_ref` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime NOT NULL COMMENT '创建时间',
+  `updated_at` datetime NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '删除时间',
+  `file_id` int NOT NULL DEFAULT '0' COMMENT '文件ID',
+  `biz` varchar(64) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '业务字段',
+  `biz_id` int NOT NULL DEFAULT '0' COMMENT '业务记录ID',
+  PRIMARY KEY (`id`),
+  KEY `idx_file_id` (`file_id`),
+  KEY `idx_biz` (`biz`,`biz_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='文件引用';
+
 -- ----------------------------
 -- Table structure for t_menu
 -- ----------------------------
@@ -62,6 +172,7 @@ CREATE TABLE `t_menu` (
   `always_show` tinyint NOT NULL DEFAULT '0' COMMENT '始终显示',
   `keep_alive` tinyint NOT NULL DEFAULT '1' COMMENT '始终显示',
   `params` json DEFAULT NULL COMMENT '路由参数',
+  `titles` json DEFAULT NULL COMMENT '其他语言的菜单名称',
   PRIMARY KEY (`id`)
 ) ENGINE=InnoDB AUTO_INCREMENT=20 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='菜单管理';
 
@@ -69,25 +180,25 @@ CREATE TABLE `t_menu` (
 -- Records of t_menu
 -- ----------------------------
 BEGIN;
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (1, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 0, '系统管理', 'CATALOG', '/system', 'Layout', '', 1, 1, 'system', 'platform', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (2, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 1, '管理员管理', 'MENU', 'system/platform', 'system/platform/index', '', 1, 1, 'el-icon-User', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (3, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 2, '管理员新增', 'BUTTON', '', '', 'sys:platform:add', 1, 1, '', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (4, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 2, '管理员编辑', 'BUTTON', '', '', 'sys:platform:edit', 2, 1, '', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (5, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 2, '管理员删除', 'BUTTON', '', '', 'sys:platform:delete', 3, 1, '', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (6, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 1, '角色管理', 'MENU', 'system/role', 'system/role/index', '', 2, 1, 'role', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (7, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 6, '角色新增', 'BUTTON', '', '', 'sys:role:add', 1, 1, '', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (8, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 6, '角色编辑', 'BUTTON', '', '', 'sys:role:edit', 2, 1, '', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (9, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 6, '角色删除', 'BUTTON', '', '', 'sys:role:delete', 3, 1, '', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (10, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 6, '分配权限', 'BUTTON', '', '', 'sys:role:permission', 4, 1, '', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (11, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 1, '菜单管理', 'MENU', 'system/menu', 'system/menu/index', '', 3, 1, 'menu', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (12, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 11, '菜单新增', 'BUTTON', '', '', 'sys:menu:add', 1, 1, '', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (13, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 11, '菜单编辑', 'BUTTON', '', '', 'sys:menu:edit', 2, 1, '', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (14, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 11, '菜单删除', 'BUTTON', '', '', 'sys:menu:delete', 3, 1, '', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (15, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 1, '系统配置', 'MENU', 'system/config', 'system/config/index', '', 4, 1, 'setting', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (16, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 15, '配置新增', 'BUTTON', '', '', 'sys:config:add', 1, 1, '', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (17, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 15, '配置编辑', 'BUTTON', '', '', 'sys:config:edit', 2, 1, '', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (18, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 15, '配置删除', 'BUTTON', '', '', 'sys:config:delete', 3, 1, '', '', 0, 1, NULL);
-INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`) VALUES (19, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 2, '重置密码', 'BUTTON', '', '', 'sys:platform:password:reset', 4, 1, '', '', 0, 1, NULL);
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (1, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 0, '系统管理', 'CATALOG', '/system', 'Layout', '', 1, 1, 'system', 'platform', 0, 1, NULL, '{"en": "System"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (2, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 1, '管理员管理', 'MENU', 'system/platform', 'system/platform/index', '', 1, 1, 'el-icon-User', '', 0, 1, NULL, '{"en": "Administrators"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (3, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 2, '管理员新增', 'BUTTON', '', '', 'sys:platform:add', 1, 1, '', '', 0, 1, NULL, '{"en": "Add Administrator"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (4, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 2, '管理员编辑', 'BUTTON', '', '', 'sys:platform:edit', 2, 1, '', '', 0, 1, NULL, '{"en": "Edit Administrator"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (5, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 2, '管理员删除', 'BUTTON', '', '', 'sys:platform:delete', 3, 1, '', '', 0, 1, NULL, '{"en": "Delete Administrator"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (6, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 1, '角色管理', 'MENU', 'system/role', 'system/role/index', '', 2, 1, 'role', '', 0, 1, NULL, '{"en": "Roles"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (7, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 6, '角色新增', 'BUTTON', '', '', 'sys:role:add', 1, 1, '', '', 0, 1, NULL, '{"en": "Add Role"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (8, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 6, '角色编辑', 'BUTTON', '', '', 'sys:role:edit', 2, 1, '', '', 0, 1, NULL, '{"en": "Edit Role"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (9, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 6, '角色删除', 'BUTTON', '', '', 'sys:role:delete', 3, 1, '', '', 0, 1, NULL, '{"en": "Delete Role"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (10, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 6, '分配权限', 'BUTTON', '', '', 'sys:role:permission', 4, 1, '', '', 0, 1, NULL, '{"en": "Assign Permissions"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (11, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 1, '菜单管理', 'MENU', 'system/menu', 'system/menu/index', '', 3, 1, 'menu', '', 0, 1, NULL, '{"en": "Menus"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (12, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 11, '菜单新增', 'BUTTON', '', '', 'sys:menu:add', 1, 1, '', '', 0, 1, NULL, '{"en": "Add Menu"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (13, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 11, '菜单编辑', 'BUTTON', '', '', 'sys:menu:edit', 2, 1, '', '', 0, 1, NULL, '{"en": "Edit Menu"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (14, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 11, '菜单删除', 'BUTTON', '', '', 'sys:menu:delete', 3, 1, '', '', 0, 1, NULL, '{"en": "Delete Menu"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (15, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 1, '系统配置', 'MENU', 'system/config', 'system/config/index', '', 4, 1, 'setting', '', 0, 1, NULL, '{"en": "Configs"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (16, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 15, '配置新增', 'BUTTON', '', '', 'sys:config:add', 1, 1, '', '', 0, 1, NULL, '{"en": "Add Config"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (17, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 15, '配置编辑', 'BUTTON', '', '', 'sys:config:edit', 2, 1, '', '', 0, 1, NULL, '{"en": "Edit Config"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (18, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 15, '配置删除', 'BUTTON', '', '', 'sys:config:delete', 3, 1, '', '', 0, 1, NULL, '{"en": "Delete Config"}');
+INSERT INTO `t_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `parent_id`, `name`, `type`, `path`, `component`, `perm`, `sort`, `visible`, `icon`, `redirect`, `always_show`, `keep_alive`, `params`, `titles`) VALUES (19, '2024-11-09 23:56:51', '2024-11-09 23:56:51', NULL, 2, '重置密码', 'BUTTON', '', '', 'sys:platform:password:reset', 4, 1, '', '', 0, 1, NULL, '{"en": "Reset Password"}');
 COMMIT;
 
 -- ----------------------------
@@ -127,6 +238,7 @@ CREATE TABLE `t_platform` (
   `role_id` json NOT NULL COMMENT '角色',
   `status` tinyint NOT NULL COMMENT '状态',
   `last_time` datetime DEFAULT NULL COMMENT '上次登录时间',
+  `locale` varchar(16) COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT '' COMMENT '语言偏好',
   PRIMARY KEY (`id`)
 ) ENGINE=InnoDB AUTO_INCREMENT=2 DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='管理员';
 
@@ -216,4 +328,28 @@ INSERT INTO `t_role_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `role
 INSERT INTO `t_role_menu` (`id`, `created_at`, `updated_at`, `deleted_at`, `role_id`, `menu_id`) VALUES (37, '2024-11-10 00:51:27', '2024-11-10 00:51:27', NULL, 1, 18);
 COMMIT;
 
+-- ----------------------------
+-- Table structure for t_upload_session
+-- ----------------------------
+DROP TABLE IF EXISTS `t_upload_session`;
+CREATE TABLE `t_upload_session` (
+  `id` int unsigned NOT NULL AUTO_INCREMENT,
+  `created_at` datetime NOT NULL COMMENT '创建时间',
+  `updated_at` datetime NOT NULL COMMENT '更新时间',
+  `deleted_at` datetime DEFAULT NULL COMMENT '删除时间',
+  `upload_id` varchar(32) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '上传ID',
+  `uploader_id` int NOT NULL DEFAULT '0' COMMENT '上传人',
+  `category` varchar(32) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '上传类别',
+  `name` varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT '原始文件名',
+  `size` bigint NOT NULL DEFAULT '0' COMMENT '文件大小(字节)',
+  `hash` char(64) COLLATE utf8mb4_unicode_ci NOT NULL COMMENT 'SHA-256',
+  `chunk_size` bigint NOT NULL DEFAULT '0' COMMENT '分片大小(字节)',
+  `chunk_count` int NOT NULL DEFAULT '0' COMMENT '分片数量',
+  `expired_at` datetime NOT NULL COMMENT '过期时间',
+  `private` tinyint NOT NULL DEFAULT '0' COMMENT '私有文件',
+  PRIMARY KEY (`id`),
+  UNIQUE KEY `uk_upload_id` (`upload_id`),
+  KEY `idx_expired_at` (`expired_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='分片上传会话';
+
 SET FOREIGN_KEY_CHECKS = 1;
@@ -1,8 +1,12 @@
 package database
 
 import (
+	"errors"
 	"time"
 
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+
 	"github.com/go-dev-frame/sponge/pkg/logger"
 	"github.com/go-dev-frame/sponge/pkg/sgorm"
 	"github.com/go-dev-frame/sponge/pkg/sgorm/mysql"
@@ -46,3 +50,12 @@ func InitMysql() *sgorm.DB {
 	}
 	return db
 }
+
+// IsDuplicateKey whether the error is a violation of a unique index
+func IsDuplicateKey(err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	var mysqlErr *mysqldriver.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062
+}
@@ -25,8 +25,8 @@ var (
 
 // CacheType cache type
 type CacheType struct {
-	CType string          // cache type  memory or redis
-	Rdb   *goredis.Client // if CType=redis, Rdb cannot be empty
+	CType string          // cache type  memory, redis or tiered
+	Rdb   *goredis.Client // if CType=redis or tiered, Rdb cannot be empty
 }
 
 // InitCache initial cache
@@ -35,7 +35,7 @@ func InitCache(cType string) {
 		CType: cType,
 	}
 
-	if cType == "redis" {
+	if cType == "redis" || cType == "tiered" {
 		cacheType.Rdb = GetRedisCli()
 	}
 }
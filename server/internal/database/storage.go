@@ -0,0 +1,96 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"admin/internal/config"
+	"admin/internal/pkg/signurl"
+	"admin/internal/pkg/storage"
+)
+
+const (
+	// SignKeyEnv environment variable of the sign key, it takes precedence over storage.signKey so the key
+	// can be kept out of the config file, e.g. in a kubernetes Secret
+	SignKeyEnv = "STORAGE_SIGN_KEY"
+	// MinSignKeyLength the minimum length of the sign key in bytes
+	MinSignKeyLength = 32
+)
+
+var (
+	storageCli     storage.Storage
+	storageCliOnce sync.Once
+
+	urlSigner     *signurl.Signer
+	urlSignerOnce sync.Once
+)
+
+// InitStorage initial storage of uploaded files
+func InitStorage() {
+	storageCfg := config.Get().Storage
+	switch storageCfg.Type {
+	case "s3":
+		var err error
+		storageCli, err = storage.NewS3(storage.S3Options{
+			Endpoint:  storageCfg.S3.Endpoint,
+			Region:    storageCfg.S3.Region,
+			Bucket:    storageCfg.S3.Bucket,
+			AccessKey: storageCfg.S3.AccessKey,
+			SecretKey: storageCfg.S3.SecretKey,
+			PathStyle: storageCfg.S3.PathStyle,
+			Prefix:    storageCfg.S3.Prefix,
+		})
+		if err != nil {
+			panic("storage.NewS3 error: " + err.Error())
+		}
+	default:
+		storageCli = storage.NewLocal(storageCfg.Local.Root)
+	}
+}
+
+// GetStorage get storage of uploaded files
+func GetStorage() storage.Storage {
+	if storageCli == nil {
+		storageCliOnce.Do(func() {
+			InitStorage()
+		})
+	}
+
+	return storageCli
+}
+
+// SetStorage replace storage, used by tests
+func SetStorage(s storage.Storage) {
+	storageCli = s
+}
+
+// GetURLSigner get the signer of the download urls of private files
+func GetURLSigner() *signurl.Signer {
+	if urlSigner == nil {
+		urlSignerOnce.Do(func() {
+			signKey, err := GetSignKey()
+			if err != nil {
+				panic(err.Error())
+			}
+			urlSigner = signurl.New(signKey)
+		})
+	}
+
+	return urlSigner
+}
+
+// GetSignKey get the sign key from SignKeyEnv or storage.signKey, the key must be at least MinSignKeyLength bytes
+func GetSignKey() (string, error) {
+	signKey := os.Getenv(SignKeyEnv)
+	if signKey == "" {
+		signKey = config.Get().Storage.SignKey
+	}
+	if signKey == "" {
+		return "", fmt.Errorf("storage.signKey is not configured, set it or the environment variable %s", SignKeyEnv)
+	}
+	if len(signKey) < MinSignKeyLength {
+		return "", fmt.Errorf("storage.signKey is too short, it must be at least %d bytes", MinSignKeyLength)
+	}
+	return signKey, nil
+}
@@ -0,0 +1,20 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// cache business-level http error codes.
+// the cacheNO value range is 1~100, if the same error code is used, it will cause panic.
+var (
+	cacheNO       = 70
+	cacheName     = "cache"
+	cacheBaseCode = errcode.HCode(cacheNO)
+
+	ErrCacheKeyNamespace = errcode.NewError(cacheBaseCode+1, "缓存key不属于该命名空间")
+	ErrGetCache          = errcode.NewError(cacheBaseCode+2, "failed to get "+cacheName)
+	ErrEvictCache        = errcode.NewError(cacheBaseCode+3, "failed to evict "+cacheName)
+	ErrWarmCache         = errcode.NewError(cacheBaseCode+4, "failed to warm "+cacheName)
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -11,11 +11,12 @@ var (
 	configName     = "config"
 	configBaseCode = errcode.HCode(configNO)
 
-	ErrCreateConfig     = errcode.NewError(configBaseCode+1, "failed to create "+configName)
-	ErrDeleteByIDConfig = errcode.NewError(configBaseCode+2, "failed to delete "+configName)
-	ErrUpdateByIDConfig = errcode.NewError(configBaseCode+3, "failed to update "+configName)
-	ErrGetByIDConfig    = errcode.NewError(configBaseCode+4, "failed to get "+configName+" details")
-	ErrListConfig       = errcode.NewError(configBaseCode+5, "failed to list of "+configName)
+	ErrCreateConfig       = errcode.NewError(configBaseCode+1, "failed to create "+configName)
+	ErrDeleteByIDConfig   = errcode.NewError(configBaseCode+2, "failed to delete "+configName)
+	ErrUpdateByIDConfig   = errcode.NewError(configBaseCode+3, "failed to update "+configName)
+	ErrGetByIDConfig      = errcode.NewError(configBaseCode+4, "failed to get "+configName+" details")
+	ErrListConfig         = errcode.NewError(configBaseCode+5, "failed to list of "+configName)
+	ErrInvalidConfigValue = errcode.NewError(configBaseCode+6, "配置值不符合类型或约束")
 
 	// error codes are globally unique, adding 1 to the previous error code
 )
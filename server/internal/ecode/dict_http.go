@@ -0,0 +1,27 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// dict business-level http error codes.
+// the dictNO value range is 1~100, if the same error code is used, it will cause panic.
+var (
+	dictNO       = 72
+	dictName     = "dict"
+	dictBaseCode = errcode.HCode(dictNO)
+
+	ErrCreateDict         = errcode.NewError(dictBaseCode+1, "failed to create "+dictName)
+	ErrDeleteByIDDict     = errcode.NewError(dictBaseCode+2, "failed to delete "+dictName)
+	ErrUpdateByIDDict     = errcode.NewError(dictBaseCode+3, "failed to update "+dictName)
+	ErrGetByIDDict        = errcode.NewError(dictBaseCode+4, "failed to get "+dictName+" details")
+	ErrListDict           = errcode.NewError(dictBaseCode+5, "failed to list of "+dictName)
+	ErrDictCodeReadOnly   = errcode.NewError(dictBaseCode+6, "字典编码与代码枚举重名, 代码枚举只读")
+	ErrDictCodeExists     = errcode.NewError(dictBaseCode+7, "字典编码已存在")
+	ErrDictItemValue      = errcode.NewError(dictBaseCode+8, "字典项的值不是整数")
+	ErrCreateDictItem     = errcode.NewError(dictBaseCode+9, "failed to create dict item")
+	ErrUpdateByIDDictItem = errcode.NewError(dictBaseCode+10, "failed to update dict item")
+	ErrListDictItem       = errcode.NewError(dictBaseCode+11, "failed to list of dict item")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,29 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// file business-level http error codes.
+// the fileNO value range is 1~100, if the same error code is used, it will cause panic.
+var (
+	fileNO       = 60
+	fileName     = "file"
+	fileBaseCode = errcode.HCode(fileNO)
+
+	ErrDeleteByIDFile       = errcode.NewError(fileBaseCode+1, "failed to delete "+fileName)
+	ErrGetByIDFile          = errcode.NewError(fileBaseCode+2, "failed to get "+fileName+" details")
+	ErrListFile             = errcode.NewError(fileBaseCode+3, "failed to list of "+fileName)
+	ErrUploadFile           = errcode.NewError(fileBaseCode+4, "failed to upload "+fileName)
+	ErrFileInUse            = errcode.NewError(fileBaseCode+5, "文件正在使用中，无法删除")
+	ErrUploadTooLarge       = errcode.NewError(fileBaseCode+6, "文件大小超出限制")
+	ErrUploadTypeNotAllowed = errcode.NewError(fileBaseCode+7, "不允许上传该类型的文件")
+	ErrUploadSessionExpired = errcode.NewError(fileBaseCode+8, "上传会话不存在或已过期")
+	ErrUploadChunk          = errcode.NewError(fileBaseCode+9, "分片序号或大小错误")
+	ErrUploadIncomplete     = errcode.NewError(fileBaseCode+10, "分片未全部上传")
+	ErrUploadHashMismatch   = errcode.NewError(fileBaseCode+11, "文件校验失败，哈希不一致")
+	ErrFileGCRunning        = errcode.NewError(fileBaseCode+12, "孤立文件回收正在运行，请稍后再试")
+	ErrFileGC               = errcode.NewError(fileBaseCode+13, "failed to collect orphaned "+fileName)
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,145 @@
+package ecode
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+
+	"admin/internal/pkg/i18n"
+)
+
+var (
+	// messages of the error codes by locale, the message passed to NewError is used if a locale is missing
+	i18nMessages = map[int]map[string]string{}
+	// the messages passed to NewError, the details appended by WithDetails follow them
+	sourceMessages = map[int]string{}
+)
+
+func init() {
+	// system level, response.Output replies the http status code with the same messages
+	addI18n(InvalidParams, "参数错误", "")
+	addI18n(Unauthorized, "未登录或登录已过期", "")
+	addI18n(InternalServerError, "服务器内部错误", "")
+	addI18n(NotFound, "资源不存在", "")
+	addI18n(Timeout, "请求超时", "")
+	addI18n(TooManyRequests, "请求过于频繁", "")
+	addI18n(Forbidden, "禁止访问", "")
+	addI18n(LimitExceed, "请求过于频繁", "")
+	addI18n(AccessDenied, "拒绝访问", "")
+	addI18n(PermissionDenied, "没有权限", "")
+	addI18n(ServiceUnavailable, "服务不可用", "")
+	addI18n(Conflict, "数据冲突", "")
+	for status, e := range map[int]*errcode.Error{
+		http.StatusBadRequest:          InvalidParams,
+		http.StatusUnauthorized:        Unauthorized,
+		http.StatusForbidden:           Forbidden,
+		http.StatusNotFound:            NotFound,
+		http.StatusRequestTimeout:      Timeout,
+		http.StatusConflict:            Conflict,
+		http.StatusInternalServerError: InternalServerError,
+		http.StatusTooManyRequests:     LimitExceed,
+		http.StatusServiceUnavailable:  ServiceUnavailable,
+	} {
+		i18nMessages[status] = i18nMessages[e.Code()]
+		sourceMessages[status] = e.Msg()
+	}
+
+	// business level, the generated crud codes only need the chinese messages
+	addCrudI18n(platformBaseCode, "管理员")
+	addCrudI18n(configBaseCode, "配置")
+	addCrudI18n(dictBaseCode, "字典")
+	addCrudI18n(menuBaseCode, "菜单")
+	addCrudI18n(roleMenuBaseCode, "角色菜单")
+	addCrudI18n(roleBaseCode, "角色")
+
+	addI18n(ErrLoginCaptcha, "", "invalid captcha")
+	addI18n(ErrLogin, "", "incorrect username or password")
+	addI18n(ErrLoginFrozen, "", "the account is frozen, please contact the administrator")
+	addI18n(ErrPassword, "", "incorrect old password")
+
+	addI18n(ErrMoveMenu, "移动菜单失败", "")
+	addI18n(ErrMenuMoveCycle, "", "a menu can not be moved under itself or its descendants")
+	addI18n(ErrExportMenu, "导出菜单失败", "")
+	addI18n(ErrImportMenu, "导入菜单失败", "")
+	addI18n(ErrMenuManifest, "", "invalid menu manifest")
+	addI18n(ErrMenuDependents, "", "the menu has descendants or is assigned to roles")
+	addI18n(ErrMenuPermExists, "", "the perm already exists")
+	addI18n(ErrMenuParentType, "", "a menu can only be moved under a catalog or a menu")
+
+	addI18n(ErrInvalidConfigValue, "", "the config value does not match its type or constraints")
+
+	addI18n(ErrDictCodeReadOnly, "", "the dict code is used by a code enum, code enums are read only")
+	addI18n(ErrDictCodeExists, "", "the dict code already exists")
+	addI18n(ErrDictItemValue, "", "the value of the dict item is not an integer")
+	addI18n(ErrCreateDictItem, "创建字典项失败", "")
+	addI18n(ErrUpdateByIDDictItem, "更新字典项失败", "")
+	addI18n(ErrListDictItem, "获取字典项列表失败", "")
+
+	addI18n(ErrDeleteByIDFile, "删除文件失败", "")
+	addI18n(ErrGetByIDFile, "获取文件详情失败", "")
+	addI18n(ErrListFile, "获取文件列表失败", "")
+	addI18n(ErrUploadFile, "上传文件失败", "")
+	addI18n(ErrFileInUse, "", "the file is in use and can not be deleted")
+	addI18n(ErrUploadTooLarge, "", "the file is too large")
+	addI18n(ErrUploadTypeNotAllowed, "", "the file type is not allowed")
+	addI18n(ErrUploadSessionExpired, "", "the upload session does not exist or has expired")
+	addI18n(ErrUploadChunk, "", "invalid chunk index or size")
+	addI18n(ErrUploadIncomplete, "", "not all chunks are uploaded")
+	addI18n(ErrUploadHashMismatch, "", "the file hash does not match")
+	addI18n(ErrFileGCRunning, "", "orphaned files are being collected, please try again later")
+	addI18n(ErrFileGC, "回收孤立文件失败", "")
+
+	addI18n(ErrCacheKeyNamespace, "", "the cache key does not belong to the namespace")
+	addI18n(ErrGetCache, "获取缓存失败", "")
+	addI18n(ErrEvictCache, "清除缓存失败", "")
+	addI18n(ErrWarmCache, "预热缓存失败", "")
+
+	addI18n(ErrRoutePermReport, "获取路由权限失败", "")
+	addI18n(ErrExplainPermission, "解释权限失败", "")
+
+	for _, info := range errcode.ListHTTPErrCodes() {
+		sourceMessages[info.Code] = info.Msg
+	}
+}
+
+// addI18n add the chinese and english messages of an error code, an empty message is skipped
+func addI18n(e *errcode.Error, zh string, en string) {
+	msgs, ok := i18nMessages[e.Code()]
+	if !ok {
+		msgs = map[string]string{}
+		i18nMessages[e.Code()] = msgs
+	}
+	if zh != "" {
+		msgs[i18n.ZhCN] = zh
+	}
+	if en != "" {
+		msgs[i18n.En] = en
+	}
+}
+
+// addCrudI18n the chinese messages of the five codes generated for a table: create, delete, update, get and list
+func addCrudI18n(baseCode int, name string) {
+	for i, format := range []string{"创建%s失败", "删除%s失败", "更新%s失败", "获取%s详情失败", "获取%s列表失败"} {
+		i18nMessages[baseCode+i+1] = map[string]string{i18n.ZhCN: fmt.Sprintf(format, name)}
+	}
+}
+
+// Localize the message of the error code in the locale, msg is the message replied by the handler,
+// the details appended by WithDetails are kept. msg is returned unchanged if there is no translation
+// or the handler rewrote it
+func Localize(code int, msg string, locale string) string {
+	text, ok := i18nMessages[code][locale]
+	if !ok {
+		return msg
+	}
+	source := sourceMessages[code]
+	if msg == source {
+		return text
+	}
+	if details, found := strings.CutPrefix(msg, source+", "); found {
+		return text + ", " + details
+	}
+	return msg
+}
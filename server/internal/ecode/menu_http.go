@@ -16,6 +16,14 @@ var (
 	ErrUpdateByIDMenu = errcode.NewError(menuBaseCode+3, "failed to update "+menuName)
 	ErrGetByIDMenu    = errcode.NewError(menuBaseCode+4, "failed to get "+menuName+" details")
 	ErrListMenu       = errcode.NewError(menuBaseCode+5, "failed to list of "+menuName)
+	ErrMoveMenu       = errcode.NewError(menuBaseCode+6, "failed to move "+menuName)
+	ErrMenuMoveCycle  = errcode.NewError(menuBaseCode+7, "不能移动到自身或其子菜单下")
+	ErrExportMenu     = errcode.NewError(menuBaseCode+8, "failed to export "+menuName)
+	ErrImportMenu     = errcode.NewError(menuBaseCode+9, "failed to import "+menuName)
+	ErrMenuManifest   = errcode.NewError(menuBaseCode+10, "菜单清单格式错误")
+	ErrMenuDependents = errcode.NewError(menuBaseCode+11, "菜单有子菜单或已分配给角色")
+	ErrMenuPermExists = errcode.NewError(menuBaseCode+12, "权限标识已存在")
+	ErrMenuParentType = errcode.NewError(menuBaseCode+13, "只能移动到目录或菜单下")
 
 	// error codes are globally unique, adding 1 to the previous error code
 )
@@ -0,0 +1,18 @@
+package ecode
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/errcode"
+)
+
+// permission business-level http error codes.
+// the permissionNO value range is 1~100, if the same error code is used, it will cause panic.
+var (
+	permissionNO       = 88
+	permissionName     = "permission"
+	permissionBaseCode = errcode.HCode(permissionNO)
+
+	ErrRoutePermReport   = errcode.NewError(permissionBaseCode+1, "failed to get the route "+permissionName+"s")
+	ErrExplainPermission = errcode.NewError(permissionBaseCode+2, "failed to explain the "+permissionName+"s")
+
+	// error codes are globally unique, adding 1 to the previous error code
+)
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"admin/internal/dao"
+	"admin/internal/database"
+	"admin/internal/ecode"
+	"admin/internal/model"
+	"admin/internal/types"
+)
+
+var _ AuditLogHandler = (*auditLogHandler)(nil)
+
+// AuditLogHandler defining the handler interface
+type AuditLogHandler interface {
+	List(c *gin.Context)
+}
+
+type auditLogHandler struct {
+	iDao dao.AuditLogDao
+}
+
+// NewAuditLogHandler creating the handler interface
+func NewAuditLogHandler() AuditLogHandler {
+	return &auditLogHandler{
+		iDao: dao.NewAuditLogDao(database.GetDB()),
+	}
+}
+
+// List of records by query parameters
+// @Summary list of audit logs by query parameters
+// @Description list of audit logs by paging and conditions, the newest first
+// @Tags auditLog
+// @accept json
+// @Produce json
+// @Param request query types.ListAuditLogsRequest true "query parameters"
+// @Success 200 {object} types.ListAuditLogsReply{}
+// @Router /api/v1/auditLog [get]
+// @Security BearerAuth
+func (h *auditLogHandler) List(c *gin.Context) {
+	request := &types.ListAuditLogsRequest{}
+	err := c.ShouldBindQuery(request)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	records, total, err := h.iDao.GetByParams(ctx, request)
+	if err != nil {
+		logger.Error("GetByParams error", logger.Err(err), logger.Any("request", request), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	data := []*types.AuditLogObjDetail{}
+	err = copier.Copy(&data, records)
+	if err != nil {
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  data,
+		"total": total,
+	})
+}
+
+// writeAudit record an operation of the current account, err is recorded as the detail if it is not nil,
+// a failure of writing is only logged
+func writeAudit(c *gin.Context, iDao dao.AuditLogDao, action string, target string, detail string, err error) {
+	if err != nil {
+		detail = "error: " + err.Error()
+	}
+	record := &model.AuditLog{
+		AccountID: c.GetUint64("id"),
+		Action:    action,
+		Target:    target,
+		Detail:    detail,
+		IP:        c.ClientIP(),
+	}
+	if err := iDao.Create(middleware.WrapCtx(c), record); err != nil {
+		logger.Error("write audit log error", logger.Err(err), logger.Any("audit", record), middleware.GCtxRequestIDField(c))
+		return
+	}
+	logger.Info("audit", logger.String("action", action), logger.String("target", target), logger.Any("accountID", record.AccountID),
+		logger.String("detail", detail), middleware.GCtxRequestIDField(c))
+}
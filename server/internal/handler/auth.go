@@ -5,6 +5,7 @@ import (
 	"admin/internal/dao"
 	"admin/internal/database"
 	"admin/internal/ecode"
+	"admin/internal/middlewares"
 	"admin/internal/model"
 	"admin/internal/types"
 	"context"
@@ -100,6 +101,8 @@ func (a authHandler) Login(c *gin.Context) {
 		response.Error(c, ecode.ErrLogin)
 		return
 	}
+	// browsers send the cookie, not the Authorization header, when opening a download link bound to the account
+	middlewares.SetDownloadToken(c, token)
 
 	response.Success(c, a.loginReply(token))
 }
@@ -122,6 +125,7 @@ func (a authHandler) loginReply(token string) types.LoginItem {
 // @Router /api/v1/auth/logout [delete]
 // @Security BearerAuth
 func (a authHandler) Logout(c *gin.Context) {
+	middlewares.ClearDownloadToken(c)
 	response.Success(c)
 }
 
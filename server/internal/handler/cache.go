@@ -0,0 +1,336 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+
+	"admin/internal/cache"
+	"admin/internal/dao"
+	"admin/internal/database"
+	"admin/internal/ecode"
+	"admin/internal/types"
+)
+
+// records loaded by warming a namespace
+const cacheWarmLimit = 1000
+
+// audit actions of the cache administration
+const (
+	auditCacheList           = "cache.list"
+	auditCacheView           = "cache.view"
+	auditCacheEvictKey       = "cache.evictKey"
+	auditCacheEvictNamespace = "cache.evictNamespace"
+	auditCacheWarm           = "cache.warm"
+)
+
+var _ CacheHandler = (*cacheHandler)(nil)
+
+// CacheHandler defining the handler interface
+type CacheHandler interface {
+	ListNamespaces(c *gin.Context)
+	GetNamespace(c *gin.Context)
+	GetKey(c *gin.Context)
+	EvictKey(c *gin.Context)
+	EvictNamespace(c *gin.Context)
+	Warm(c *gin.Context)
+}
+
+type cacheHandler struct {
+	cacheType *database.CacheType
+	iAuditDao dao.AuditLogDao
+	warmers   map[string]func(ctx context.Context) (int, error)
+}
+
+// NewCacheHandler creating the handler interface
+func NewCacheHandler() CacheHandler {
+	db, cacheType := database.GetDB(), database.GetCacheType()
+	return &cacheHandler{
+		cacheType: cacheType,
+		iAuditDao: dao.NewAuditLogDao(db),
+		warmers:   newCacheWarmers(db, cacheType),
+	}
+}
+
+func newCacheWarmers(db *sgorm.DB, cacheType *database.CacheType) map[string]func(ctx context.Context) (int, error) {
+	return map[string]func(ctx context.Context) (int, error){
+		cache.NamespacePlatform: func(ctx context.Context) (int, error) {
+			return dao.NewPlatformDao(db, cache.NewPlatformCache(cacheType)).WarmCache(ctx, cacheWarmLimit)
+		},
+		cache.NamespaceRole: func(ctx context.Context) (int, error) {
+			return dao.NewRoleDao(db, cache.NewRoleCache(cacheType)).WarmCache(ctx, cacheWarmLimit)
+		},
+		cache.NamespaceMenu: func(ctx context.Context) (int, error) {
+			return dao.NewMenuDao(db, cache.NewMenuCache(cacheType)).WarmCache(ctx, cacheWarmLimit)
+		},
+		// the menus of all the roles, the trees of the role sets are loaded on demand
+		cache.NamespaceMenuTree: func(ctx context.Context) (int, error) {
+			_, err := dao.NewMenuDao(db, cache.NewMenuCache(cacheType)).Options(ctx, &types.OptionMenusRequest{})
+			if err != nil {
+				return 0, err
+			}
+			return 1, nil
+		},
+		cache.NamespaceRoleMenu: func(ctx context.Context) (int, error) {
+			return dao.NewRoleMenuDao(db, cache.NewRoleMenuCache(cacheType)).WarmCache(ctx, cacheWarmLimit)
+		},
+		cache.NamespaceConfig: func(ctx context.Context) (int, error) {
+			return dao.NewConfigDao(db, cache.NewConfigCache(cacheType)).WarmCache(ctx, cacheWarmLimit)
+		},
+		// enums are generated from the code, warming reloads them
+		cache.NamespaceEnum: func(ctx context.Context) (int, error) {
+			return len(cache.NewEnumCache().GetAll(ctx)), nil
+		},
+	}
+}
+
+// ListNamespaces list the cache namespaces and their key counts
+// @Summary list cache namespaces
+// @Description list the cache namespaces with their key counts, the keys of the memory cache can not be counted
+// @Tags cache
+// @Produce json
+// @Success 200 {object} types.ListCacheNamespacesReply{}
+// @Router /api/v1/cache [get]
+// @Security BearerAuth
+func (h *cacheHandler) ListNamespaces(c *gin.Context) {
+	ctx := middleware.WrapCtx(c)
+	list := []*types.CacheNamespace{}
+	for _, ns := range cache.Namespaces {
+		data, _, err := h.inspect(ctx, ns, 0)
+		if err != nil {
+			logger.Error("inspect cache namespace error", logger.Err(err), logger.String("namespace", ns.Name), middleware.GCtxRequestIDField(c))
+			writeAudit(c, h.iAuditDao, auditCacheList, "", "", err)
+			response.Error(c, ecode.ErrGetCache)
+			return
+		}
+		list = append(list, data)
+	}
+	writeAudit(c, h.iAuditDao, auditCacheList, "", "", nil)
+
+	response.Success(c, list)
+}
+
+// GetNamespace get the key count and sample entries of a namespace
+// @Summary get a cache namespace
+// @Description get the key count and sample entries of a cache namespace, the passwords and secret configs are masked
+// @Tags cache
+// @Produce json
+// @Param namespace path string true "namespace, platform role menu roleMenu config or enum"
+// @Param request query types.GetCacheNamespaceRequest true "query parameters"
+// @Success 200 {object} types.GetCacheNamespaceReply{}
+// @Router /api/v1/cache/{namespace} [get]
+// @Security BearerAuth
+func (h *cacheHandler) GetNamespace(c *gin.Context) {
+	ns, ok := getCacheNamespaceFromPath(c)
+	if !ok {
+		return
+	}
+	request := &types.GetCacheNamespaceRequest{}
+	err := c.ShouldBindQuery(request)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if request.Limit == 0 {
+		request.Limit = 10
+	}
+
+	ctx := middleware.WrapCtx(c)
+	data, samples, err := h.inspect(ctx, ns, request.Limit)
+	writeAudit(c, h.iAuditDao, auditCacheView, ns.Prefix, "", err)
+	if err != nil {
+		logger.Error("inspect cache namespace error", logger.Err(err), logger.String("namespace", ns.Name), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrGetCache)
+		return
+	}
+	if samples == nil {
+		samples = []*types.CacheEntry{}
+	}
+
+	response.Success(c, &types.CacheNamespaceDetail{CacheNamespace: *data, Samples: samples})
+}
+
+// GetKey get a key of a namespace
+// @Summary get a cache key
+// @Description get the value and ttl of a cache key, the passwords and secret configs are masked
+// @Tags cache
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Param request query types.EvictCacheKeyRequest true "query parameters"
+// @Success 200 {object} types.CacheEntry{}
+// @Router /api/v1/cache/{namespace}/key [get]
+// @Security BearerAuth
+func (h *cacheHandler) GetKey(c *gin.Context) {
+	ns, key, ok := h.getKeyFromRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	entry, err := cache.NewKeyInspector(h.cacheType, ns).Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, database.ErrCacheNotFound) {
+			writeAudit(c, h.iAuditDao, auditCacheView, key, "not found", nil)
+			response.Error(c, ecode.NotFound)
+			return
+		}
+		writeAudit(c, h.iAuditDao, auditCacheView, key, "", err)
+		logger.Error("get cache key error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrGetCache)
+		return
+	}
+	writeAudit(c, h.iAuditDao, auditCacheView, key, "", nil)
+
+	response.Success(c, entry)
+}
+
+// EvictKey evict a key of a namespace
+// @Summary evict a cache key
+// @Description delete a cache key, the other instances evict it from their memory cache too
+// @Tags cache
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Param request query types.EvictCacheKeyRequest true "query parameters"
+// @Success 200 {object} types.EvictCacheReplyDoc{}
+// @Router /api/v1/cache/{namespace}/key [delete]
+// @Security BearerAuth
+func (h *cacheHandler) EvictKey(c *gin.Context) {
+	ns, key, ok := h.getKeyFromRequest(c)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := cache.NewKeyInspector(h.cacheType, ns).Del(ctx, key)
+	writeAudit(c, h.iAuditDao, auditCacheEvictKey, key, "", err)
+	if err != nil {
+		logger.Error("evict cache key error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrEvictCache)
+		return
+	}
+
+	response.Success(c, &types.EvictCacheReply{Deleted: 1})
+}
+
+// EvictNamespace evict all keys of a namespace
+// @Summary evict a cache namespace
+// @Description delete all keys of a namespace, the memory cache can not be listed so it is cleared completely, enums are reloaded after eviction
+// @Tags cache
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Success 200 {object} types.EvictCacheReplyDoc{}
+// @Router /api/v1/cache/{namespace} [delete]
+// @Security BearerAuth
+func (h *cacheHandler) EvictNamespace(c *gin.Context) {
+	ns, ok := getCacheNamespaceFromPath(c)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	iInspector := cache.NewKeyInspector(h.cacheType, ns)
+	var deleted int64
+	var err error
+	if ns.Name == cache.NamespaceEnum {
+		// only the enum keys, clearing the memory cache would drop the memory cache of the other namespaces
+		keys := []string{}
+		for name := range cache.NewEnumCache().GetAll(ctx) {
+			keys = append(keys, ns.Prefix+name)
+		}
+		deleted = int64(len(keys))
+		err = iInspector.Del(ctx, keys...)
+	} else {
+		deleted, err = iInspector.DelPrefix(ctx, ns.Prefix)
+	}
+	if err == nil && (ns.Name == cache.NamespaceEnum || !iInspector.Listable()) {
+		// enums are only loaded at startup, reload them after they are evicted
+		_, err = h.warmers[cache.NamespaceEnum](ctx)
+	}
+	writeAudit(c, h.iAuditDao, auditCacheEvictNamespace, ns.Prefix, fmt.Sprintf("deleted %d", deleted), err)
+	if err != nil {
+		logger.Error("evict cache namespace error", logger.Err(err), logger.String("namespace", ns.Name), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrEvictCache)
+		return
+	}
+
+	response.Success(c, &types.EvictCacheReply{Deleted: deleted})
+}
+
+// Warm load the records of a namespace from the database into the cache
+// @Summary warm a cache namespace
+// @Description load the latest 1000 records of a namespace from the database into the cache, enums are reloaded from the code
+// @Tags cache
+// @Produce json
+// @Param namespace path string true "namespace"
+// @Success 200 {object} types.WarmCacheReplyDoc{}
+// @Router /api/v1/cache/{namespace}/warm [post]
+// @Security BearerAuth
+func (h *cacheHandler) Warm(c *gin.Context) {
+	ns, ok := getCacheNamespaceFromPath(c)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	loaded, err := h.warmers[ns.Name](ctx)
+	writeAudit(c, h.iAuditDao, auditCacheWarm, ns.Prefix, fmt.Sprintf("loaded %d", loaded), err)
+	if err != nil {
+		logger.Error("warm cache error", logger.Err(err), logger.String("namespace", ns.Name), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrWarmCache)
+		return
+	}
+
+	response.Success(c, &types.WarmCacheReply{Loaded: loaded})
+}
+
+// count the keys of a namespace and get at most limit sample entries
+func (h *cacheHandler) inspect(ctx context.Context, ns *cache.Namespace, limit int) (*types.CacheNamespace, []*types.CacheEntry, error) {
+	iInspector := cache.NewKeyInspector(h.cacheType, ns)
+	count, samples, err := iInspector.Scan(ctx, ns.Prefix, limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &types.CacheNamespace{
+		Name:     ns.Name,
+		Prefix:   ns.Prefix,
+		Listable: iInspector.Listable(),
+		Count:    count,
+	}, samples, nil
+}
+
+func (h *cacheHandler) getKeyFromRequest(c *gin.Context) (*cache.Namespace, string, bool) {
+	ns, ok := getCacheNamespaceFromPath(c)
+	if !ok {
+		return nil, "", false
+	}
+	request := &types.EvictCacheKeyRequest{}
+	err := c.ShouldBindQuery(request)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return nil, "", false
+	}
+	if !strings.HasPrefix(request.Key, ns.Prefix) {
+		response.Error(c, ecode.ErrCacheKeyNamespace)
+		return nil, "", false
+	}
+	return ns, request.Key, true
+}
+
+func getCacheNamespaceFromPath(c *gin.Context) (*cache.Namespace, bool) {
+	ns, ok := cache.GetNamespace(c.Param("namespace"))
+	if !ok {
+		response.Error(c, ecode.NotFound)
+		return nil, false
+	}
+	return ns, true
+}
@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/httpcli"
+
+	"admin/internal/cache"
+	"admin/internal/dao"
+	"admin/internal/database"
+	"admin/internal/ecode"
+	"admin/internal/model"
+	"admin/internal/pkg/confval"
+)
+
+func newCacheHandler() *gotest.Handler {
+	testData := &model.AuditLog{}
+	testData.ID = 1
+
+	c := gotest.NewCache(map[string]interface{}{})
+	d := gotest.NewDao(c, testData)
+	d.IDao = dao.NewAuditLogDao(d.DB)
+
+	cacheType := &database.CacheType{CType: "redis", Rdb: c.RedisClient}
+	h := gotest.NewHandler(d, testData)
+	h.IHandler = &cacheHandler{
+		cacheType: cacheType,
+		iAuditDao: d.IDao.(dao.AuditLogDao),
+		warmers:   newCacheWarmers(d.DB, cacheType),
+	}
+	iHandler := h.IHandler.(CacheHandler)
+
+	testFns := []gotest.RouterInfo{
+		{
+			FuncName:    "GetNamespace",
+			Method:      http.MethodGet,
+			Path:        "/cache/:namespace",
+			HandlerFunc: iHandler.GetNamespace,
+		},
+		{
+			FuncName:    "EvictKey",
+			Method:      http.MethodDelete,
+			Path:        "/cache/:namespace/key",
+			HandlerFunc: iHandler.EvictKey,
+		},
+		{
+			FuncName:    "EvictNamespace",
+			Method:      http.MethodDelete,
+			Path:        "/cache/:namespace",
+			HandlerFunc: iHandler.EvictNamespace,
+		},
+		{
+			FuncName:    "Warm",
+			Method:      http.MethodPost,
+			Path:        "/cache/:namespace/warm",
+			HandlerFunc: iHandler.Warm,
+		},
+	}
+
+	h.GoRunHTTPServer(testFns)
+
+	time.Sleep(time.Millisecond * 200)
+	return h
+}
+
+func expectAudit(h *gotest.Handler) {
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("INSERT INTO `t_audit_log`.*").WillReturnResult(sqlmock.NewResult(1, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+}
+
+func Test_cacheHandler(t *testing.T) {
+	h := newCacheHandler()
+	defer h.Close()
+	cacheType := h.IHandler.(*cacheHandler).cacheType
+	ctx := h.MockDao.Ctx
+
+	roleCache := cache.NewRoleCache(cacheType)
+	role := &model.Role{Name: "admin"}
+	role.ID = 1
+	assert.NoError(t, roleCache.Set(ctx, 1, role, time.Minute))
+	assert.NoError(t, roleCache.Set(ctx, 2, role, time.Minute))
+
+	// view
+	expectAudit(h)
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("GetNamespace", "role"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Code)
+	assert.Equal(t, float64(2), result.Data.(map[string]interface{})["count"])
+
+	err = httpcli.Get(result, h.GetRequestURL("GetNamespace", "unknown"))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.NotFound.Code(), result.Code)
+
+	// evict a key
+	expectAudit(h)
+	err = httpcli.Delete(result, h.GetRequestURL("EvictKey", "role"), httpcli.WithParams(map[string]interface{}{"key": "role:1"}))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Code)
+	_, err = roleCache.Get(ctx, 1)
+	assert.ErrorIs(t, err, database.ErrCacheNotFound)
+
+	err = httpcli.Delete(result, h.GetRequestURL("EvictKey", "role"), httpcli.WithParams(map[string]interface{}{"key": "config:1"}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrCacheKeyNamespace.Code(), result.Code)
+
+	// evict the namespace
+	expectAudit(h)
+	err = httpcli.Delete(result, h.GetRequestURL("EvictNamespace", "role"))
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), result.Data.(map[string]interface{})["deleted"])
+
+	// warm from the database
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "editor"))
+	expectAudit(h)
+	err = httpcli.Post(result, h.GetRequestURL("Warm", "role"), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(1), result.Data.(map[string]interface{})["loaded"])
+	record, err := roleCache.Get(ctx, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "editor", record.Name)
+
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+}
+
+func Test_cacheHandler_GetNamespace_redact(t *testing.T) {
+	h := newCacheHandler()
+	defer h.Close()
+	cacheType := h.IHandler.(*cacheHandler).cacheType
+
+	platform := &model.Platform{Username: "admin", Password: "$2a$10$hash"}
+	platform.ID = 1
+	assert.NoError(t, cache.NewPlatformCache(cacheType).Set(h.MockDao.Ctx, 1, platform, time.Minute))
+
+	expectAudit(h)
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("GetNamespace", "platform"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, result.Code)
+	samples := result.Data.(map[string]interface{})["samples"].([]interface{})
+	if assert.Len(t, samples, 1) {
+		value := samples[0].(map[string]interface{})["value"].(map[string]interface{})
+		assert.Equal(t, "admin", value["username"])
+		assert.Equal(t, confval.SecretMask, value["password"])
+	}
+}
@@ -2,7 +2,11 @@ package handler
 
 import (
 	"admin/internal/database"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -17,6 +21,8 @@ import (
 	"admin/internal/dao"
 	"admin/internal/ecode"
 	"admin/internal/model"
+	"admin/internal/pkg/confval"
+	"admin/internal/pkg/i18n"
 	"admin/internal/types"
 )
 
@@ -30,11 +36,14 @@ type ConfigHandler interface {
 	GetByID(c *gin.Context)
 	List(c *gin.Context)
 	Dict(c *gin.Context)
+	GetGroup(c *gin.Context)
+	Public(c *gin.Context)
 }
 
 type configHandler struct {
-	iDao  dao.ConfigDao
-	cEnum cache.EnumCache
+	iDao    dao.ConfigDao
+	cEnum   cache.EnumCache
+	cPublic cache.PublicConfigCache
 }
 
 // NewConfigHandler creating the handler interface
@@ -44,7 +53,8 @@ func NewConfigHandler() ConfigHandler {
 			database.GetDB(),
 			cache.NewConfigCache(database.GetCacheType()),
 		),
-		cEnum: cache.NewEnumCache(),
+		cEnum:   cache.NewEnumCache(),
+		cPublic: cache.NewPublicConfigCache(database.GetCacheType()),
 	}
 }
 
@@ -74,6 +84,14 @@ func (h *configHandler) Create(c *gin.Context) {
 		return
 	}
 	// Note: if copier.Copy cannot assign a value to a field, add it here
+	if config.Type == "" {
+		config.Type = confval.String
+	}
+	if err = validateConfig(config); err != nil {
+		logger.Warn("invalid config value", logger.Err(err), logger.String("key", config.Key), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrInvalidConfigValue.WithDetails(err.Error()))
+		return
+	}
 
 	ctx := middleware.WrapCtx(c)
 	err = h.iDao.Create(ctx, config)
@@ -82,6 +100,7 @@ func (h *configHandler) Create(c *gin.Context) {
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
 		return
 	}
+	h.delPublicCache(c)
 
 	response.Success(c, gin.H{"id": config.ID})
 }
@@ -115,6 +134,7 @@ func (h *configHandler) DeleteByID(c *gin.Context) {
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
 		return
 	}
+	h.delPublicCache(c)
 
 	response.Success(c)
 }
@@ -155,12 +175,46 @@ func (h *configHandler) UpdateByID(c *gin.Context) {
 	// Note: if copier.Copy cannot assign a value to a field, add it here
 
 	ctx := middleware.WrapCtx(c)
+	// validate the value as it will be after the update, fields that are not submitted keep the stored ones
+	stored, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	if config.Value == confval.SecretMask && stored.Type == confval.Secret {
+		config.Value = "" // the masked value was submitted back, keep the stored secret
+	}
+	merged := *stored
+	if config.Type != "" {
+		merged.Type = config.Type
+	}
+	if config.Constraints != nil {
+		merged.Constraints = config.Constraints
+	}
+	if config.Value != "" {
+		merged.Value = config.Value
+	}
+	if config.Public != nil {
+		merged.Public = config.Public
+	}
+	if err = validateConfig(&merged); err != nil {
+		logger.Warn("invalid config value", logger.Err(err), logger.String("key", merged.Key), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrInvalidConfigValue.WithDetails(err.Error()))
+		return
+	}
+
 	err = h.iDao.UpdateByID(ctx, config)
 	if err != nil {
 		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
 		return
 	}
+	h.delPublicCache(c)
 
 	response.Success(c)
 }
@@ -195,13 +249,11 @@ func (h *configHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	data := &types.ConfigObjDetail{}
-	err = copier.Copy(data, config)
+	data, err := convertConfig(config)
 	if err != nil {
 		response.Error(c, ecode.ErrGetByIDConfig)
 		return
 	}
-	// Note: if copier.Copy cannot assign a value to a field, add it here
 
 	response.Success(c, data)
 }
@@ -245,7 +297,7 @@ func (h *configHandler) List(c *gin.Context) {
 	})
 }
 
-// Dict 字典
+// Dict 字典, 标签为请求的语言
 // @Summary get dict
 // @Description get dict
 // @Tags config
@@ -257,9 +309,128 @@ func (h *configHandler) List(c *gin.Context) {
 func (h *configHandler) Dict(c *gin.Context) {
 	ctx := middleware.WrapCtx(c)
 	result := h.cEnum.GetAll(ctx)
+	if locale := i18n.Get(c); locale != "" && locale != i18n.Default {
+		localized := make(map[string][]*types.Options, len(result))
+		for key, options := range result {
+			localized[key] = types.LocalizeOptions(options, locale)
+		}
+		result = localized
+	}
 	response.Success(c, result)
 }
 
+// GetGroup get the configs of a group as a map
+// @Summary get configs of a group
+// @Description get the values of the configs in a group as a key-value map, values are converted to their types and secrets are masked
+// @Tags config
+// @Param group path string true "group"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.GetConfigGroupReply{}
+// @Router /api/v1/config/group/{group} [get]
+// @Security BearerAuth
+func (h *configHandler) GetGroup(c *gin.Context) {
+	group := c.Param("group")
+	if group == "" {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	configs, err := h.iDao.GetByGroup(ctx, group)
+	if err != nil {
+		logger.Error("GetByGroup error", logger.Err(err), logger.String("group", group), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, convertConfigValues(configs))
+}
+
+// Public get the public configs, no login required
+// @Summary get public configs
+// @Description get the configs flagged public as a key-value map, e.g. site name and logo, supports If-None-Match
+// @Tags config
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.GetConfigGroupReply{}
+// @Router /api/v1/config/public [get]
+func (h *configHandler) Public(c *gin.Context) {
+	ctx := middleware.WrapCtx(c)
+	data, err := h.cPublic.Get(ctx)
+	if err != nil {
+		if !errors.Is(err, database.ErrCacheNotFound) {
+			logger.Warn("cPublic.Get error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		}
+		configs, err := h.iDao.GetPublic(ctx)
+		if err != nil {
+			logger.Error("GetPublic error", logger.Err(err), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+		data, err = newPublicConfigs(configs)
+		if err != nil {
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+		if err = h.cPublic.Set(ctx, data); err != nil {
+			logger.Warn("cPublic.Set error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		}
+	}
+
+	c.Header("ETag", data.ETag)
+	c.Header("Cache-Control", "public, max-age=60")
+	if match := c.GetHeader("If-None-Match"); match != "" && strings.Contains(match, data.ETag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	response.Success(c, data.Configs)
+}
+
+// delete the cached public configs after a config is changed, the cache expires by itself if it fails
+func (h *configHandler) delPublicCache(c *gin.Context) {
+	if err := h.cPublic.Del(middleware.WrapCtx(c)); err != nil {
+		logger.Warn("cPublic.Del error", logger.Err(err), middleware.GCtxRequestIDField(c))
+	}
+}
+
+// validateConfig check the value against the type and constraints, secrets can not be public
+func validateConfig(config *model.Config) error {
+	if config.Type == confval.Secret && config.Public != nil && *config.Public {
+		return errors.New("secret config can not be public")
+	}
+	return confval.Validate(config.Type, config.Constraints, config.Value)
+}
+
+func newPublicConfigs(configs []*model.Config) (*types.PublicConfigs, error) {
+	values := convertConfigValues(configs)
+	body, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(body)
+	return &types.PublicConfigs{
+		ETag:    `"` + hex.EncodeToString(sum[:8]) + `"`,
+		Configs: values,
+	}, nil
+}
+
+func convertConfigValues(configs []*model.Config) types.ConfigValues {
+	values := types.ConfigValues{}
+	for _, config := range configs {
+		if config.Type == confval.Secret {
+			if config.Value != "" {
+				values[config.Key] = confval.SecretMask
+			} else {
+				values[config.Key] = ""
+			}
+			continue
+		}
+		values[config.Key] = confval.Typed(config.Type, config.Value)
+	}
+	return values
+}
+
 func getConfigIDFromPath(c *gin.Context) (string, uint64, bool) {
 	idStr := c.Param("id")
 	id, err := utils.StrToUint64E(idStr)
@@ -278,6 +449,10 @@ func convertConfig(config *model.Config) (*types.ConfigObjDetail, error) {
 		return nil, err
 	}
 	// Note: if copier.Copy cannot assign a value to a field, add it here
+	data.Public = config.Public != nil && *config.Public
+	if data.Type == confval.Secret && data.Value != "" {
+		data.Value = confval.SecretMask
+	}
 
 	return data, nil
 }
@@ -16,7 +16,9 @@ import (
 
 	"admin/internal/cache"
 	"admin/internal/dao"
+	"admin/internal/ecode"
 	"admin/internal/model"
+	"admin/internal/pkg/confval"
 	"admin/internal/types"
 )
 
@@ -40,7 +42,10 @@ func newConfigHandler() *gotest.Handler {
 
 	// init mock handler
 	h := gotest.NewHandler(d, testData)
-	h.IHandler = &configHandler{iDao: d.IDao.(dao.ConfigDao)}
+	h.IHandler = &configHandler{
+		iDao:    d.IDao.(dao.ConfigDao),
+		cPublic: cache.NewPublicConfigCache(&database.CacheType{CType: "redis", Rdb: c.RedisClient}),
+	}
 	iHandler := h.IHandler.(ConfigHandler)
 
 	testFns := []gotest.RouterInfo{
@@ -74,6 +79,18 @@ func newConfigHandler() *gotest.Handler {
 			Path:        "/config/list",
 			HandlerFunc: iHandler.List,
 		},
+		{
+			FuncName:    "GetGroup",
+			Method:      http.MethodGet,
+			Path:        "/config/group/:group",
+			HandlerFunc: iHandler.GetGroup,
+		},
+		{
+			FuncName:    "Public",
+			Method:      http.MethodGet,
+			Path:        "/config/public",
+			HandlerFunc: iHandler.Public,
+		},
 	}
 
 	h.GoRunHTTPServer(testFns)
@@ -103,6 +120,15 @@ func Test_configHandler_Create(t *testing.T) {
 
 	t.Logf("%+v", result)
 
+	// invalid value of the declared type
+	err = httpcli.Post(result, h.GetRequestURL("Create"), &types.CreateConfigRequest{Key: "pageSize", Value: "abc", Type: confval.Int})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrInvalidConfigValue.Code(), result.Code)
+
+	// enum without options
+	err = httpcli.Post(result, h.GetRequestURL("Create"), &types.CreateConfigRequest{Key: "storage", Value: "s3", Type: confval.Enum})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrInvalidConfigValue.Code(), result.Code)
 }
 
 func Test_configHandler_DeleteByID(t *testing.T) {
@@ -156,6 +182,8 @@ func Test_configHandler_UpdateByID(t *testing.T) {
 	testData := &types.UpdateConfigByIDRequest{}
 	_ = copier.Copy(testData, h.TestData.(*model.Config))
 
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "key", "value", "type"}).AddRow(testData.ID, "pageSize", "10", "int"))
 	h.MockDao.SQLMock.ExpectBegin()
 	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
 		WithArgs(h.MockDao.AnyTime, testData.ID). // adjusted for the amount of test data
@@ -171,11 +199,20 @@ func Test_configHandler_UpdateByID(t *testing.T) {
 		t.Fatalf("%+v", result)
 	}
 
+	// the value does not match the stored type
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "key", "value", "type"}).AddRow(testData.ID, "pageSize", "10", "int"))
+	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", testData.ID), &types.UpdateConfigByIDRequest{Value: "abc"})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrInvalidConfigValue.Code(), result.Code)
+
 	// zero id error test
 	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", 0), testData)
 	assert.NoError(t, err)
 
 	// update error test - 为错误测试添加mock期望
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "key", "value"}).AddRow(111, "a", "b"))
 	h.MockDao.SQLMock.ExpectBegin()
 	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
 		WithArgs(h.MockDao.AnyTime, uint64(111)).
@@ -251,6 +288,61 @@ func Test_configHandler_List(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_configHandler_GetGroup(t *testing.T) {
+	h := newConfigHandler()
+	defer h.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "key", "value", "type"}).
+		AddRow(1, "pageSize", "20", confval.Int).
+		AddRow(2, "smtpPassword", "123456", confval.Secret)
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("GetGroup", "site"))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Code)
+	assert.Equal(t, map[string]interface{}{"pageSize": float64(20), "smtpPassword": confval.SecretMask}, result.Data)
+}
+
+func Test_configHandler_Public(t *testing.T) {
+	h := newConfigHandler()
+	defer h.Close()
+
+	// only the first request queries the database, the others are served from the cache
+	rows := sqlmock.NewRows([]string{"id", "key", "value", "type", "public"}).
+		AddRow(7, "siteName", "Admin", confval.String, true)
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	url := h.GetRequestURL("Public")
+	resp, err := http.Get(url)
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	etag := resp.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	result := &httpcli.StdResult{}
+	err = httpcli.Get(result, url)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"siteName": "Admin"}, result.Data)
+
+	req, _ := http.NewRequest(http.MethodGet, url, nil)
+	req.Header.Set("If-None-Match", etag)
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+}
+
+func Test_validateConfig(t *testing.T) {
+	public := true
+	err := validateConfig(&model.Config{Type: confval.Secret, Value: "123456", Public: &public})
+	assert.Error(t, err)
+	err = validateConfig(&model.Config{Type: confval.String, Value: "Admin", Public: &public})
+	assert.NoError(t, err)
+}
+
 func TestNewConfigHandler(t *testing.T) {
 	defer func() {
 		recover()
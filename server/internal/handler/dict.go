@@ -0,0 +1,353 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"admin/internal/cache"
+	"admin/internal/dao"
+	"admin/internal/database"
+	"admin/internal/ecode"
+	"admin/internal/model"
+	"admin/internal/types"
+	"admin/internal/validation"
+)
+
+var _ DictHandler = (*dictHandler)(nil)
+
+// DictHandler defining the handler interface
+type DictHandler interface {
+	Create(c *gin.Context)
+	DeleteByID(c *gin.Context)
+	UpdateByID(c *gin.Context)
+	GetByID(c *gin.Context)
+	List(c *gin.Context)
+}
+
+type dictHandler struct {
+	iDao     dao.DictDao
+	iItemDao dao.DictItemDao
+	cEnum    cache.EnumCache
+}
+
+// NewDictHandler creating the handler interface
+func NewDictHandler() DictHandler {
+	return &dictHandler{
+		iDao:     dao.NewDictDao(database.GetDB()),
+		iItemDao: dao.NewDictItemDao(database.GetDB()),
+		cEnum:    cache.NewEnumCache(),
+	}
+}
+
+// Create a record
+// @Summary create dict
+// @Description submit information to create dict, the code can not be the same as a code enum
+// @Tags dict
+// @accept json
+// @Produce json
+// @Param data body types.CreateDictRequest true "dict information"
+// @Success 200 {object} types.CreateDictReply{}
+// @Router /api/v1/dict [post]
+// @Security BearerAuth
+func (h *dictHandler) Create(c *gin.Context) {
+	form := &types.CreateDictRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
+		return
+	}
+
+	dict := &model.Dict{}
+	err = copier.Copy(dict, form)
+	if err != nil {
+		response.Error(c, ecode.ErrCreateDict)
+		return
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+	if dict.ValueType == "" {
+		dict.ValueType = dao.DictValueTypeString
+	}
+
+	ctx := middleware.WrapCtx(c)
+	if !h.checkCode(c, dict.Code, 0) {
+		return
+	}
+	err = h.iDao.Create(ctx, dict)
+	if err != nil {
+		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	reloadEnum(c, h.cEnum)
+
+	response.Success(c, gin.H{"id": dict.ID})
+}
+
+// DeleteByID delete a record and its items by id
+// @Summary delete dict
+// @Description delete dict and its items by id
+// @Tags dict
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.DeleteDictByIDReply{}
+// @Router /api/v1/dict/{id} [delete]
+// @Security BearerAuth
+func (h *dictHandler) DeleteByID(c *gin.Context) {
+	id, isAbort := getDictIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := h.iDao.DeleteByID(ctx, id)
+	if err != nil {
+		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	reloadEnum(c, h.cEnum)
+
+	response.Success(c)
+}
+
+// UpdateByID update information by id
+// @Summary update dict
+// @Description update dict information by id
+// @Tags dict
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.UpdateDictByIDRequest true "dict information"
+// @Success 200 {object} types.UpdateDictByIDReply{}
+// @Router /api/v1/dict/{id} [put]
+// @Security BearerAuth
+func (h *dictHandler) UpdateByID(c *gin.Context) {
+	id, isAbort := getDictIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.UpdateDictByIDRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
+		return
+	}
+	form.ID = id
+
+	dict := &model.Dict{}
+	err = copier.Copy(dict, form)
+	if err != nil {
+		response.Error(c, ecode.ErrUpdateByIDDict)
+		return
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+
+	ctx := middleware.WrapCtx(c)
+	if dict.Code != "" && !h.checkCode(c, dict.Code, id) {
+		return
+	}
+	err = h.iDao.UpdateByID(ctx, dict)
+	if err != nil {
+		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	reloadEnum(c, h.cEnum)
+
+	response.Success(c)
+}
+
+// GetByID get a record and its items by id
+// @Summary get dict detail
+// @Description get dict detail and its items by id
+// @Tags dict
+// @Param id path string true "id"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.GetDictByIDReply{}
+// @Router /api/v1/dict/{id} [get]
+// @Security BearerAuth
+func (h *dictHandler) GetByID(c *gin.Context) {
+	id, isAbort := getDictIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	dict, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("GetByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	items, err := h.iItemDao.GetByDictID(ctx, id)
+	if err != nil {
+		logger.Error("GetByDictID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	data, err := convertDict(dict)
+	if err != nil {
+		response.Error(c, ecode.ErrGetByIDDict)
+		return
+	}
+	data.Items, err = convertDictItems(items)
+	if err != nil {
+		response.Error(c, ecode.ErrGetByIDDict)
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// List of records by query parameters
+// @Summary list of dicts by query parameters
+// @Description list of dicts by paging and conditions, the code enums are not included
+// @Tags dict
+// @accept json
+// @Produce json
+// @Param request query types.ListDictsRequest true "query parameters"
+// @Success 200 {object} types.ListDictsReply{}
+// @Router /api/v1/dict [get]
+// @Security BearerAuth
+func (h *dictHandler) List(c *gin.Context) {
+	request := &types.ListDictsRequest{}
+	err := c.ShouldBindQuery(request)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	dicts, total, err := h.iDao.GetByParams(ctx, request)
+	if err != nil {
+		logger.Error("GetByParams error", logger.Err(err), logger.Any("request", request), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	data := []*types.DictObjDetail{}
+	for _, dict := range dicts {
+		detail, err := convertDict(dict)
+		if err != nil {
+			response.Error(c, ecode.ErrListDict)
+			return
+		}
+		data = append(data, detail)
+	}
+
+	response.Success(c, gin.H{
+		"list":  data,
+		"total": total,
+	})
+}
+
+// checkCode the code can not be the same as a code enum or another dictionary, the error is responded if it is false
+func (h *dictHandler) checkCode(c *gin.Context, code string, id uint64) bool {
+	if h.cEnum.IsCodeEnum(code) {
+		response.Error(c, ecode.ErrDictCodeReadOnly)
+		return false
+	}
+	dict, err := h.iDao.GetByCode(middleware.WrapCtx(c), code)
+	if err == nil && dict.ID != id {
+		response.Error(c, ecode.ErrDictCodeExists)
+		return false
+	}
+	if err != nil && !errors.Is(err, database.ErrRecordNotFound) {
+		logger.Error("GetByCode error", logger.Err(err), logger.String("code", code), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return false
+	}
+	return true
+}
+
+// reloadEnum reload the enum cache after a dictionary is changed, the change is served after the enum keys
+// expire if it fails
+func reloadEnum(c *gin.Context, cEnum cache.EnumCache) {
+	if err := cEnum.Reload(middleware.WrapCtx(c)); err != nil {
+		logger.Warn("reload enum cache error", logger.Err(err), middleware.GCtxRequestIDField(c))
+	}
+}
+
+// checkDictItemValue the value of an item of an int dictionary must be an integer
+func checkDictItemValue(dict *model.Dict, value string) bool {
+	if dict.ValueType != dao.DictValueTypeInt || value == "" {
+		return true
+	}
+	_, err := strconv.Atoi(value)
+	return err == nil
+}
+
+func getDictIDFromPath(c *gin.Context) (uint64, bool) {
+	idStr := c.Param("id")
+	id, err := utils.StrToUint64E(idStr)
+	if err != nil || id == 0 {
+		logger.Warn("StrToUint64E error: ", logger.String("idStr", idStr), middleware.GCtxRequestIDField(c))
+		return 0, true
+	}
+
+	return id, false
+}
+
+func convertDict(dict *model.Dict) (*types.DictObjDetail, error) {
+	data := &types.DictObjDetail{}
+	err := copier.Copy(data, dict)
+	if err != nil {
+		return nil, err
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+	if dict.Status != nil {
+		data.Status = *dict.Status
+	}
+
+	return data, nil
+}
+
+func convertDictItem(item *model.DictItem) (*types.DictItemObjDetail, error) {
+	data := &types.DictItemObjDetail{}
+	err := copier.Copy(data, item)
+	if err != nil {
+		return nil, err
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+	if item.Status != nil {
+		data.Status = *item.Status
+	}
+
+	return data, nil
+}
+
+func convertDictItems(fromValues []*model.DictItem) ([]*types.DictItemObjDetail, error) {
+	toValues := []*types.DictItemObjDetail{}
+	for _, v := range fromValues {
+		data, err := convertDictItem(v)
+		if err != nil {
+			return nil, err
+		}
+		toValues = append(toValues, data)
+	}
+
+	return toValues, nil
+}
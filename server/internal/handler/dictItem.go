@@ -0,0 +1,271 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"admin/internal/cache"
+	"admin/internal/dao"
+	"admin/internal/database"
+	"admin/internal/ecode"
+	"admin/internal/model"
+	"admin/internal/types"
+	"admin/internal/validation"
+)
+
+var _ DictItemHandler = (*dictItemHandler)(nil)
+
+// DictItemHandler defining the handler interface
+type DictItemHandler interface {
+	Create(c *gin.Context)
+	DeleteByID(c *gin.Context)
+	UpdateByID(c *gin.Context)
+	GetByID(c *gin.Context)
+	List(c *gin.Context)
+}
+
+type dictItemHandler struct {
+	iDao     dao.DictItemDao
+	iDictDao dao.DictDao
+	cEnum    cache.EnumCache
+}
+
+// NewDictItemHandler creating the handler interface
+func NewDictItemHandler() DictItemHandler {
+	return &dictItemHandler{
+		iDao:     dao.NewDictItemDao(database.GetDB()),
+		iDictDao: dao.NewDictDao(database.GetDB()),
+		cEnum:    cache.NewEnumCache(),
+	}
+}
+
+// Create a record
+// @Summary create dict item
+// @Description submit information to create dict item, the value must be an integer if the value type of the dict is int
+// @Tags dictItem
+// @accept json
+// @Produce json
+// @Param data body types.CreateDictItemRequest true "dict item information"
+// @Success 200 {object} types.CreateDictItemReply{}
+// @Router /api/v1/dictItem [post]
+// @Security BearerAuth
+func (h *dictItemHandler) Create(c *gin.Context) {
+	form := &types.CreateDictItemRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
+		return
+	}
+
+	item := &model.DictItem{}
+	err = copier.Copy(item, form)
+	if err != nil {
+		response.Error(c, ecode.ErrCreateDictItem)
+		return
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+
+	ctx := middleware.WrapCtx(c)
+	if !h.checkValue(c, item.DictID, item.Value) {
+		return
+	}
+	err = h.iDao.Create(ctx, item)
+	if err != nil {
+		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	reloadEnum(c, h.cEnum)
+
+	response.Success(c, gin.H{"id": item.ID})
+}
+
+// DeleteByID delete a record by id
+// @Summary delete dict item
+// @Description delete dict item by id
+// @Tags dictItem
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.DeleteDictItemByIDReply{}
+// @Router /api/v1/dictItem/{id} [delete]
+// @Security BearerAuth
+func (h *dictItemHandler) DeleteByID(c *gin.Context) {
+	id, isAbort := getDictIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err := h.iDao.DeleteByID(ctx, id)
+	if err != nil {
+		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	reloadEnum(c, h.cEnum)
+
+	response.Success(c)
+}
+
+// UpdateByID update information by id
+// @Summary update dict item
+// @Description update dict item information by id
+// @Tags dictItem
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Param data body types.UpdateDictItemByIDRequest true "dict item information"
+// @Success 200 {object} types.UpdateDictItemByIDReply{}
+// @Router /api/v1/dictItem/{id} [put]
+// @Security BearerAuth
+func (h *dictItemHandler) UpdateByID(c *gin.Context) {
+	id, isAbort := getDictIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	form := &types.UpdateDictItemByIDRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
+		return
+	}
+	form.ID = id
+
+	item := &model.DictItem{}
+	err = copier.Copy(item, form)
+	if err != nil {
+		response.Error(c, ecode.ErrUpdateByIDDictItem)
+		return
+	}
+	// Note: if copier.Copy cannot assign a value to a field, add it here
+
+	ctx := middleware.WrapCtx(c)
+	stored, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	if !h.checkValue(c, stored.DictID, item.Value) {
+		return
+	}
+	err = h.iDao.UpdateByID(ctx, item)
+	if err != nil {
+		logger.Error("UpdateByID error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	reloadEnum(c, h.cEnum)
+
+	response.Success(c)
+}
+
+// GetByID get a record by id
+// @Summary get dict item detail
+// @Description get dict item detail by id
+// @Tags dictItem
+// @Param id path string true "id"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.GetDictItemByIDReply{}
+// @Router /api/v1/dictItem/{id} [get]
+// @Security BearerAuth
+func (h *dictItemHandler) GetByID(c *gin.Context) {
+	id, isAbort := getDictIDFromPath(c)
+	if isAbort {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	item, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("GetByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	data, err := convertDictItem(item)
+	if err != nil {
+		response.Error(c, ecode.ErrGetByIDDict)
+		return
+	}
+
+	response.Success(c, data)
+}
+
+// List of the items of a dict
+// @Summary list of dict items
+// @Description list of all items of a dict in order, the disabled ones are included
+// @Tags dictItem
+// @accept json
+// @Produce json
+// @Param request query types.ListDictItemsRequest true "query parameters"
+// @Success 200 {object} types.ListDictItemsReply{}
+// @Router /api/v1/dictItem [get]
+// @Security BearerAuth
+func (h *dictItemHandler) List(c *gin.Context) {
+	request := &types.ListDictItemsRequest{}
+	err := c.ShouldBindQuery(request)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	items, err := h.iDao.GetByDictID(ctx, request.DictID)
+	if err != nil {
+		logger.Error("GetByDictID error", logger.Err(err), logger.Any("request", request), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	data, err := convertDictItems(items)
+	if err != nil {
+		response.Error(c, ecode.ErrListDictItem)
+		return
+	}
+
+	response.Success(c, gin.H{"list": data})
+}
+
+// checkValue the dict must exist and the value must match its value type, the error is responded if it is false
+func (h *dictItemHandler) checkValue(c *gin.Context, dictID uint64, value string) bool {
+	dict, err := h.iDictDao.GetByID(middleware.WrapCtx(c), dictID)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("dictID", dictID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return false
+	}
+	if !checkDictItemValue(dict, value) {
+		response.Error(c, ecode.ErrDictItemValue)
+		return false
+	}
+	return true
+}
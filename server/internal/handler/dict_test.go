@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/httpcli"
+
+	"admin/internal/cache"
+	"admin/internal/config"
+	"admin/internal/dao"
+	"admin/internal/ecode"
+	"admin/internal/model"
+	"admin/internal/types"
+)
+
+func newDictHandler() *gotest.Handler {
+	_ = config.Init("")
+	testData := &model.Dict{Code: "channel", ValueType: dao.DictValueTypeInt}
+	testData.ID = 1
+
+	d := gotest.NewDao(nil, testData)
+	h := gotest.NewHandler(d, testData)
+	dictHandler := &dictHandler{
+		iDao:     dao.NewDictDao(d.DB),
+		iItemDao: dao.NewDictItemDao(d.DB),
+		cEnum:    cache.NewEnumCache(),
+	}
+	itemHandler := &dictItemHandler{
+		iDao:     dao.NewDictItemDao(d.DB),
+		iDictDao: dao.NewDictDao(d.DB),
+		cEnum:    cache.NewEnumCache(),
+	}
+	h.IHandler = dictHandler
+
+	testFns := []gotest.RouterInfo{
+		{
+			FuncName:    "Create",
+			Method:      http.MethodPost,
+			Path:        "/dict",
+			HandlerFunc: dictHandler.Create,
+		},
+		{
+			FuncName:    "UpdateByID",
+			Method:      http.MethodPut,
+			Path:        "/dict/:id",
+			HandlerFunc: dictHandler.UpdateByID,
+		},
+		{
+			FuncName:    "GetByID",
+			Method:      http.MethodGet,
+			Path:        "/dict/:id",
+			HandlerFunc: dictHandler.GetByID,
+		},
+		{
+			FuncName:    "CreateItem",
+			Method:      http.MethodPost,
+			Path:        "/dictItem",
+			HandlerFunc: itemHandler.Create,
+		},
+	}
+
+	h.GoRunHTTPServer(testFns)
+
+	time.Sleep(time.Millisecond * 200)
+	return h
+}
+
+func Test_dictHandler_Create(t *testing.T) {
+	h := newDictHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Dict)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("INSERT INTO .*").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("Create"), &types.CreateDictRequest{Name: "渠道", Code: testData.Code})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Code)
+
+	// code enums are read only
+	err = httpcli.Post(result, h.GetRequestURL("Create"), &types.CreateDictRequest{Name: "性别", Code: "gender"})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrDictCodeReadOnly.Code(), result.Code)
+
+	// the code is used by another dict
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code"}).AddRow(2, testData.Code))
+	err = httpcli.Post(result, h.GetRequestURL("Create"), &types.CreateDictRequest{Name: "渠道", Code: testData.Code})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrDictCodeExists.Code(), result.Code)
+}
+
+func Test_dictHandler_UpdateByID(t *testing.T) {
+	h := newDictHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Dict)
+
+	// the code of the dict itself
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code"}).AddRow(testData.ID, testData.Code))
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Put(result, h.GetRequestURL("UpdateByID", testData.ID), &types.UpdateDictByIDRequest{Code: testData.Code})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Code)
+
+	err = httpcli.Put(result, h.GetRequestURL("UpdateByID", testData.ID), &types.UpdateDictByIDRequest{Code: "whether"})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrDictCodeReadOnly.Code(), result.Code)
+}
+
+func Test_dictHandler_GetByID(t *testing.T) {
+	h := newDictHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Dict)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "code", "status"}).AddRow(testData.ID, testData.Code, 1))
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "dict_id", "label", "value"}).AddRow(1, testData.ID, "官网", "1"))
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("GetByID", testData.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Code)
+	data := result.Data.(map[string]interface{})
+	assert.Equal(t, float64(1), data["status"])
+	assert.Len(t, data["items"], 1)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	err = httpcli.Get(result, h.GetRequestURL("GetByID", 2))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.NotFound.Code(), result.Code)
+}
+
+func Test_dictItemHandler_Create(t *testing.T) {
+	h := newDictHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.Dict)
+	dictRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "code", "value_type"}).AddRow(testData.ID, testData.Code, testData.ValueType)
+	}
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(dictRows())
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("INSERT INTO .*").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("CreateItem"), &types.CreateDictItemRequest{DictID: testData.ID, Label: "官网", Value: "1", TagType: "info"})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Code)
+
+	// the value of an int dict must be an integer
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(dictRows())
+	err = httpcli.Post(result, h.GetRequestURL("CreateItem"), &types.CreateDictItemRequest{DictID: testData.ID, Label: "官网", Value: "web"})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrDictItemValue.Code(), result.Code)
+
+	// the dict does not exist
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	err = httpcli.Post(result, h.GetRequestURL("CreateItem"), &types.CreateDictItemRequest{DictID: 2, Label: "官网", Value: "1"})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.NotFound.Code(), result.Code)
+}
@@ -0,0 +1,467 @@
+package handler
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jinzhu/copier"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"admin/internal/cache"
+	"admin/internal/constant"
+	"admin/internal/constant/enum"
+	"admin/internal/dao"
+	"admin/internal/database"
+	"admin/internal/ecode"
+	"admin/internal/model"
+	"admin/internal/pkg/imageproc"
+	"admin/internal/pkg/signurl"
+	"admin/internal/pkg/storage"
+	"admin/internal/task"
+	"admin/internal/types"
+)
+
+var _ FileHandler = (*fileHandler)(nil)
+
+// FileHandler defining the handler interface
+type FileHandler interface {
+	DeleteByID(c *gin.Context)
+	GetByID(c *gin.Context)
+	List(c *gin.Context)
+
+	SignURL(c *gin.Context)
+	Download(c *gin.Context)
+
+	RunGC(c *gin.Context)
+	GetGCReport(c *gin.Context)
+}
+
+type fileHandler struct {
+	iDao       dao.FileDao
+	iConfigDao dao.ConfigDao
+	storage    storage.Storage
+	signer     *signurl.Signer
+	iGCCache   cache.FileGCCache
+}
+
+// NewFileHandler creating the handler interface
+func NewFileHandler() FileHandler {
+	return &fileHandler{
+		iDao: dao.NewFileDao(
+			database.GetDB(),
+			cache.NewFileCache(database.GetCacheType()),
+		),
+		iConfigDao: dao.NewConfigDao(
+			database.GetDB(),
+			cache.NewConfigCache(database.GetCacheType()),
+		),
+		storage:  database.GetStorage(),
+		signer:   database.GetURLSigner(),
+		iGCCache: cache.NewFileGCCache(database.GetCacheType()),
+	}
+}
+
+// DeleteByID delete records by id, files that are still referenced can not be deleted
+// @Summary delete file
+// @Description delete file by id, multiple ids are separated by commas, the stored object is deleted too
+// @Tags file
+// @accept json
+// @Produce json
+// @Param id path string true "id"
+// @Success 200 {object} types.DeleteFileByIDReply{}
+// @Router /api/v1/file/{id} [delete]
+// @Security BearerAuth
+func (h *fileHandler) DeleteByID(c *gin.Context) {
+	idStr := c.Param("id")
+	if idStr == "" {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	var ids []uint64
+	for _, v := range strings.Split(idStr, ",") {
+		ids = append(ids, utils.StrToUint64(v))
+	}
+
+	ctx := middleware.WrapCtx(c)
+	refs, err := h.iDao.CountRefs(ctx, ids)
+	if err != nil {
+		logger.Error("CountRefs error", logger.Err(err), logger.Any("id", idStr), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	if len(refs) > 0 {
+		response.Error(c, ecode.ErrFileInUse)
+		return
+	}
+
+	files, err := h.iDao.GetByIDs(ctx, ids)
+	if err != nil {
+		logger.Error("GetByIDs error", logger.Err(err), logger.Any("id", idStr), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	err = h.iDao.DeleteByIDs(ctx, ids)
+	if err != nil {
+		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", idStr), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	for _, file := range files {
+		keys := []string{file.StorageKey}
+		for _, suffix := range file.Variants {
+			keys = append(keys, imageproc.VariantKey(file.StorageKey, suffix))
+		}
+		for _, key := range keys {
+			if err = h.storage.Delete(ctx, key); err != nil {
+				logger.Warn("storage.Delete error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+			}
+		}
+	}
+
+	response.Success(c)
+}
+
+// GetByID get a record by id
+// @Summary get file detail
+// @Description get file detail by id
+// @Tags file
+// @Param id path string true "id"
+// @Accept json
+// @Produce json
+// @Success 200 {object} types.GetFileByIDReply{}
+// @Router /api/v1/file/{id} [get]
+// @Security BearerAuth
+func (h *fileHandler) GetByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := utils.StrToUint64E(idStr)
+	if err != nil || id == 0 {
+		logger.Warn("StrToUint64E error: ", logger.String("idStr", idStr), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	file, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("GetByID not found", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+
+	data, err := h.convertFiles(c, []*model.File{file})
+	if err != nil {
+		response.Error(c, ecode.ErrGetByIDFile)
+		return
+	}
+
+	response.Success(c, data[0])
+}
+
+// List of records by query parameters
+// @Summary list of files by query parameters
+// @Description list of files by paging and conditions, search by name, mime type prefix and uploader
+// @Tags file
+// @accept json
+// @Produce json
+// @Param request query types.ListFilesRequest true "query parameters"
+// @Success 200 {object} types.ListFilesReply{}
+// @Router /api/v1/file [get]
+// @Security BearerAuth
+func (h *fileHandler) List(c *gin.Context) {
+	request := &types.ListFilesRequest{}
+	err := c.ShouldBindQuery(request)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	files, total, err := h.iDao.GetByParams(ctx, request)
+	if err != nil {
+		logger.Error("GetByParams error", logger.Err(err), logger.Any("request", request), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	data, err := h.convertFiles(c, files)
+	if err != nil {
+		response.Error(c, ecode.ErrListFile)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"list":  data,
+		"total": total,
+	})
+}
+
+func (h *fileHandler) convertFiles(c *gin.Context, fromValues []*model.File) ([]*types.FileObjDetail, error) {
+	var ids []uint64
+	for _, v := range fromValues {
+		ids = append(ids, v.ID)
+	}
+
+	refs := map[uint64]int64{}
+	if len(ids) > 0 {
+		refs, _ = h.iDao.CountRefs(c, ids)
+	}
+
+	toValues := []*types.FileObjDetail{}
+	for _, v := range fromValues {
+		data := &types.FileObjDetail{}
+		err := copier.Copy(data, v)
+		if err != nil {
+			return nil, err
+		}
+		// Note: if copier.Copy cannot assign a value to a field, add it here
+		data.RefCount = refs[v.ID]
+		if v.Private {
+			data.Path = constant.DownloadPathPrefix + utils.Uint64ToStr(v.ID)
+			// the other accounts see the record but get no url
+			if canReadPrivate(c, v) {
+				data.Url = signedURL(c, h.iConfigDao, h.signer, v.ID, c.GetUint64("id"), defaultExpiredAt())
+			}
+			toValues = append(toValues, data)
+			continue
+		}
+		data.Path = constant.UploadPathPrefix + v.StorageKey
+		data.Url = h.iConfigDao.MakePathByConfig(c, data.Path, constant.ConfigKeyImageDomain)
+		data.Variants = variantURLs(c, h.iConfigDao, v)
+		toValues = append(toValues, data)
+	}
+
+	return toValues, nil
+}
+
+// SignURL create a signed download url which expires, it can be bound to an account. the urls of a private
+// file are only signed for its owner and the administrators, an unbound url only for its owner
+// @Summary sign download url
+// @Description create an expiring download url of the file, private files can only be downloaded by signed urls, only the owner and the administrators can sign them, and only the owner can sign an url not bound to an account
+// @Tags file
+// @Produce json
+// @Param id path string true "id"
+// @Param request query types.SignFileURLRequest true "query parameters"
+// @Success 200 {object} types.SignFileURLReply{}
+// @Router /api/v1/file/{id}/sign [get]
+// @Security BearerAuth
+func (h *fileHandler) SignURL(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := utils.StrToUint64E(idStr)
+	if err != nil || id == 0 {
+		logger.Warn("StrToUint64E error: ", logger.String("idStr", idStr), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	request := &types.SignFileURLRequest{}
+	err = c.ShouldBindQuery(request)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if request.Expires == 0 {
+		request.Expires = constant.SignedURLDefaultExpires
+	}
+	if request.Expires > constant.SignedURLMaxExpires {
+		response.Error(c, ecode.InvalidParams.WithDetails("expires is too long"))
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	file, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+		} else {
+			logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return
+	}
+	if file.Private {
+		owner := file.OwnerID != 0 && file.OwnerID == c.GetUint64("id")
+		if !canReadPrivate(c, file) || (request.AccountID == 0 && !owner) {
+			logger.Warn("sign private file refused", logger.Any("id", id), logger.Any("accountID", request.AccountID),
+				logger.Any("caller", c.GetUint64("id")), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.Forbidden)
+			return
+		}
+	}
+
+	expiredAt := time.Now().Add(time.Duration(request.Expires) * time.Second)
+	response.Success(c, types.SignedURL{
+		Url:       signedURL(c, h.iConfigDao, h.signer, file.ID, request.AccountID, expiredAt),
+		ExpiredAt: expiredAt,
+	})
+}
+
+// Download download a file by a signed url, Range requests are supported, urls bound to an account
+// require the token of that account, by the Authorization header or the cookie set at login
+// @Summary download file
+// @Description download a file by a signed url created by /api/v1/file/{id}/sign
+// @Tags file
+// @Produce octet-stream
+// @Param id path string true "id"
+// @Param expires query int true "expiry unix time"
+// @Param account query int false "bound account id"
+// @Param sign query string true "signature"
+// @Success 200 {file} file
+// @Router /api/v1/download/{id} [get]
+func (h *fileHandler) Download(c *gin.Context) {
+	id, err := utils.StrToUint64E(c.Param("id"))
+	if err != nil || id == 0 {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	err = h.signer.Verify(id, c.Request.URL.Query(), c.GetUint64("id"), time.Now())
+	if err != nil {
+		logger.Warn("signed url verify failed", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		c.Status(http.StatusForbidden)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	file, err := h.iDao.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		logger.Error("GetByID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	obj, err := h.storage.Open(ctx, file.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		logger.Error("storage.Open error", logger.Err(err), logger.String("key", file.StorageKey), middleware.GCtxRequestIDField(c))
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	defer obj.Close()
+
+	c.Header("Content-Type", file.MimeType)
+	c.Header("Content-Disposition", contentDisposition(file.Name))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Cache-Control", "private, no-store")
+	http.ServeContent(c.Writer, c.Request, file.Name, obj.Info().LastModified, obj)
+}
+
+// contentDisposition attachment header with the original file name, non ASCII names are encoded by RFC 5987
+func contentDisposition(name string) string {
+	if v := mime.FormatMediaType("attachment", map[string]string{"filename": name}); v != "" {
+		return v
+	}
+	return "attachment"
+}
+
+// signedURL signed download url of the file, accountID 0 means the url is not bound to an account
+func signedURL(c *gin.Context, iConfigDao dao.ConfigDao, signer *signurl.Signer, id uint64, accountID uint64, expiredAt time.Time) string {
+	filePath := constant.DownloadPathPrefix + utils.Uint64ToStr(id)
+	return iConfigDao.MakePathByConfig(c, filePath, constant.ConfigKeyImageDomain) + "?" + signer.Sign(id, accountID, expiredAt).Encode()
+}
+
+// canReadPrivate whether the caller can download the private file, its owner and the administrators can
+func canReadPrivate(c *gin.Context, file *model.File) bool {
+	if file.OwnerID != 0 && file.OwnerID == c.GetUint64("id") {
+		return true
+	}
+	for _, code := range c.GetStringSlice("roleCode") {
+		if code == enum.RoleCodeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+func defaultExpiredAt() time.Time {
+	return time.Now().Add(constant.SignedURLDefaultExpires * time.Second)
+}
+
+// RunGC collect orphaned uploads now, it only reports by default, set dryRun=false to delete them
+// @Summary collect orphaned files
+// @Description find stored objects older than the grace period that are no longer referenced by model fields or t_file_ref, delete them if dryRun is false, the objects not in t_file only if deleteUnregistered of uploadGC is true
+// @Tags file
+// @Produce json
+// @Param request query types.RunFileGCRequest true "query parameters"
+// @Success 200 {object} types.FileGCReportReply{}
+// @Router /api/v1/file/gc [post]
+// @Security BearerAuth
+func (h *fileHandler) RunGC(c *gin.Context) {
+	request := &types.RunFileGCRequest{}
+	err := c.ShouldBindQuery(request)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	dryRun := true
+	if request.DryRun != nil {
+		dryRun = *request.DryRun
+	}
+
+	ctx := middleware.WrapCtx(c)
+	report, err := task.RunFileGC(ctx, &task.FileGCDeps{
+		ConfigDao: h.iConfigDao,
+		FileDao:   h.iDao,
+		Storage:   h.storage,
+		Cache:     h.iGCCache,
+	}, &dryRun)
+	if err != nil {
+		if errors.Is(err, task.ErrFileGCRunning) {
+			response.Error(c, ecode.ErrFileGCRunning)
+			return
+		}
+		logger.Error("RunFileGC error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrFileGC)
+		return
+	}
+	logger.Info("orphaned upload gc was run", logger.Any("operator", c.GetUint64("id")), logger.Bool("dryRun", dryRun),
+		logger.Int("orphans", report.OrphanCount), logger.Int("deleted", report.Deleted), middleware.GCtxRequestIDField(c))
+
+	response.Success(c, report)
+}
+
+// GetGCReport get the report of the last collection, by the scheduled job or the admin endpoint
+// @Summary get the last report of orphaned files
+// @Description get the report of the last orphaned upload garbage collection
+// @Tags file
+// @Produce json
+// @Success 200 {object} types.FileGCReportReply{}
+// @Router /api/v1/file/gc [get]
+// @Security BearerAuth
+func (h *fileHandler) GetGCReport(c *gin.Context) {
+	report, err := h.iGCCache.GetReport(middleware.WrapCtx(c))
+	if err != nil {
+		if errors.Is(err, database.ErrCacheNotFound) {
+			response.Error(c, ecode.NotFound.WithDetails("gc has not run yet"))
+			return
+		}
+		logger.Error("GetReport error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, report)
+}
@@ -0,0 +1,419 @@
+package handler
+
+import (
+	"admin/internal/database"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/httpcli"
+	"github.com/go-dev-frame/sponge/pkg/jwt"
+	"github.com/go-dev-frame/sponge/pkg/utils"
+
+	"admin/internal/cache"
+	"admin/internal/constant/enum"
+	"admin/internal/dao"
+	"admin/internal/ecode"
+	"admin/internal/middlewares"
+	"admin/internal/model"
+	"admin/internal/pkg/signurl"
+	"admin/internal/pkg/storage"
+	"admin/internal/types"
+)
+
+func newFileHandler() *gotest.Handler {
+	testData := &model.File{}
+	testData.ID = 1
+	testData.StorageKey = "2024-11-10/a.png"
+
+	// init mock cache
+	c := gotest.NewCache(map[string]interface{}{utils.Uint64ToStr(testData.ID): testData})
+	c.ICache = cache.NewFileCache(&database.CacheType{
+		CType: "redis",
+		Rdb:   c.RedisClient,
+	})
+
+	// init mock dao
+	d := gotest.NewDao(c, testData)
+	d.IDao = dao.NewFileDao(d.DB, c.ICache.(cache.FileCache))
+
+	// init mock handler
+	h := gotest.NewHandler(d, testData)
+	h.IHandler = &fileHandler{
+		iDao:       d.IDao.(dao.FileDao),
+		iConfigDao: dao.NewConfigDao(d.DB, nil),
+		storage:    storage.NewLocal(filepath.Join(os.TempDir(), "admin-file-test")),
+		signer:     signurl.New("test"),
+		iGCCache:   cache.NewFileGCCache(&database.CacheType{CType: "memory"}),
+	}
+	iHandler := h.IHandler.(FileHandler)
+
+	testFns := []gotest.RouterInfo{
+		{
+			FuncName:    "DeleteByID",
+			Method:      http.MethodDelete,
+			Path:        "/file/:id",
+			HandlerFunc: iHandler.DeleteByID,
+		},
+		{
+			FuncName:    "GetByID",
+			Method:      http.MethodGet,
+			Path:        "/file/:id",
+			HandlerFunc: asCaller(iHandler.GetByID),
+		},
+		{
+			FuncName:    "List",
+			Method:      http.MethodGet,
+			Path:        "/file/list",
+			HandlerFunc: iHandler.List,
+		},
+		{
+			FuncName:    "SignURL",
+			Method:      http.MethodGet,
+			Path:        "/file/:id/sign",
+			HandlerFunc: asCaller(iHandler.SignURL),
+		},
+		{
+			FuncName:    "Download",
+			Method:      http.MethodGet,
+			Path:        "/download/:id",
+			HandlerFunc: iHandler.Download,
+		},
+		{
+			FuncName: "DownloadBound",
+			Method:   http.MethodGet,
+			Path:     "/bound/download/:id",
+			HandlerFunc: func(c *gin.Context) {
+				// the same as the router, the account is the uid of the token
+				middlewares.AuthBoundAccount(auth.WithExtraVerify(func(claims *jwt.Claims, c *gin.Context) error {
+					c.Set("id", utils.StrToUint64(claims.UID))
+					return nil
+				}))(c)
+				if !c.IsAborted() {
+					iHandler.Download(c)
+				}
+			},
+		},
+		{
+			FuncName:    "GetGCReport",
+			Method:      http.MethodGet,
+			Path:        "/file/gc",
+			HandlerFunc: iHandler.GetGCReport,
+		},
+	}
+
+	h.GoRunHTTPServer(testFns)
+
+	time.Sleep(time.Millisecond * 200)
+	return h
+}
+
+// asCaller the account and the role code of the request are given by the headers, they are set by VerifyToken
+func asCaller(fn gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("id", utils.StrToUint64(c.GetHeader("X-Account")))
+		c.Set("roleCode", []string{c.GetHeader("X-Role")})
+		fn(c)
+	}
+}
+
+func Test_fileHandler_DeleteByID(t *testing.T) {
+	h := newFileHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.File)
+
+	// referenced files can not be deleted
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"file_id", "total"}).AddRow(testData.ID, 1))
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Delete(result, h.GetRequestURL("DeleteByID", testData.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ecode.ErrFileInUse.Code(), result.Code)
+
+	// not referenced
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"file_id", "total"}))
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "storage_key"}).AddRow(testData.ID, testData.StorageKey))
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("DELETE FROM .*").
+		WithArgs(testData.ID).
+		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", testData.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+}
+
+func Test_fileHandler_GetByID(t *testing.T) {
+	h := newFileHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.File)
+
+	rows := sqlmock.NewRows([]string{"id", "storage_key"}).
+		AddRow(testData.ID, testData.StorageKey)
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WithArgs(testData.ID, 1).
+		WillReturnRows(rows)
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"file_id", "total"}).AddRow(testData.ID, 1))
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("GetByID", testData.ID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	data := result.Data.(map[string]interface{})
+	assert.Equal(t, "/uploads/2024-11-10/a.png", data["path"])
+	assert.Equal(t, float64(1), data["refCount"])
+
+	// zero id error test
+	err = httpcli.Get(result, h.GetRequestURL("GetByID", 0))
+	assert.NoError(t, err)
+}
+
+func Test_fileHandler_List(t *testing.T) {
+	h := newFileHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.File)
+
+	rows := sqlmock.NewRows([]string{"id", "storage_key"}).
+		AddRow(testData.ID, testData.StorageKey)
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(rows)
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"file_id", "total"}))
+
+	result := &httpcli.StdResult{}
+	params := httpcli.KV{"page": 1, "pageSize": 10, "sort": "ignore count", "mimeType": "image/"}
+	err := httpcli.Get(result, h.GetRequestURL("List"), httpcli.WithParams(params))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+}
+
+func Test_fileHandler_SignURL(t *testing.T) {
+	h := newFileHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.File)
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "storage_key"}).AddRow(testData.ID, testData.StorageKey))
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("SignURL", testData.ID), httpcli.WithParams(httpcli.KV{"expires": 60, "accountID": 7}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	data := result.Data.(map[string]interface{})
+	assert.Contains(t, data["url"], "/api/v1/download/1?account=7&expires=")
+
+	// too long
+	err = httpcli.Get(result, h.GetRequestURL("SignURL", testData.ID), httpcli.WithParams(httpcli.KV{"expires": 30 * 24 * 3600}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
+
+func Test_fileHandler_SignURL_private(t *testing.T) {
+	h := newFileHandler()
+	defer h.Close()
+
+	// a private file of the account 3
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "storage_key", "private", "owner_id"}).
+			AddRow(2, "private/3/2024-11-10/b.pdf", true, 3))
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"file_id", "total"}))
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"file_id", "total"}))
+
+	as := func(account string, role string) httpcli.Option {
+		return httpcli.WithHeaders(map[string]string{"X-Account": account, "X-Role": role})
+	}
+	sign := func(accountID int, opt httpcli.Option) *httpcli.StdResult {
+		result := &httpcli.StdResult{}
+		err := httpcli.Get(result, h.GetRequestURL("SignURL", 2), httpcli.WithParams(httpcli.KV{"accountID": accountID}), opt)
+		assert.NoError(t, err)
+		return result
+	}
+
+	// the detail of another account has no url
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("GetByID", 2), as("4", ""))
+	assert.NoError(t, err)
+	if assert.Equal(t, 0, result.Code, result.Msg) {
+		assert.Empty(t, result.Data.(map[string]interface{})["url"])
+	}
+	err = httpcli.Get(result, h.GetRequestURL("GetByID", 2), as("3", ""))
+	assert.NoError(t, err)
+	if assert.Equal(t, 0, result.Code, result.Msg) {
+		assert.Contains(t, result.Data.(map[string]interface{})["url"], "account=3")
+	}
+
+	// another account can not sign it, even bound to itself
+	assert.Equal(t, ecode.Forbidden.Code(), sign(4, as("4", "")).Code)
+	assert.Equal(t, ecode.Forbidden.Code(), sign(0, as("4", "")).Code)
+	// an administrator can sign bound urls only
+	assert.Equal(t, 0, sign(4, as("4", enum.RoleCodeAdmin)).Code)
+	assert.Equal(t, ecode.Forbidden.Code(), sign(0, as("4", enum.RoleCodeAdmin)).Code)
+	// the owner can sign any url
+	result = sign(0, as("3", ""))
+	if assert.Equal(t, 0, result.Code, result.Msg) {
+		assert.NotContains(t, result.Data.(map[string]interface{})["url"], "account=")
+	}
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+}
+
+func Test_fileHandler_Download(t *testing.T) {
+	h := newFileHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.File)
+	iHandler := h.IHandler.(*fileHandler)
+
+	key := "private/2024-11-10/contract.pdf"
+	content := "%PDF-1.4 contract"
+	err := iHandler.storage.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), "application/pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "mime_type", "storage_key", "private"}).
+			AddRow(testData.ID, "合同.pdf", "application/pdf", key, true))
+
+	query := iHandler.signer.Sign(testData.ID, 0, time.Now().Add(time.Minute))
+	req, _ := http.NewRequest(http.MethodGet, h.GetRequestURL("Download", testData.ID)+"?"+query.Encode(), nil)
+	req.Header.Set("Range", "bytes=5-")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusPartialContent, resp.StatusCode)
+	assert.Equal(t, content[5:], string(body))
+	assert.Equal(t, "attachment; filename*=utf-8''%E5%90%88%E5%90%8C.pdf", resp.Header.Get("Content-Disposition"))
+
+	// invalid signature
+	query.Set(signurl.ParamExpires, "9999999999")
+	resp, err = http.Get(h.GetRequestURL("Download", testData.ID) + "?" + query.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	// bound to another account
+	query = iHandler.signer.Sign(testData.ID, 7, time.Now().Add(time.Minute))
+	resp, err = http.Get(h.GetRequestURL("Download", testData.ID) + "?" + query.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func Test_fileHandler_DownloadBound(t *testing.T) {
+	h := newFileHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.File)
+	iHandler := h.IHandler.(*fileHandler)
+	auth.InitAuth([]byte("test"), time.Hour)
+
+	key := "private/7/2024-11-10/contract.pdf"
+	content := "%PDF-1.4 contract"
+	err := iHandler.storage.Put(context.Background(), key, strings.NewReader(content), int64(len(content)), "application/pdf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := h.GetRequestURL("DownloadBound", testData.ID) + "?" + iHandler.signer.Sign(testData.ID, 7, time.Now().Add(time.Minute)).Encode()
+	download := func(setToken func(req *http.Request)) int {
+		req, _ := http.NewRequest(http.MethodGet, url, nil)
+		setToken(req)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+	expectFile := func() {
+		h.MockDao.SQLMock.ExpectQuery("SELECT .*").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "mime_type", "storage_key", "private"}).
+				AddRow(testData.ID, "合同.pdf", "application/pdf", key, true))
+	}
+	token, err := auth.GenerateToken("7")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without a token
+	assert.Equal(t, http.StatusUnauthorized, download(func(req *http.Request) {}))
+
+	// the token of the bound account in the Authorization header
+	expectFile()
+	assert.Equal(t, http.StatusOK, download(func(req *http.Request) {
+		req.Header.Set(auth.HeaderAuthorizationKey, "Bearer "+token)
+	}))
+
+	// a browser opening the link only sends the cookie set at login
+	expectFile()
+	assert.Equal(t, http.StatusOK, download(func(req *http.Request) {
+		req.AddCookie(&http.Cookie{Name: middlewares.DownloadTokenCookie, Value: token})
+	}))
+
+	// the token of another account
+	other, _ := auth.GenerateToken("8")
+	assert.Equal(t, http.StatusForbidden, download(func(req *http.Request) {
+		req.AddCookie(&http.Cookie{Name: middlewares.DownloadTokenCookie, Value: other})
+	}))
+}
+
+func Test_fileHandler_GetGCReport(t *testing.T) {
+	h := newFileHandler()
+	defer h.Close()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("GetGCReport"))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.NotFound.Code(), result.Code)
+
+	err = h.IHandler.(*fileHandler).iGCCache.SetReport(context.Background(), &types.FileGCReport{Scanned: 2, OrphanCount: 1})
+	assert.NoError(t, err)
+	err = httpcli.Get(result, h.GetRequestURL("GetGCReport"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Code != 0 {
+		t.Fatalf("%+v", result)
+	}
+	data := result.Data.(map[string]interface{})
+	assert.Equal(t, float64(1), data["orphanCount"])
+}
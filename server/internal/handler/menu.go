@@ -4,6 +4,8 @@ import (
 	"admin/internal/database"
 	"errors"
 	"github.com/huandu/xstrings"
+	"io"
+	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -18,7 +20,10 @@ import (
 	"admin/internal/dao"
 	"admin/internal/ecode"
 	"admin/internal/model"
+	"admin/internal/pkg/i18n"
+	"admin/internal/pkg/menumanifest"
 	"admin/internal/types"
+	"admin/internal/validation"
 )
 
 var _ MenuHandler = (*menuHandler)(nil)
@@ -32,8 +37,14 @@ type MenuHandler interface {
 	List(c *gin.Context)
 	Routes(c *gin.Context)
 	Options(c *gin.Context)
+	Move(c *gin.Context)
+	Export(c *gin.Context)
+	Import(c *gin.Context)
 }
 
+// menuManifestMaxSize the size limit of an imported manifest
+const menuManifestMaxSize = 1 << 20
+
 type menuHandler struct {
 	iDao dao.MenuDao
 }
@@ -50,7 +61,7 @@ func NewMenuHandler() MenuHandler {
 
 // Create a record
 // @Summary create menu
-// @Description submit information to create menu
+// @Description submit information to create menu. for a MENU, buttonPermPrefix generates the standard buttons add, edit, delete and query (or the ones in buttons) with the perms <prefix>:<action>, grantRoleIds grants the menu and the buttons to the roles, all in one transaction
 // @Tags menu
 // @accept json
 // @Produce json
@@ -63,7 +74,15 @@ func (h *menuHandler) Create(c *gin.Context) {
 	err := c.ShouldBindJSON(form)
 	if err != nil {
 		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
-		response.Error(c, ecode.InvalidParams)
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
+		return
+	}
+	if form.ButtonPermPrefix != "" && form.Type != dao.MenuTypeMenu {
+		response.Error(c, ecode.InvalidParams.WithDetails("buttonPermPrefix is only for MENU"))
+		return
+	}
+	if strings.Trim(form.ButtonPermPrefix, ":*") == "" && (form.ButtonPermPrefix != "" || len(form.Buttons) > 0) {
+		response.Error(c, ecode.InvalidParams.WithDetails("buttonPermPrefix is required to generate buttons"))
 		return
 	}
 
@@ -76,23 +95,50 @@ func (h *menuHandler) Create(c *gin.Context) {
 	// Note: if copier.Copy cannot assign a value to a field, add it here
 
 	ctx := middleware.WrapCtx(c)
-	err = h.iDao.Create(ctx, menu)
+	if form.ButtonPermPrefix == "" && len(form.GrantRoleIDs) == 0 {
+		err = h.iDao.Create(ctx, menu)
+		if err != nil {
+			logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+		response.Success(c, gin.H{"id": menu.ID})
+		return
+	}
+
+	var buttons []*model.Menu
+	if form.ButtonPermPrefix != "" {
+		buttons = dao.MenuButtons(menu, form.ButtonPermPrefix, form.Buttons)
+	}
+	err = h.iDao.CreateWithButtons(ctx, menu, buttons, form.GrantRoleIDs)
 	if err != nil {
-		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
-		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		switch {
+		case errors.Is(err, dao.ErrMenuPermExists):
+			response.Error(c, ecode.ErrMenuPermExists)
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.Error(c, ecode.NotFound.WithDetails("role"))
+		default:
+			logger.Error("CreateWithButtons error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
 		return
 	}
 
-	response.Success(c, gin.H{"id": menu.ID})
+	buttonIDs := make([]uint64, 0, len(buttons))
+	for _, button := range buttons {
+		buttonIDs = append(buttonIDs, button.ID)
+	}
+	response.Success(c, gin.H{"id": menu.ID, "buttonIds": buttonIDs})
 }
 
 // DeleteByID delete a record by id
 // @Summary delete menu
-// @Description delete menu by id
+// @Description delete menus by id, separated by commas. the menus with descendants or role assignments are refused with the dependents in data, unless cascade is true, then the descendants and the role assignments are deleted too
 // @Tags menu
 // @accept json
 // @Produce json
 // @Param id path string true "id"
+// @Param cascade query bool false "also delete the descendants and the role assignments"
 // @Success 200 {object} types.DeleteMenuByIDReply{}
 // @Router /api/v1/menu/{id} [delete]
 // @Security BearerAuth
@@ -102,6 +148,13 @@ func (h *menuHandler) DeleteByID(c *gin.Context) {
 		response.Error(c, ecode.InvalidParams)
 		return
 	}
+	form := &types.DeleteMenuByIDRequest{}
+	err := c.ShouldBindQuery(form)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
+		return
+	}
 
 	var ids []uint64
 	for _, v := range strings.Split(idStr, ",") {
@@ -109,12 +162,20 @@ func (h *menuHandler) DeleteByID(c *gin.Context) {
 	}
 
 	ctx := middleware.WrapCtx(c)
-	err := h.iDao.DeleteByIDs(ctx, ids)
+	dependents, err := h.iDao.DeleteTree(ctx, ids, form.Cascade)
 	if err != nil {
-		logger.Error("DeleteByID error", logger.Err(err), logger.Any("id", idStr), middleware.GCtxRequestIDField(c))
+		if errors.Is(err, dao.ErrMenuDependents) {
+			response.Error(c, ecode.ErrMenuDependents, dependents)
+			return
+		}
+		logger.Error("DeleteTree error", logger.Err(err), logger.Any("id", idStr), middleware.GCtxRequestIDField(c))
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
 		return
 	}
+	if form.Cascade && !dependents.Empty() {
+		logger.Info("menus were deleted with their dependents", logger.Any("operator", c.GetUint64("id")),
+			logger.Any("id", idStr), logger.Any("dependents", dependents), middleware.GCtxRequestIDField(c))
+	}
 
 	response.Success(c)
 }
@@ -248,7 +309,7 @@ func (h *menuHandler) List(c *gin.Context) {
 	response.Success(c, data)
 }
 
-// Routes of records routes
+// Routes of records routes, the titles are in the locale of the request
 // @Summary list of routes
 // @Description list routes
 // @Tags menu
@@ -260,7 +321,7 @@ func (h *menuHandler) List(c *gin.Context) {
 func (h *menuHandler) Routes(c *gin.Context) {
 	ctx := middleware.WrapCtx(c)
 	roleIds, _ := c.Get("roleId")
-	result, err := h.iDao.Routes(ctx, roleIds.(types.LocalIntArray))
+	result, err := h.iDao.Routes(ctx, roleIds.(types.LocalIntArray), i18n.Get(c))
 	if err != nil {
 		logger.Error("Routes error", logger.Err(err), logger.Any("roleIds", roleIds), middleware.GCtxRequestIDField(c))
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
@@ -293,6 +354,141 @@ func (h *menuHandler) Options(c *gin.Context) {
 	response.Success(c, options)
 }
 
+// Move menus with their subtrees
+// @Summary move menus
+// @Description move menus with their subtrees to new positions in order, e.g. after a drag and drop in the menu tree, the sort values of the siblings are renumbered, a parent must be a catalog or a menu
+// @Tags menu
+// @accept json
+// @Produce json
+// @Param data body types.MoveMenusRequest true "moves"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/menu/move [put]
+// @Security BearerAuth
+func (h *menuHandler) Move(c *gin.Context) {
+	form := &types.MoveMenusRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	err = h.iDao.Move(ctx, form.Moves)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrRecordNotFound):
+			response.Error(c, ecode.NotFound)
+		case errors.Is(err, dao.ErrMenuCycle):
+			response.Error(c, ecode.ErrMenuMoveCycle)
+		case errors.Is(err, dao.ErrMenuParentType):
+			response.Error(c, ecode.ErrMenuParentType)
+		default:
+			logger.Error("Move error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrMoveMenu)
+		}
+		return
+	}
+
+	response.Success(c)
+}
+
+// Export the menu tree
+// @Summary export menus
+// @Description export the whole menu tree with the perms and the params as a json or yaml manifest, ids are not exported, the menus which can not be matched when imported again are listed in skipped
+// @Tags menu
+// @Param format query string false "json or yaml, default json"
+// @Produce json,application/yaml
+// @Success 200 {object} types.MenuManifest{}
+// @Router /api/v1/menu/export [get]
+// @Security BearerAuth
+func (h *menuHandler) Export(c *gin.Context) {
+	form := &types.ExportMenusRequest{}
+	err := c.ShouldBindQuery(form)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
+		return
+	}
+	if form.Format == "" {
+		form.Format = menumanifest.FormatJSON
+	}
+
+	ctx := middleware.WrapCtx(c)
+	manifest, err := h.iDao.Export(ctx)
+	if err != nil {
+		logger.Error("Export error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrExportMenu)
+		return
+	}
+	data, err := menumanifest.Encode(manifest, form.Format)
+	if err != nil {
+		logger.Error("menumanifest.Encode error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrExportMenu)
+		return
+	}
+
+	contentType := "application/json; charset=utf-8"
+	if form.Format == menumanifest.FormatYAML {
+		contentType = "application/yaml; charset=utf-8"
+	}
+	c.Header("Content-Disposition", contentDisposition("menus."+form.Format))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// Import menus from a manifest
+// @Summary import menus
+// @Description upsert the menus of an exported manifest, the catalogs, menus and external links are matched by path and the buttons by perm under their parent. the matched menus keep their ids and role assignments, the menus not in the manifest are kept. it only reports the differences by default, set dryRun=false to write them
+// @Tags menu
+// @accept json,application/yaml
+// @Produce json
+// @Param format query string false "json or yaml, default json"
+// @Param dryRun query bool false "only report the differences, default true"
+// @Param data body types.MenuManifest true "manifest"
+// @Success 200 {object} types.ImportMenusReply{}
+// @Router /api/v1/menu/import [post]
+// @Security BearerAuth
+func (h *menuHandler) Import(c *gin.Context) {
+	form := &types.ImportMenusRequest{}
+	err := c.ShouldBindQuery(form)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
+		return
+	}
+	dryRun := true
+	if form.DryRun != nil {
+		dryRun = *form.DryRun
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, menuManifestMaxSize))
+	if err != nil {
+		logger.Warn("read manifest error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrMenuManifest.WithDetails(err.Error()))
+		return
+	}
+	manifest, err := menumanifest.Decode(data, form.Format)
+	if err != nil {
+		logger.Warn("menumanifest.Decode error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrMenuManifest.WithDetails(err.Error()))
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	report, err := h.iDao.Import(ctx, manifest, dryRun)
+	if err != nil {
+		logger.Error("Import error", logger.Err(err), logger.Bool("dryRun", dryRun), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrImportMenu)
+		return
+	}
+	if !dryRun {
+		logger.Info("menus were imported", logger.Any("operator", c.GetUint64("id")),
+			logger.Int("created", len(report.Created)), logger.Int("updated", len(report.Updated)), middleware.GCtxRequestIDField(c))
+	}
+
+	response.Success(c, report)
+}
+
 func getMenuIDFromPath(c *gin.Context) (string, uint64, bool) {
 	idStr := c.Param("id")
 	id, err := utils.StrToUint64E(idStr)
@@ -16,6 +16,7 @@ import (
 
 	"admin/internal/cache"
 	"admin/internal/dao"
+	"admin/internal/ecode"
 	"admin/internal/model"
 	"admin/internal/types"
 )
@@ -74,6 +75,24 @@ func newMenuHandler() *gotest.Handler {
 			Path:        "/menu/list",
 			HandlerFunc: iHandler.List,
 		},
+		{
+			FuncName:    "Move",
+			Method:      http.MethodPut,
+			Path:        "/menu/move",
+			HandlerFunc: iHandler.Move,
+		},
+		{
+			FuncName:    "Export",
+			Method:      http.MethodGet,
+			Path:        "/menu/export",
+			HandlerFunc: iHandler.Export,
+		},
+		{
+			FuncName:    "Import",
+			Method:      http.MethodPost,
+			Path:        "/menu/import",
+			HandlerFunc: iHandler.Import,
+		},
 	}
 
 	h.GoRunHTTPServer(testFns)
@@ -103,42 +122,92 @@ func Test_menuHandler_Create(t *testing.T) {
 
 	t.Logf("%+v", result)
 
+	// with the generated buttons
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT count.*").
+		WithArgs("sys:config:add", "sys:config:edit", "sys:config:delete", "sys:config:query").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	for id := 20; id <= 24; id++ {
+		h.MockDao.SQLMock.ExpectExec("INSERT INTO .*").WillReturnResult(sqlmock.NewResult(int64(id), 1))
+	}
+	h.MockDao.SQLMock.ExpectCommit()
+	err = httpcli.Post(result, h.GetRequestURL("Create"), &types.CreateMenuRequest{
+		Name: "配置管理", Type: "MENU", Path: "system/config", ButtonPermPrefix: "sys:config:*",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Code, result.Msg)
+	assert.Len(t, result.Data.(map[string]interface{})["buttonIds"], 4)
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+
+	// buttons are only for MENU
+	err = httpcli.Post(result, h.GetRequestURL("Create"), &types.CreateMenuRequest{
+		Name: "系统管理", Type: "CATALOG", ButtonPermPrefix: "sys",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
 }
 
 func Test_menuHandler_DeleteByID(t *testing.T) {
 	h := newMenuHandler()
 	defer h.Close()
 	testData := h.TestData.(*model.Menu)
-	expectedSQLForDeletion := "UPDATE .*"
-	expectedArgsForDeletionTime := h.MockDao.AnyTime
 
+	menuRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "parent_id", "name", "type"}).
+			AddRow(1, 0, "系统管理", "CATALOG").
+			AddRow(2, 1, "菜单管理", "MENU").
+			AddRow(3, 0, "日志", "CATALOG")
+	}
+	linkRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "role_id", "menu_id", "role_name"}).
+			AddRow(10, 1, 1, "超级管理员").
+			AddRow(11, 1, 2, "超级管理员")
+	}
+
+	// no dependents
 	h.MockDao.SQLMock.ExpectBegin()
-	h.MockDao.SQLMock.ExpectExec(expectedSQLForDeletion).
-		WithArgs(expectedArgsForDeletionTime, testData.ID). // adjusted for the amount of test data
-		WillReturnResult(sqlmock.NewResult(int64(testData.ID), 1))
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(menuRows())
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(sqlmock.NewRows([]string{"id", "role_id", "menu_id", "role_name"}))
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(h.MockDao.AnyTime, uint64(3)).
+		WillReturnResult(sqlmock.NewResult(3, 1))
 	h.MockDao.SQLMock.ExpectCommit()
 
 	result := &httpcli.StdResult{}
-	err := httpcli.Delete(result, h.GetRequestURL("DeleteByID", testData.ID))
+	err := httpcli.Delete(result, h.GetRequestURL("DeleteByID", 3))
 	if err != nil {
 		t.Fatal(err)
 	}
-	if result.Code != 0 {
-		t.Fatalf("%+v", result)
-	}
+	assert.Equal(t, 0, result.Code, result.Msg)
 
-	// zero id error test
-	//err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", 0))
-	//assert.NoError(t, err)
+	// refused with the dependents
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(menuRows())
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(linkRows())
+	h.MockDao.SQLMock.ExpectRollback()
+	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", testData.ID))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrMenuDependents.Code(), result.Code)
+	dependents := result.Data.(map[string]interface{})
+	assert.Len(t, dependents["descendants"], 1)
+	assert.Len(t, dependents["roles"], 1)
 
-	// delete error test - 为错误测试添加mock期望
+	// cascade
 	h.MockDao.SQLMock.ExpectBegin()
-	h.MockDao.SQLMock.ExpectExec(expectedSQLForDeletion).
-		WithArgs(expectedArgsForDeletionTime, uint64(111)).
-		WillReturnResult(sqlmock.NewResult(111, 1))
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(menuRows())
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(linkRows())
+	// the role links are removed for good like SyncByRoleID, the joins of the permissions do not skip soft deleted links
+	h.MockDao.SQLMock.ExpectExec("DELETE FROM `t_role_menu` .*").
+		WithArgs(uint64(10), uint64(11)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	h.MockDao.SQLMock.ExpectExec("UPDATE `t_menu` .*").
+		WithArgs(h.MockDao.AnyTime, uint64(1), uint64(2)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
 	h.MockDao.SQLMock.ExpectCommit()
-	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", 111))
+	err = httpcli.Delete(result, h.GetRequestURL("DeleteByID", testData.ID)+"?cascade=true")
 	assert.NoError(t, err)
+	assert.Equal(t, 0, result.Code, result.Msg)
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
 }
 
 func Test_menuHandler_UpdateByID(t *testing.T) {
@@ -269,3 +338,120 @@ func TestNewMenuHandler(t *testing.T) {
 	}()
 	_ = NewMenuHandler()
 }
+
+func Test_menuHandler_Move(t *testing.T) {
+	h := newMenuHandler()
+	defer h.Close()
+
+	menuRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "parent_id", "type", "sort"}).
+			AddRow(1, 0, "CATALOG", 1).
+			AddRow(2, 1, "MENU", 1).
+			AddRow(3, 1, "MENU", 2).
+			AddRow(4, 2, "BUTTON", 1)
+	}
+
+	// move 3 to the root before 1
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(menuRows())
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(0, 2, h.MockDao.AnyTime, 1).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	h.MockDao.SQLMock.ExpectExec("UPDATE .*").
+		WithArgs(0, 1, h.MockDao.AnyTime, 3).
+		WillReturnResult(sqlmock.NewResult(3, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Put(result, h.GetRequestURL("Move"), &types.MoveMenusRequest{
+		Moves: []*types.MoveMenuItem{{ID: 3, ParentID: 0, Index: 0}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, result.Code, result.Msg)
+
+	// cycle
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(menuRows())
+	h.MockDao.SQLMock.ExpectRollback()
+	err = httpcli.Put(result, h.GetRequestURL("Move"), &types.MoveMenusRequest{
+		Moves: []*types.MoveMenuItem{{ID: 1, ParentID: 2}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrMenuMoveCycle.Code(), result.Code)
+
+	// under a button
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(menuRows())
+	h.MockDao.SQLMock.ExpectRollback()
+	err = httpcli.Put(result, h.GetRequestURL("Move"), &types.MoveMenusRequest{
+		Moves: []*types.MoveMenuItem{{ID: 3, ParentID: 4}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrMenuParentType.Code(), result.Code)
+
+	// no moves
+	err = httpcli.Put(result, h.GetRequestURL("Move"), &types.MoveMenusRequest{})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+}
+
+func Test_menuHandler_Export(t *testing.T) {
+	h := newMenuHandler()
+	defer h.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "parent_id", "name", "type", "path", "sort"}).
+		AddRow(1, 0, "系统管理", "CATALOG", "/system", 1)
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+
+	manifest := &types.MenuManifest{}
+	err := httpcli.Get(manifest, h.GetRequestURL("Export"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, manifest.Menus, 1) {
+		assert.Equal(t, "/system", manifest.Menus[0].Path)
+	}
+
+	result := &httpcli.StdResult{}
+	err = httpcli.Get(result, h.GetRequestURL("Export"), httpcli.WithParams(map[string]interface{}{"format": "xml"}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
+
+func Test_menuHandler_Import(t *testing.T) {
+	h := newMenuHandler()
+	defer h.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "parent_id", "name", "type", "path", "sort", "visible", "keep_alive"}).
+		AddRow(1, 0, "系统管理", "CATALOG", "/system", 1, 1, 1)
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT .*").WillReturnRows(rows)
+	h.MockDao.SQLMock.ExpectCommit()
+
+	// dry run by default
+	result := &httpcli.StdResult{}
+	err := httpcli.Post(result, h.GetRequestURL("Import"), &types.MenuManifest{Menus: []*types.MenuManifestItem{
+		{Name: "系统管理", Type: "CATALOG", Path: "/system", Children: []*types.MenuManifestItem{
+			{Name: "新增", Type: "BUTTON", Perm: "sys:a"},
+		}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, result.Code, result.Msg)
+	report := result.Data.(map[string]interface{})
+	assert.Equal(t, true, report["dryRun"])
+	assert.Len(t, report["created"], 1)
+	assert.EqualValues(t, 1, report["unchanged"])
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+
+	// invalid manifest
+	err = httpcli.Post(result, h.GetRequestURL("Import"), &types.MenuManifest{Menus: []*types.MenuManifestItem{
+		{Name: "系统管理", Type: "CATALOG"},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrMenuManifest.Code(), result.Code)
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"admin/internal/cache"
+	"admin/internal/dao"
+	"admin/internal/database"
+	"admin/internal/ecode"
+	"admin/internal/pkg/routeperm"
+	"admin/internal/types"
+	"admin/internal/validation"
+)
+
+var _ PermissionHandler = (*permissionHandler)(nil)
+
+// PermissionHandler defining the handler interface
+type PermissionHandler interface {
+	Routes(c *gin.Context)
+	Explain(c *gin.Context)
+}
+
+type permissionHandler struct {
+	iMenuDao       dao.MenuDao
+	iPermissionDao dao.PermissionDao
+	routes         func() gin.RoutesInfo // the routes of the engine
+}
+
+// NewPermissionHandler creating the handler interface
+func NewPermissionHandler() PermissionHandler {
+	return &permissionHandler{
+		iMenuDao: dao.NewMenuDao(
+			database.GetDB(), // db driver is mysql
+			cache.NewMenuCache(database.GetCacheType()),
+		),
+		iPermissionDao: dao.NewPermissionDao(database.GetDB()),
+		routes:         routeperm.Routes,
+	}
+}
+
+// Routes list every route with its auth mode and perm
+// @Summary list the route permissions
+// @Description list every route with its auth mode and the perm it requires, flag the routes without a perm, the perms no menu has and the perms of the menus no route requires
+// @Tags permission
+// @Produce json
+// @Success 200 {object} types.RoutePermReportReply{}
+// @Router /api/v1/permission/routes [get]
+// @Security BearerAuth
+func (h *permissionHandler) Routes(c *gin.Context) {
+	ctx := middleware.WrapCtx(c)
+	perms, err := h.iMenuDao.Perms(ctx)
+	if err != nil {
+		logger.Error("Perms error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrRoutePermReport)
+		return
+	}
+
+	response.Success(c, routeperm.Report(h.routes(), perms))
+}
+
+// Explain the effective perms of a platform and why a perm is granted or missing
+// @Summary explain the permissions of a platform
+// @Description list the effective perms of a platform, for each perm the roles and menus granting it, or why it is missing: not_found or not_assigned, disabled roles, hidden menus and deleted menus still grant, their roleStatus, menuVisible and menuDeleted are returned with every grant
+// @Tags permission
+// @Produce json
+// @Param request query types.ExplainPermissionRequest true "query parameters"
+// @Success 200 {object} types.ExplainPermissionReply{}
+// @Router /api/v1/permission/explain [get]
+// @Security BearerAuth
+func (h *permissionHandler) Explain(c *gin.Context) {
+	request := &types.ExplainPermissionRequest{}
+	err := c.ShouldBindQuery(request)
+	if err != nil {
+		logger.Warn("ShouldBindQuery error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	report, err := h.iPermissionDao.Explain(ctx, request)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			logger.Warn("Explain not found", logger.Err(err), logger.Any("platformId", request.PlatformID), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.NotFound)
+			return
+		}
+		logger.Error("Explain error", logger.Err(err), logger.Any("request", request), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrExplainPermission)
+		return
+	}
+
+	response.Success(c, report)
+}
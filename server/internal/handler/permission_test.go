@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/httpcli"
+
+	"admin/internal/dao"
+	"admin/internal/ecode"
+	"admin/internal/model"
+	"admin/internal/pkg/routeperm"
+)
+
+func newPermissionHandler() *gotest.Handler {
+	testData := &model.Menu{}
+	testData.ID = 1
+
+	c := gotest.NewCache(map[string]interface{}{})
+	d := gotest.NewDao(c, testData)
+	d.IDao = dao.NewMenuDao(d.DB, nil)
+
+	h := gotest.NewHandler(d, testData)
+	ph := &permissionHandler{
+		iMenuDao:       d.IDao.(dao.MenuDao),
+		iPermissionDao: dao.NewPermissionDao(d.DB),
+	}
+	h.IHandler = ph
+	iHandler := h.IHandler.(PermissionHandler)
+
+	testFns := []gotest.RouterInfo{
+		{
+			FuncName:    "Routes",
+			Method:      http.MethodGet,
+			Path:        "/permission/routes",
+			HandlerFunc: iHandler.Routes,
+		},
+		{
+			FuncName:    "Explain",
+			Method:      http.MethodGet,
+			Path:        "/permission/explain",
+			HandlerFunc: iHandler.Explain,
+		},
+	}
+
+	h.GoRunHTTPServer(testFns)
+	// the routes of the test server, the route is declared as the routers do
+	r := h.HTTPServer.Handler.(*gin.Engine)
+	ph.routes = r.Routes
+	routeperm.Declare(&r.RouterGroup, routeperm.Perm(http.MethodGet, "/permission/routes", "sys:permission:query"))
+
+	time.Sleep(time.Millisecond * 200)
+	return h
+}
+
+func Test_permissionHandler_Routes(t *testing.T) {
+	h := newPermissionHandler()
+	defer h.Close()
+
+	rows := sqlmock.NewRows([]string{"perm"}).AddRow("sys:menu:add").AddRow("sys:permission:query")
+	h.MockDao.SQLMock.ExpectQuery("SELECT DISTINCT .*").WillReturnRows(rows)
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("Routes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, result.Code)
+	data := result.Data.(map[string]interface{})
+	routes := data["routes"].([]interface{})
+	if assert.Len(t, routes, 2) {
+		route := routes[1].(map[string]interface{})
+		assert.Equal(t, "/permission/routes", route["path"])
+		assert.Equal(t, routeperm.AuthPerm, route["auth"])
+		assert.Equal(t, true, route["permInMenus"])
+	}
+	assert.Equal(t, []interface{}{"sys:menu:add"}, data["unusedPerms"])
+
+	// query error
+	h.MockDao.SQLMock.ExpectQuery("SELECT DISTINCT .*").WillReturnError(errors.New("error"))
+	err = httpcli.Get(result, h.GetRequestURL("Routes"))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.ErrRoutePermReport.Code(), result.Code)
+}
+
+func Test_permissionHandler_Explain(t *testing.T) {
+	h := newPermissionHandler()
+	defer h.Close()
+
+	h.MockDao.SQLMock.ExpectQuery("SELECT .* FROM `t_platform` .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username", "role_id", "status"}).AddRow(1, "admin", "[1]", 1))
+	h.MockDao.SQLMock.ExpectQuery("SELECT \\* FROM `t_role` .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "code", "status"}).AddRow(1, "访客", "GUEST", 0))
+	h.MockDao.SQLMock.ExpectQuery("SELECT t_role_menu.role_id.*").
+		WillReturnRows(sqlmock.NewRows([]string{"role_id", "menu_id", "menu_name", "perm", "visible", "menu_deleted"}).
+			AddRow(1, 3, "菜单新增", "sys:menu:add", 0, false))
+	h.MockDao.SQLMock.ExpectQuery("SELECT .* FROM `t_menu` .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "perm"}).AddRow(3, "菜单新增", "sys:menu:add"))
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Get(result, h.GetRequestURL("Explain"), httpcli.WithParams(map[string]interface{}{
+		"platformId": 1,
+		"perm":       "sys:menu:add",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, result.Code)
+	data := result.Data.(map[string]interface{})
+	// a disabled role grants its perms like GetPermissionsByIds
+	assert.Equal(t, []interface{}{"sys:menu:add"}, data["perms"])
+	explanation := data["explanations"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, true, explanation["granted"])
+	assert.Empty(t, explanation["reason"])
+	grant := explanation["grantedBy"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, float64(0), grant["roleStatus"])
+	assert.Equal(t, float64(0), grant["menuVisible"])
+
+	// the platform does not exist
+	h.MockDao.SQLMock.ExpectQuery("SELECT .* FROM `t_platform` .*").WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	err = httpcli.Get(result, h.GetRequestURL("Explain"), httpcli.WithParams(map[string]interface{}{"platformId": 9}))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.NotFound.Code(), result.Code)
+
+	// the platform id is required
+	err = httpcli.Get(result, h.GetRequestURL("Explain"))
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"admin/internal/constant"
 	"admin/internal/database"
+	"admin/internal/pkg/util"
 	"encoding/base64"
 	"errors"
 	"strings"
@@ -22,6 +23,7 @@ import (
 	"admin/internal/ecode"
 	"admin/internal/model"
 	"admin/internal/types"
+	"admin/internal/validation"
 )
 
 var _ PlatformHandler = (*platformHandler)(nil)
@@ -44,6 +46,7 @@ type platformHandler struct {
 	iDao       dao.PlatformDao
 	iRoleDao   dao.RoleDao
 	iConfigDao dao.ConfigDao
+	iFileDao   dao.FileDao
 }
 
 // NewPlatformHandler creating the handler interface
@@ -61,6 +64,10 @@ func NewPlatformHandler() PlatformHandler {
 			database.GetDB(),
 			cache.NewConfigCache(database.GetCacheType()),
 		),
+		iFileDao: dao.NewFileDao(
+			database.GetDB(),
+			cache.NewFileCache(database.GetCacheType()),
+		),
 	}
 }
 
@@ -79,7 +86,7 @@ func (h *platformHandler) Create(c *gin.Context) {
 	err := c.ShouldBindJSON(form)
 	if err != nil {
 		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
-		response.Error(c, ecode.InvalidParams)
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
 		return
 	}
 
@@ -99,6 +106,7 @@ func (h *platformHandler) Create(c *gin.Context) {
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
 		return
 	}
+	h.syncAvatarRef(c, platform)
 
 	response.Success(c, gin.H{"id": platform.ID})
 }
@@ -132,6 +140,9 @@ func (h *platformHandler) DeleteByID(c *gin.Context) {
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
 		return
 	}
+	if err = h.iFileDao.DeleteRefs(ctx, constant.FileRefPlatformAvatar, ids); err != nil {
+		logger.Warn("DeleteRefs error", logger.Err(err), logger.Any("id", idStr), middleware.GCtxRequestIDField(c))
+	}
 
 	response.Success(c)
 }
@@ -158,7 +169,7 @@ func (h *platformHandler) UpdateByID(c *gin.Context) {
 	err := c.ShouldBindJSON(form)
 	if err != nil {
 		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
-		response.Error(c, ecode.InvalidParams)
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
 		return
 	}
 	form.ID = id
@@ -179,6 +190,7 @@ func (h *platformHandler) UpdateByID(c *gin.Context) {
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
 		return
 	}
+	h.syncAvatarRef(c, platform)
 
 	response.Success(c)
 }
@@ -307,6 +319,18 @@ func (h *platformHandler) Me(c *gin.Context) {
 	response.Success(c, reply)
 }
 
+// syncAvatarRef record which uploaded file is used as the avatar, the avatar is not changed if it is empty
+func (h *platformHandler) syncAvatarRef(c *gin.Context, platform *model.Platform) {
+	if platform.Avatar == "" {
+		return
+	}
+	key := util.ImagePathKey(platform.Avatar, constant.UploadPathPrefix)
+	err := h.iFileDao.SyncRefs(middleware.WrapCtx(c), constant.FileRefPlatformAvatar, platform.ID, key)
+	if err != nil {
+		logger.Warn("SyncRefs error", logger.Err(err), logger.Any("id", platform.ID), middleware.GCtxRequestIDField(c))
+	}
+}
+
 func getPlatformIDFromPath(c *gin.Context) (string, uint64, bool) {
 	idStr := c.Param("id")
 	id, err := utils.StrToUint64E(idStr)
@@ -450,7 +474,7 @@ func (h *platformHandler) UpdateProfile(c *gin.Context) {
 	err := c.ShouldBindJSON(form)
 	if err != nil {
 		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
-		response.Error(c, ecode.InvalidParams)
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
 		return
 	}
 	form.ID = c.GetUint64("id")
@@ -470,6 +494,7 @@ func (h *platformHandler) UpdateProfile(c *gin.Context) {
 		response.Output(c, ecode.InternalServerError.ToHTTPCode())
 		return
 	}
+	h.syncAvatarRef(c, platform)
 
 	response.Success(c)
 }
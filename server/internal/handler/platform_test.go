@@ -16,6 +16,7 @@ import (
 
 	"admin/internal/cache"
 	"admin/internal/dao"
+	"admin/internal/ecode"
 	"admin/internal/model"
 	"admin/internal/types"
 )
@@ -46,6 +47,7 @@ func newPlatformHandler() *gotest.Handler {
 	h.IHandler = &platformHandler{
 		iDao:     d.IDao.(dao.PlatformDao),
 		iRoleDao: roleDao,
+		iFileDao: dao.NewFileDao(d.DB, nil),
 	}
 	iHandler := h.IHandler.(PlatformHandler)
 
@@ -109,6 +111,12 @@ func Test_platformHandler_Create(t *testing.T) {
 
 	t.Logf("%+v", result)
 
+	// gender out of the enum
+	gender := 5
+	err = httpcli.Post(result, h.GetRequestURL("Create"), &types.CreatePlatformRequest{Username: "test", Gender: &gender})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+	assert.Contains(t, result.Msg, "gender must be one of 1(男), 2(女), 0(保密)")
 }
 
 func Test_platformHandler_DeleteByID(t *testing.T) {
@@ -19,8 +19,12 @@ import (
 	"admin/internal/ecode"
 	"admin/internal/model"
 	"admin/internal/types"
+	"admin/internal/validation"
 )
 
+// audit action of the role menu assignment
+const auditRoleMenus = "role.menus"
+
 var _ RoleHandler = (*roleHandler)(nil)
 
 // RoleHandler defining the handler interface
@@ -38,6 +42,7 @@ type RoleHandler interface {
 type roleHandler struct {
 	iDao         dao.RoleDao
 	iRoleMenuDao dao.RoleMenuDao
+	iAuditDao    dao.AuditLogDao
 }
 
 // NewRoleHandler creating the handler interface
@@ -51,6 +56,7 @@ func NewRoleHandler() RoleHandler {
 			database.GetDB(),
 			cache.NewRoleMenuCache(database.GetCacheType()),
 		),
+		iAuditDao: dao.NewAuditLogDao(database.GetDB()),
 	}
 }
 
@@ -69,7 +75,7 @@ func (h *roleHandler) Create(c *gin.Context) {
 	err := c.ShouldBindJSON(form)
 	if err != nil {
 		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
-		response.Error(c, ecode.InvalidParams)
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
 		return
 	}
 
@@ -147,7 +153,7 @@ func (h *roleHandler) UpdateByID(c *gin.Context) {
 	err := c.ShouldBindJSON(form)
 	if err != nil {
 		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
-		response.Error(c, ecode.InvalidParams)
+		response.Error(c, ecode.InvalidParams.WithDetails(validation.Translate(err)))
 		return
 	}
 	form.ID = id
@@ -344,16 +350,17 @@ func (h *roleHandler) MenuIds(c *gin.Context) {
 
 // Menus update permission
 // @Summary update permission
-// @Description update permission
+// @Description replace the menus of a role, only the changed links are written and the changes are returned and audited
 // @Tags role
 // @accept json
 // @Produce json
 // @Param id path string true "id"
-// @Success 200 {object} types.UpdateRoleByIDReply{}
+// @Param data body []uint64 true "menu ids"
+// @Success 200 {object} types.UpdateRoleMenusReply{}
 // @Router /api/v1/role/{id}/menus [put]
 // @Security BearerAuth
 func (h *roleHandler) Menus(c *gin.Context) {
-	_, id, isAbort := getRoleIDFromPath(c)
+	idStr, id, isAbort := getRoleIDFromPath(c)
 	if isAbort {
 		response.Error(c, ecode.InvalidParams)
 		return
@@ -372,12 +379,21 @@ func (h *roleHandler) Menus(c *gin.Context) {
 	}
 
 	ctx := middleware.WrapCtx(c)
-	err = h.iRoleMenuDao.UpdateByRoleIds(ctx, id, menuIds)
+	changes, err := h.iRoleMenuDao.SyncByRoleID(ctx, id, menuIds)
 	if err != nil {
-		logger.Error("UpdateByRoleId error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
-		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		writeAudit(c, h.iAuditDao, auditRoleMenus, "role:"+idStr, "", err)
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.NotFound)
+			return
+		}
+		logger.Error("SyncByRoleID error", logger.Err(err), logger.Any("id", id), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUpdateByIDRoleMenu)
 		return
 	}
+	if changes.Changed() {
+		detail, _ := json.Marshal(changes)
+		writeAudit(c, h.iAuditDao, auditRoleMenus, "role:"+idStr, string(detail), nil)
+	}
 
-	response.Success(c)
+	response.Success(c, changes)
 }
@@ -16,6 +16,7 @@ import (
 
 	"admin/internal/cache"
 	"admin/internal/dao"
+	"admin/internal/ecode"
 	"admin/internal/model"
 	"admin/internal/types"
 )
@@ -40,7 +41,11 @@ func newRoleHandler() *gotest.Handler {
 
 	// init mock handler
 	h := gotest.NewHandler(d, testData)
-	h.IHandler = &roleHandler{iDao: d.IDao.(dao.RoleDao)}
+	h.IHandler = &roleHandler{
+		iDao:         d.IDao.(dao.RoleDao),
+		iRoleMenuDao: dao.NewRoleMenuDao(d.DB, nil),
+		iAuditDao:    dao.NewAuditLogDao(d.DB),
+	}
 	iHandler := h.IHandler.(RoleHandler)
 
 	testFns := []gotest.RouterInfo{
@@ -74,6 +79,12 @@ func newRoleHandler() *gotest.Handler {
 			Path:        "/role/list",
 			HandlerFunc: iHandler.List,
 		},
+		{
+			FuncName:    "Menus",
+			Method:      http.MethodPut,
+			Path:        "/role/:id/menus",
+			HandlerFunc: iHandler.Menus,
+		},
 	}
 
 	h.GoRunHTTPServer(testFns)
@@ -234,3 +245,45 @@ func TestNewRoleHandler(t *testing.T) {
 	}()
 	_ = NewRoleHandler()
 }
+
+func Test_roleHandler_Menus(t *testing.T) {
+	h := newRoleHandler()
+	defer h.Close()
+
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT `id` FROM `t_role` .* FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	h.MockDao.SQLMock.ExpectQuery("SELECT `id`,`menu_id` FROM `t_role_menu` .*").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "menu_id"}).AddRow(1, 1).AddRow(2, 2))
+	h.MockDao.SQLMock.ExpectQuery("SELECT count.*").WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	h.MockDao.SQLMock.ExpectExec("DELETE FROM `t_role_menu` .*").WillReturnResult(sqlmock.NewResult(0, 1))
+	h.MockDao.SQLMock.ExpectExec("INSERT INTO `t_role_menu`.*").WillReturnResult(sqlmock.NewResult(3, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+	expectAudit(h)
+
+	result := &httpcli.StdResult{}
+	err := httpcli.Put(result, h.GetRequestURL("Menus", 1), []uint64{1, 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, result.Code)
+	data := result.Data.(map[string]interface{})
+	assert.Equal(t, []interface{}{float64(3)}, data["added"])
+	assert.Equal(t, []interface{}{float64(2)}, data["removed"])
+
+	// the role does not exist
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectQuery("SELECT `id` FROM `t_role` .* FOR UPDATE").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	h.MockDao.SQLMock.ExpectRollback()
+	expectAudit(h)
+
+	err = httpcli.Put(result, h.GetRequestURL("Menus", 9), []uint64{1})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.NotFound.Code(), result.Code)
+
+	// invalid body
+	err = httpcli.Put(result, h.GetRequestURL("Menus", 1), map[string]interface{}{"menuIds": 1})
+	assert.NoError(t, err)
+	assert.Equal(t, ecode.InvalidParams.Code(), result.Code)
+}
@@ -6,27 +6,49 @@ import (
 	"admin/internal/dao"
 	"admin/internal/database"
 	"admin/internal/ecode"
+	"admin/internal/model"
+	"admin/internal/pkg/imageproc"
+	"admin/internal/pkg/signurl"
+	"admin/internal/pkg/storage"
+	"admin/internal/pkg/upload"
+	"admin/internal/pkg/util"
 	"admin/internal/types"
-	"fmt"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"io"
-	"os"
+	"net/http"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-dev-frame/sponge/pkg/errcode"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
 	"github.com/go-dev-frame/sponge/pkg/gin/response"
-	"github.com/go-dev-frame/sponge/pkg/gocrypto"
-	"github.com/go-dev-frame/sponge/pkg/gofile"
+	"github.com/go-dev-frame/sponge/pkg/logger"
 )
 
 type UploadHandler interface {
 	Local(c *gin.Context)
+	Serve(c *gin.Context)
+
+	InitiateSession(c *gin.Context)
+	GetSession(c *gin.Context)
+	UploadChunk(c *gin.Context)
+	CompleteSession(c *gin.Context)
+	AbortSession(c *gin.Context)
 }
 
 type uploadHandler struct {
-	iConfigDao dao.ConfigDao
+	iConfigDao        dao.ConfigDao
+	iFileDao          dao.FileDao
+	iUploadSessionDao dao.UploadSessionDao
+	storage           storage.Storage
+	signer            *signurl.Signer
 }
 
 func NewUploadHandler() UploadHandler {
@@ -35,61 +57,351 @@ func NewUploadHandler() UploadHandler {
 			database.GetDB(),
 			cache.NewConfigCache(database.GetCacheType()),
 		),
+		iFileDao: dao.NewFileDao(
+			database.GetDB(),
+			cache.NewFileCache(database.GetCacheType()),
+		),
+		iUploadSessionDao: dao.NewUploadSessionDao(database.GetDB()),
+		storage:           database.GetStorage(),
+		signer:            database.GetURLSigner(),
 	}
 }
 
-// Local upload local file
-// @Summary upload local file
-// @Description upload local file
+// Local upload file to the configured storage (local or s3), files with the same content are stored only once,
+// the file is checked by the upload policy of the category which is configured in t_config,
+// private files are not served under /uploads, they can only be downloaded by signed urls
+// @Summary upload file
+// @Description upload file to the configured storage, the route name is kept for compatibility
 // @Tags upload
 // @accept json
 // @Produce json
+// @Param category query string false "upload category, avatar or attachment, default attachment"
+// @Param private query bool false "private file, default false"
 // @Param file formData file true "file"
 // @Success 200 {object} types.UploadLocalReply{}
 // @Router /api/v1/upload/local [post]
 // @Security BearerAuth
 func (h *uploadHandler) Local(c *gin.Context) {
-	_, file, err := c.Request.FormFile("file")
+	category := c.DefaultQuery("category", upload.CategoryAttachment)
+	if category != upload.CategoryAvatar && category != upload.CategoryAttachment {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	private, err := strconv.ParseBool(c.DefaultQuery("private", "false"))
 	if err != nil {
 		response.Error(c, ecode.InvalidParams)
 		return
 	}
-	ext := filepath.Ext(file.Filename)
-	name := strings.TrimSuffix(file.Filename, ext)
-	newFileName := gocrypto.Md5([]byte(name+time.Now().Format("20060102150405"))) + ext
+	ctx := middleware.WrapCtx(c)
+	policy := h.getPolicy(ctx, category)
 
-	path := fmt.Sprintf("%s/%s", "uploads", time.Now().Format("2006-01-02"))
-	if !gofile.IsExists(path) {
-		if err := gofile.CreateDir(path); err != nil {
-			response.Error(c, errcode.NewError(10001, err.Error()))
+	// reject oversized bodies before they are read, 1M is left for the multipart envelope
+	if policy.MaxSize > 0 {
+		limit := policy.MaxSize + 1<<20
+		if c.Request.ContentLength > limit {
+			response.Error(c, ecode.ErrUploadTooLarge)
 			return
 		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+	}
+
+	_, file, err := c.Request.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.Error(c, ecode.ErrUploadTooLarge)
+			return
+		}
+		response.Error(c, ecode.InvalidParams)
+		return
 	}
-	filePath := path + "/" + newFileName
-	f, openError := file.Open() // 读取文件
-	if openError != nil {
-		response.Error(c, errcode.NewError(10001, openError.Error()))
+	if err = policy.CheckSize(file.Size); err != nil {
+		response.Error(c, ecode.ErrUploadTooLarge)
+		return
+	}
+
+	f, err := file.Open() // 读取文件
+	if err != nil {
+		logger.Error("file.Open error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUploadFile)
 		return
 	}
 	defer f.Close() // 创建文件 defer 关闭
 
-	out, createErr := os.Create(filePath)
-	if createErr != nil {
-		response.Error(c, errcode.NewError(10001, createErr.Error()))
+	// 根据文件内容判断类型，不信任客户端的扩展名和Content-Type
+	mime, err := policy.Detect(f, file.Filename)
+	if err != nil {
+		if errors.Is(err, upload.ErrTypeNotAllowed) {
+			logger.Warn("upload type not allowed", logger.String("name", file.Filename), logger.String("category", category), middleware.GCtxRequestIDField(c))
+			response.Error(c, ecode.ErrUploadTypeNotAllowed)
+			return
+		}
+		logger.Error("Detect error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUploadFile)
 		return
 	}
-	defer out.Close() // 创建文件 defer 关闭
 
-	_, copyErr := io.Copy(out, f) // 传输（拷贝）文件
-	if copyErr != nil {
-		response.Error(c, errcode.NewError(10001, copyErr.Error()))
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		response.Error(c, ecode.ErrUploadFile)
+		return
+	}
+	hash := sha256.New()
+	if _, err = io.Copy(hash, f); err != nil {
+		logger.Error("read file error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUploadFile)
+		return
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+
+	// 相同内容的文件直接复用, 私有文件只复用自己上传的
+	ownerID := fileOwner(private, c.GetUint64("id"))
+	record, err := h.iFileDao.GetByHash(ctx, sum, private, ownerID)
+	if err == nil {
+		h.shareFile(ctx, record, category)
+		response.Success(c, h.uploadItem(c, record))
+		return
+	}
+	if !errors.Is(err, database.ErrRecordNotFound) {
+		logger.Error("GetByHash error", logger.Err(err), logger.String("hash", sum), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		response.Error(c, ecode.ErrUploadFile)
+		return
+	}
+	ext := mime.Extension()
+	if ext == "" {
+		ext = strings.ToLower(filepath.Ext(file.Filename))
+	}
+	key := newStorageKey(sum, ext, ownerID)
+	size, variants, err := h.storeFile(ctx, key, f, file.Size, mime.String(), nil)
+	if err != nil {
+		logger.Error("storeFile error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUploadFile)
+		return
+	}
+
+	record = &model.File{
+		UploaderID: c.GetUint64("id"),
+		Name:       file.Filename,
+		Size:       size,
+		MimeType:   mime.String(),
+		Hash:       sum,
+		StorageKey: key,
+		Variants:   variants,
+		Private:    private,
+		OwnerID:    ownerID,
+		Category:   category,
+	}
+	record, err = h.createFile(ctx, record)
+	if err != nil {
+		logger.Error("CreateOrGet error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
 		return
 	}
 
 	// 上传成功
-	response.Success(c, types.UploadItem{
-		Name: newFileName,
-		Path: "/" + filePath,
-		Url:  h.iConfigDao.MakePathByConfig(c, "/"+filePath, constant.ConfigKeyImageDomain),
-	})
+	response.Success(c, h.uploadItem(c, record))
+}
+
+// getPolicy upload policy of the category from t_config, the default policy is used if it is not configured
+func (h *uploadHandler) getPolicy(ctx context.Context, category string) *upload.Policy {
+	value := h.iConfigDao.GetString(ctx, constant.ConfigKeyUploadPolicyPrefix+category, "")
+	policy, err := upload.ParsePolicy(category, value)
+	if err != nil {
+		logger.Warn("invalid upload policy, use the default", logger.Err(err), logger.String("category", category))
+		return upload.DefaultPolicy(category)
+	}
+	return policy
+}
+
+// shareFile a deduplicated file is shared by all its uploads, every reuse starts a new grace period of the
+// orphaned upload gc because the new upload is not referenced until its form is saved, once it is uploaded
+// as an attachment it keeps that category so it is not collected as an unused avatar
+func (h *uploadHandler) shareFile(ctx context.Context, record *model.File, category string) {
+	if err := h.iFileDao.Touch(ctx, record.ID); err != nil {
+		logger.Warn("Touch error", logger.Err(err), logger.Any("id", record.ID))
+	}
+	if record.Category == category || category != upload.CategoryAttachment {
+		return
+	}
+	update := &model.File{Category: category}
+	update.ID = record.ID
+	if err := h.iFileDao.UpdateByID(ctx, update); err != nil {
+		logger.Warn("UpdateByID error", logger.Err(err), logger.Any("id", record.ID))
+		return
+	}
+	record.Category = category
+}
+
+// createFile register the stored file, if the same file was registered by a concurrent upload the existing
+// record is shared and the objects just stored under another key are removed
+func (h *uploadHandler) createFile(ctx context.Context, record *model.File) (*model.File, error) {
+	existing, created, err := h.iFileDao.CreateOrGet(ctx, record)
+	if err != nil || created {
+		return existing, err
+	}
+	if existing.StorageKey != record.StorageKey {
+		keys := []string{record.StorageKey}
+		for _, suffix := range record.Variants {
+			keys = append(keys, imageproc.VariantKey(record.StorageKey, suffix))
+		}
+		for _, key := range keys {
+			if err = h.storage.Delete(ctx, key); err != nil {
+				logger.Warn("storage.Delete error", logger.Err(err), logger.String("key", key))
+			}
+		}
+	}
+	h.shareFile(ctx, existing, record.Category)
+	return existing, nil
+}
+
+// fileOwner the owner of a file, a private file belongs to its uploader, a public file to nobody
+func fileOwner(private bool, uploaderID uint64) uint64 {
+	if private {
+		return uploaderID
+	}
+	return 0
+}
+
+// newStorageKey storage key of a new file, e.g. 2024-11-10/<sha256>.png, the key of a private file
+// is under its owner, e.g. private/1/2024-11-10/<sha256>.pdf, so the same content of two owners is stored twice
+func newStorageKey(hash string, ext string, ownerID uint64) string {
+	key := time.Now().Format("2006-01-02") + "/" + hash + ext
+	if ownerID > 0 {
+		key = constant.PrivateKeyPrefix + strconv.FormatUint(ownerID, 10) + "/" + key
+	}
+	return key
+}
+
+// storeFile write the file to the storage, images are processed by the image pipeline first,
+// private files are stored as they are because variants can not be downloaded by signed urls,
+// verify is called after the whole file has been read, the file is not kept if it fails.
+// return the stored size and the suffixes of the generated variants
+func (h *uploadHandler) storeFile(ctx context.Context, key string, r io.Reader, size int64, mime string,
+	verify func() error) (int64, types.LocalStringArray, error) {
+	if !imageproc.Supported(mime) || strings.HasPrefix(key, constant.PrivateKeyPrefix) {
+		if err := h.storage.Put(ctx, key, r, size, mime); err != nil {
+			return 0, nil, err
+		}
+		if verify != nil {
+			if err := verify(); err != nil {
+				_ = h.storage.Delete(ctx, key)
+				return 0, nil, err
+			}
+		}
+		return size, nil, nil
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if verify != nil {
+		if err = verify(); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	var variants types.LocalStringArray
+	result, err := imageproc.Process(data, mime, h.getImageOptions(ctx))
+	if err != nil {
+		// broken or huge images are kept as they are
+		logger.Warn("imageproc.Process error, store the original", logger.Err(err), logger.String("key", key))
+		result = &imageproc.Result{}
+	}
+	if result.Original != nil {
+		data = result.Original.Data
+	}
+	for _, v := range result.Variants {
+		err = h.storage.Put(ctx, imageproc.VariantKey(key, v.Suffix), bytes.NewReader(v.Data), int64(len(v.Data)), v.ContentType)
+		if err != nil {
+			return 0, nil, err
+		}
+		variants = append(variants, v.Suffix)
+	}
+	if err = h.storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), mime); err != nil {
+		return 0, nil, err
+	}
+	return int64(len(data)), variants, nil
+}
+
+// getImageOptions image pipeline options from t_config, the default options are used if it is not configured
+func (h *uploadHandler) getImageOptions(ctx context.Context) *imageproc.Options {
+	value := h.iConfigDao.GetString(ctx, constant.ConfigKeyImagePipeline, "")
+	o, err := imageproc.ParseOptions(value)
+	if err != nil {
+		logger.Warn("invalid image pipeline options, use the default", logger.Err(err))
+		return imageproc.DefaultOptions()
+	}
+	return o
+}
+
+func (h *uploadHandler) uploadItem(c *gin.Context, record *model.File) types.UploadItem {
+	if record.Private {
+		return types.UploadItem{
+			ID:   record.ID,
+			Name: path.Base(record.StorageKey),
+			Path: constant.DownloadPathPrefix + strconv.FormatUint(record.ID, 10),
+			Url:  signedURL(c, h.iConfigDao, h.signer, record.ID, c.GetUint64("id"), defaultExpiredAt()),
+		}
+	}
+	filePath := constant.UploadPathPrefix + record.StorageKey
+	return types.UploadItem{
+		ID:       record.ID,
+		Name:     path.Base(record.StorageKey),
+		Path:     filePath,
+		Url:      h.iConfigDao.MakePathByConfig(c, filePath, constant.ConfigKeyImageDomain),
+		Variants: variantURLs(c, h.iConfigDao, record),
+	}
+}
+
+// variantURLs urls of the image variants by suffix, e.g. {"small.jpg": "http://host/uploads/2024-11-10/a.jpg!small.jpg"}
+func variantURLs(c *gin.Context, iConfigDao dao.ConfigDao, record *model.File) map[string]string {
+	if len(record.Variants) == 0 {
+		return nil
+	}
+	urls := make(map[string]string, len(record.Variants))
+	for _, suffix := range record.Variants {
+		filePath := constant.UploadPathPrefix + imageproc.VariantKey(record.StorageKey, suffix)
+		urls[suffix] = iConfigDao.MakePathByConfig(c, filePath, constant.ConfigKeyImageDomain)
+	}
+	return urls
+}
+
+// Serve download an uploaded file, redirect to storageDomain if it is configured,
+// otherwise stream the file from the storage backend, Range requests are supported
+func (h *uploadHandler) Serve(c *gin.Context) {
+	key, err := storage.CleanKey(c.Param("key"))
+	// internal objects such as chunks, and private files which can only be downloaded by signed urls
+	if err != nil || strings.HasPrefix(key, "_") || strings.HasPrefix(key, constant.PrivateKeyPrefix) {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	if u := h.iConfigDao.MakePathByConfig(c, key, constant.ConfigKeyStorageDomain); util.IsValidURL(u) {
+		c.Redirect(http.StatusFound, u)
+		return
+	}
+
+	obj, err := h.storage.Open(c, key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		logger.Error("storage.Open error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+	defer obj.Close()
+
+	info := obj.Info()
+	c.Header("X-Content-Type-Options", "nosniff")
+	if info.ContentType != "" {
+		c.Header("Content-Type", info.ContentType)
+	}
+	http.ServeContent(c.Writer, c.Request, path.Base(key), info.LastModified, obj)
 }
@@ -0,0 +1,370 @@
+package handler
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"admin/internal/database"
+	"admin/internal/ecode"
+	"admin/internal/model"
+	"admin/internal/pkg/upload"
+	"admin/internal/types"
+)
+
+var errHashMismatch = errors.New("hash mismatch")
+
+// InitiateSession start a chunked upload session, if a file with the same hash exists it is returned directly
+// @Summary initiate chunked upload
+// @Description initiate a chunked upload session with the file size and SHA-256, then upload chunks and complete it
+// @Tags upload
+// @accept json
+// @Produce json
+// @Param data body types.InitiateUploadRequest true "file information"
+// @Success 200 {object} types.UploadSessionReply{}
+// @Router /api/v1/upload/session [post]
+// @Security BearerAuth
+func (h *uploadHandler) InitiateSession(c *gin.Context) {
+	form := &types.InitiateUploadRequest{}
+	err := c.ShouldBindJSON(form)
+	if err != nil {
+		logger.Warn("ShouldBindJSON error: ", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	if form.Category == "" {
+		form.Category = upload.CategoryAttachment
+	}
+	if form.Category != upload.CategoryAvatar && form.Category != upload.CategoryAttachment {
+		response.Error(c, ecode.InvalidParams)
+		return
+	}
+	form.Hash = strings.ToLower(form.Hash)
+
+	ctx := middleware.WrapCtx(c)
+	policy := h.getPolicy(ctx, form.Category)
+	if err = policy.CheckSize(form.Size); err != nil {
+		response.Error(c, ecode.ErrUploadTooLarge)
+		return
+	}
+	if err = policy.CheckName(form.Name); err != nil {
+		response.Error(c, ecode.ErrUploadTypeNotAllowed)
+		return
+	}
+
+	// 秒传: 相同内容的文件已存在. 知道hash不代表拥有内容, 私有文件不秒传, 完整上传后只复用自己的文件
+	if !form.Private {
+		record, err := h.iFileDao.GetByHash(ctx, form.Hash, false, 0)
+		if err == nil {
+			h.shareFile(ctx, record, form.Category)
+			item := h.uploadItem(c, record)
+			response.Success(c, types.UploadSessionItem{Name: form.Name, Size: form.Size, File: &item})
+			return
+		}
+		if !errors.Is(err, database.ErrRecordNotFound) {
+			logger.Error("GetByHash error", logger.Err(err), logger.String("hash", form.Hash), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+			return
+		}
+	}
+
+	chunkSize := form.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = upload.DefaultChunkSize
+	}
+	chunkSize = min(max(chunkSize, upload.MinChunkSize), upload.MaxChunkSize)
+
+	session := &model.UploadSession{
+		UploadID:   newUploadID(),
+		UploaderID: c.GetUint64("id"),
+		Category:   form.Category,
+		Name:       form.Name,
+		Size:       form.Size,
+		Hash:       form.Hash,
+		ChunkSize:  chunkSize,
+		ChunkCount: upload.ChunkCount(form.Size, chunkSize),
+		ExpiredAt:  time.Now().Add(upload.SessionTTL),
+		Private:    form.Private,
+	}
+	err = h.iUploadSessionDao.Create(ctx, session)
+	if err != nil {
+		logger.Error("Create error", logger.Err(err), logger.Any("form", form), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, convertUploadSession(session, nil))
+}
+
+// GetSession get the upload session and the uploaded chunks, used to resume an upload
+// @Summary get chunked upload
+// @Description get the upload session and the indexes of uploaded chunks
+// @Tags upload
+// @Produce json
+// @Param uploadID path string true "upload id"
+// @Success 200 {object} types.UploadSessionReply{}
+// @Router /api/v1/upload/session/{uploadID} [get]
+// @Security BearerAuth
+func (h *uploadHandler) GetSession(c *gin.Context) {
+	session, ok := h.getSession(c)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	chunks, err := upload.ListChunks(ctx, h.storage, session.UploadID)
+	if err != nil {
+		logger.Error("ListChunks error", logger.Err(err), logger.String("uploadID", session.UploadID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	response.Success(c, convertUploadSession(session, chunks))
+}
+
+// UploadChunk upload a chunk, the request body is the raw chunk data, uploading a chunk again overwrites it
+// @Summary upload chunk
+// @Description upload a chunk, index starts from 0, every chunk except the last one must be chunkSize bytes
+// @Tags upload
+// @accept application/octet-stream
+// @Produce json
+// @Param uploadID path string true "upload id"
+// @Param index path int true "chunk index"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/upload/session/{uploadID}/chunk/{index} [put]
+// @Security BearerAuth
+func (h *uploadHandler) UploadChunk(c *gin.Context) {
+	session, ok := h.getSession(c)
+	if !ok {
+		return
+	}
+
+	index, err := strconv.Atoi(c.Param("index"))
+	size := upload.ChunkLen(session.Size, session.ChunkSize, index)
+	if err != nil || size == 0 || c.Request.ContentLength != size {
+		response.Error(c, ecode.ErrUploadChunk)
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	key := upload.ChunkKey(session.UploadID, index)
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, size)
+	err = h.storage.Put(ctx, key, body, size, "application/octet-stream")
+	if err != nil {
+		logger.Error("storage.Put error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUploadFile)
+		return
+	}
+
+	// the connection may break in the middle of a chunk
+	info, err := h.storage.Stat(ctx, key)
+	if err != nil || info.Size != size {
+		_ = h.storage.Delete(ctx, key)
+		response.Error(c, ecode.ErrUploadChunk)
+		return
+	}
+
+	response.Success(c)
+}
+
+// CompleteSession assemble the chunks, verify the hash and register the file
+// @Summary complete chunked upload
+// @Description assemble the uploaded chunks into a file, the SHA-256 must match the one given at initiation
+// @Tags upload
+// @Produce json
+// @Param uploadID path string true "upload id"
+// @Success 200 {object} types.UploadLocalReply{}
+// @Router /api/v1/upload/session/{uploadID}/complete [post]
+// @Security BearerAuth
+func (h *uploadHandler) CompleteSession(c *gin.Context) {
+	session, ok := h.getSession(c)
+	if !ok {
+		return
+	}
+
+	ctx := middleware.WrapCtx(c)
+	chunks, err := upload.ListChunks(ctx, h.storage, session.UploadID)
+	if err != nil {
+		logger.Error("ListChunks error", logger.Err(err), logger.String("uploadID", session.UploadID), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	for i := 0; i < session.ChunkCount; i++ {
+		if chunks[i] != upload.ChunkLen(session.Size, session.ChunkSize, i) {
+			response.Error(c, ecode.ErrUploadIncomplete)
+			return
+		}
+	}
+
+	// 根据第一个分片的内容判断类型
+	policy := h.getPolicy(ctx, session.Category)
+	first, err := h.storage.Open(ctx, upload.ChunkKey(session.UploadID, 0))
+	if err != nil {
+		logger.Error("storage.Open error", logger.Err(err), logger.String("uploadID", session.UploadID), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUploadFile)
+		return
+	}
+	mime, err := policy.Detect(first, session.Name)
+	_ = first.Close()
+	if err != nil {
+		if errors.Is(err, upload.ErrTypeNotAllowed) {
+			h.removeSession(c, session)
+			response.Error(c, ecode.ErrUploadTypeNotAllowed)
+			return
+		}
+		logger.Error("Detect error", logger.Err(err), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUploadFile)
+		return
+	}
+
+	// the same file may have been uploaded by someone else in the meantime, a private file only by its owner
+	ownerID := fileOwner(session.Private, session.UploaderID)
+	record, err := h.iFileDao.GetByHash(ctx, session.Hash, session.Private, ownerID)
+	if err == nil {
+		h.shareFile(ctx, record, session.Category)
+		h.removeSession(c, session)
+		response.Success(c, h.uploadItem(c, record))
+		return
+	}
+	if !errors.Is(err, database.ErrRecordNotFound) {
+		logger.Error("GetByHash error", logger.Err(err), logger.String("hash", session.Hash), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+
+	ext := mime.Extension()
+	if ext == "" {
+		ext = strings.ToLower(filepath.Ext(session.Name))
+	}
+	key := newStorageKey(session.Hash, ext, ownerID)
+	hash := sha256.New()
+	r := upload.NewChunkReader(ctx, h.storage, session.UploadID, session.ChunkCount)
+	size, variants, err := h.storeFile(ctx, key, io.TeeReader(r, hash), session.Size, mime.String(), func() error {
+		if hex.EncodeToString(hash.Sum(nil)) != session.Hash {
+			return errHashMismatch
+		}
+		return nil
+	})
+	_ = r.Close()
+	if err != nil {
+		if errors.Is(err, errHashMismatch) {
+			h.removeSession(c, session)
+			response.Error(c, ecode.ErrUploadHashMismatch)
+			return
+		}
+		logger.Error("storeFile error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+		response.Error(c, ecode.ErrUploadFile)
+		return
+	}
+
+	record = &model.File{
+		UploaderID: session.UploaderID,
+		Name:       session.Name,
+		Size:       size,
+		MimeType:   mime.String(),
+		Hash:       session.Hash,
+		StorageKey: key,
+		Variants:   variants,
+		Private:    session.Private,
+		OwnerID:    ownerID,
+		Category:   session.Category,
+	}
+	record, err = h.createFile(ctx, record)
+	if err != nil {
+		logger.Error("CreateOrGet error", logger.Err(err), logger.String("key", key), middleware.GCtxRequestIDField(c))
+		response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		return
+	}
+	h.removeSession(c, session)
+
+	response.Success(c, h.uploadItem(c, record))
+}
+
+// AbortSession abort the upload session and delete the uploaded chunks
+// @Summary abort chunked upload
+// @Description abort the upload session and delete the uploaded chunks
+// @Tags upload
+// @Produce json
+// @Param uploadID path string true "upload id"
+// @Success 200 {object} types.Result{}
+// @Router /api/v1/upload/session/{uploadID} [delete]
+// @Security BearerAuth
+func (h *uploadHandler) AbortSession(c *gin.Context) {
+	session, ok := h.getSession(c)
+	if !ok {
+		return
+	}
+	h.removeSession(c, session)
+	response.Success(c)
+}
+
+// getSession get the unexpired session of the current user, the error response is written if it is not found
+func (h *uploadHandler) getSession(c *gin.Context) (*model.UploadSession, bool) {
+	uploadID := c.Param("uploadID")
+	session, err := h.iUploadSessionDao.GetByUploadID(middleware.WrapCtx(c), uploadID)
+	if err != nil {
+		if errors.Is(err, database.ErrRecordNotFound) {
+			response.Error(c, ecode.ErrUploadSessionExpired)
+		} else {
+			logger.Error("GetByUploadID error", logger.Err(err), logger.String("uploadID", uploadID), middleware.GCtxRequestIDField(c))
+			response.Output(c, ecode.InternalServerError.ToHTTPCode())
+		}
+		return nil, false
+	}
+	if session.UploaderID != c.GetUint64("id") || session.ExpiredAt.Before(time.Now()) {
+		response.Error(c, ecode.ErrUploadSessionExpired)
+		return nil, false
+	}
+	return session, true
+}
+
+// removeSession delete the chunks and the session, chunks left behind are removed by the cleanup task
+func (h *uploadHandler) removeSession(c *gin.Context, session *model.UploadSession) {
+	ctx := middleware.WrapCtx(c)
+	if err := upload.RemoveChunks(ctx, h.storage, session.UploadID); err != nil {
+		logger.Warn("RemoveChunks error", logger.Err(err), logger.String("uploadID", session.UploadID), middleware.GCtxRequestIDField(c))
+		return
+	}
+	if err := h.iUploadSessionDao.DeleteByUploadID(ctx, session.UploadID); err != nil {
+		logger.Warn("DeleteByUploadID error", logger.Err(err), logger.String("uploadID", session.UploadID), middleware.GCtxRequestIDField(c))
+	}
+}
+
+func convertUploadSession(session *model.UploadSession, chunks map[int]int64) *types.UploadSessionItem {
+	uploaded := []int{}
+	for index, size := range chunks {
+		if size == upload.ChunkLen(session.Size, session.ChunkSize, index) {
+			uploaded = append(uploaded, index)
+		}
+	}
+	sort.Ints(uploaded)
+
+	return &types.UploadSessionItem{
+		UploadID:   session.UploadID,
+		Name:       session.Name,
+		Size:       session.Size,
+		ChunkSize:  session.ChunkSize,
+		ChunkCount: session.ChunkCount,
+		ExpiredAt:  session.ExpiredAt,
+		Uploaded:   uploaded,
+	}
+}
+
+func newUploadID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/go-dev-frame/sponge/pkg/gotest"
+	"github.com/go-dev-frame/sponge/pkg/httpcli"
+
+	"admin/internal/dao"
+	"admin/internal/model"
+	"admin/internal/pkg/signurl"
+	"admin/internal/pkg/storage"
+	"admin/internal/types"
+)
+
+const testUploadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// newUploadHandler the requests are sent by the account 2
+func newUploadHandler() *gotest.Handler {
+	testData := &model.File{}
+	testData.ID = 1
+	testData.UploaderID = 1
+	testData.Hash = testUploadHash
+	testData.StorageKey = "2024-11-10/" + testUploadHash + ".pdf"
+
+	// init mock dao
+	d := gotest.NewDao(nil, testData)
+	d.IDao = dao.NewFileDao(d.DB, nil)
+
+	// init mock handler
+	h := gotest.NewHandler(d, testData)
+	h.IHandler = &uploadHandler{
+		iConfigDao:        dao.NewConfigDao(d.DB, nil),
+		iFileDao:          d.IDao.(dao.FileDao),
+		iUploadSessionDao: dao.NewUploadSessionDao(d.DB),
+		storage:           storage.NewLocal(filepath.Join(os.TempDir(), "admin-upload-test")),
+		signer:            signurl.New("test"),
+	}
+	iHandler := h.IHandler.(UploadHandler)
+
+	testFns := []gotest.RouterInfo{
+		{
+			FuncName: "InitiateSession",
+			Method:   http.MethodPost,
+			Path:     "/upload/session",
+			HandlerFunc: func(c *gin.Context) {
+				c.Set("id", uint64(2))
+				iHandler.InitiateSession(c)
+			},
+		},
+	}
+
+	h.GoRunHTTPServer(testFns)
+
+	time.Sleep(time.Millisecond * 200)
+	return h
+}
+
+func Test_uploadHandler_InitiateSession(t *testing.T) {
+	h := newUploadHandler()
+	defer h.Close()
+	testData := h.TestData.(*model.File)
+
+	// a public file with the same content is returned without uploading
+	h.MockDao.SQLMock.ExpectQuery("SELECT .* FROM `t_file`").
+		WithArgs(testUploadHash, false, 0, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "hash", "storage_key"}).AddRow(testData.ID, testData.Hash, testData.StorageKey))
+	// the reuse starts a new grace period of the orphaned upload gc
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("UPDATE `t_file` SET `updated_at`=.*").
+		WithArgs(h.MockDao.AnyTime, testData.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	result := &httpcli.StdResult{}
+	form := &types.InitiateUploadRequest{Name: "a.pdf", Size: 1024, Hash: testUploadHash}
+	err := httpcli.Post(result, h.GetRequestURL("InitiateSession"), form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, result.Code)
+	file, ok := result.Data.(map[string]interface{})["file"].(map[string]interface{})
+	if assert.True(t, ok) {
+		assert.Equal(t, float64(testData.ID), file["id"])
+	}
+
+	// the hash of a private file of another account is not looked up, it must be uploaded
+	h.MockDao.SQLMock.ExpectBegin()
+	h.MockDao.SQLMock.ExpectExec("INSERT INTO `t_upload_session`").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	h.MockDao.SQLMock.ExpectCommit()
+
+	form.Private = true
+	err = httpcli.Post(result, h.GetRequestURL("InitiateSession"), form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 0, result.Code)
+	data := result.Data.(map[string]interface{})
+	assert.NotContains(t, data, "file")
+	assert.NotEmpty(t, data["uploadID"])
+	assert.NoError(t, h.MockDao.SQLMock.ExpectationsWereMet())
+}
+
+func Test_newStorageKey(t *testing.T) {
+	assert.Regexp(t, `^\d{4}-\d{2}-\d{2}/`+testUploadHash+`\.pdf$`, newStorageKey(testUploadHash, ".pdf", fileOwner(false, 1)))
+	assert.Regexp(t, `^private/1/\d{4}-\d{2}-\d{2}/`+testUploadHash+`\.pdf$`, newStorageKey(testUploadHash, ".pdf", fileOwner(true, 1)))
+	assert.NotEqual(t, newStorageKey(testUploadHash, ".pdf", fileOwner(true, 1)), newStorageKey(testUploadHash, ".pdf", fileOwner(true, 2)))
+}
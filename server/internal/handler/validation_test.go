@@ -0,0 +1,20 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-dev-frame/sponge/pkg/gin/validator"
+
+	"admin/internal/cache"
+	"admin/internal/config"
+	"admin/internal/validation"
+)
+
+// the requests use the custom tags, register them like routers.NewRouter does
+func init() {
+	_ = config.Init("")
+	v := validator.Init()
+	if err := validation.Register(v.Validate, cache.NewEnumCache()); err != nil {
+		panic(err)
+	}
+	binding.Validator = v
+}
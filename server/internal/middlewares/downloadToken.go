@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
+
+	"admin/internal/pkg/signurl"
+)
+
+// DownloadTokenCookie the cookie carrying the token to the downloads of signed urls bound to an account,
+// a browser opening a link does not send the Authorization header but it sends the cookie
+const DownloadTokenCookie = "admin_download_token"
+
+// downloadTokenMaxAge the same as the expiry of the token, in seconds
+const downloadTokenMaxAge = 7200
+
+// SetDownloadToken keep the token in an HttpOnly cookie, it is only read by the signed downloads
+func SetDownloadToken(c *gin.Context, token string) {
+	setDownloadCookie(c, token, downloadTokenMaxAge)
+}
+
+// ClearDownloadToken remove the cookie of the token
+func ClearDownloadToken(c *gin.Context) {
+	setDownloadCookie(c, "", -1)
+}
+
+func setDownloadCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	// lax, the cookie is sent when a link is opened from another site but not by its cross-site subrequests
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(DownloadTokenCookie, value, maxAge, "/", "", secure, true)
+}
+
+// AuthBoundAccount jwt authentication for signed urls which are bound to an account, the other signed urls
+// need no login. a browser opening the url sends the token by the cookie set at login instead of the
+// Authorization header, so the cookie is used when the header is absent
+func AuthBoundAccount(opts ...auth.AuthOption) gin.HandlerFunc {
+	authFn := auth.Auth(opts...)
+	return func(c *gin.Context) {
+		if c.Query(signurl.ParamAccount) == "" {
+			c.Next()
+			return
+		}
+		if c.GetHeader(auth.HeaderAuthorizationKey) == "" {
+			if token, err := c.Cookie(DownloadTokenCookie); err == nil && token != "" {
+				c.Request.Header.Set(auth.HeaderAuthorizationKey, "Bearer "+token)
+			}
+		}
+		authFn(c)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"admin/internal/dao"
 	"admin/internal/database"
 	"admin/internal/ecode"
+	"admin/internal/pkg/i18n"
 	"context"
 	"time"
 
@@ -26,6 +27,7 @@ func VerifyToken(claims *jwt.Claims, c *gin.Context) error {
 			return err
 		}
 		c.Header("X-Renewed-Token", token)
+		SetDownloadToken(c, token)
 	}
 
 	if iPlatformDao == nil {
@@ -58,6 +60,10 @@ func VerifyToken(claims *jwt.Claims, c *gin.Context) error {
 		roleCode = append(roleCode, role.Code)
 	}
 
+	if platform.Locale != "" {
+		i18n.Set(c, platform.Locale)
+	}
+
 	c.Set("id", platform.ID)
 	c.Set("roleId", platform.RoleID)
 	c.Set("roleCode", roleCode)
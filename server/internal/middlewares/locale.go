@@ -0,0 +1,73 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"admin/internal/ecode"
+	"admin/internal/pkg/i18n"
+)
+
+// Locale pick the locale of the request from the Accept-Language header, VerifyToken replaces it with the
+// preference of the user. the messages of the error codes in the json replies are translated to the locale
+func Locale() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		i18n.Set(c, i18n.ParseAcceptLanguage(c.GetHeader("Accept-Language")))
+
+		w := &localeWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.body.Len() > 0 {
+			_, _ = w.ResponseWriter.Write(localizeReply(w.body.Bytes(), i18n.Get(c)))
+		}
+	}
+}
+
+// localeWriter keep the json replies until the handlers finish, the others are written through
+type localeWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *localeWriter) Write(data []byte) (int, error) {
+	if !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *localeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+type reply struct {
+	Code int             `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// localizeReply translate the message of a failed reply, the other replies are unchanged
+func localizeReply(body []byte, locale string) []byte {
+	if locale == "" {
+		return body
+	}
+	r := &reply{}
+	if err := json.Unmarshal(body, r); err != nil || r.Code == 0 {
+		return body
+	}
+	msg := ecode.Localize(r.Code, r.Msg, locale)
+	if msg == r.Msg {
+		return body
+	}
+	r.Msg = msg
+	data, err := json.Marshal(r)
+	if err != nil {
+		return body
+	}
+	return append(data, '\n')
+}
@@ -0,0 +1,75 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/response"
+	"github.com/stretchr/testify/assert"
+
+	"admin/internal/ecode"
+	"admin/internal/pkg/i18n"
+)
+
+func TestLocale(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(Locale())
+	r.GET("/login", func(c *gin.Context) {
+		response.Error(c, ecode.ErrLogin)
+	})
+	r.GET("/params", func(c *gin.Context) {
+		response.Error(c, ecode.InvalidParams.WithDetails("gender must be one of 1(男), 2(女)"))
+	})
+	r.GET("/internal", func(c *gin.Context) {
+		response.Output(c, http.StatusInternalServerError)
+	})
+	r.GET("/preference", func(c *gin.Context) {
+		i18n.Set(c, "en")
+		response.Error(c, ecode.ErrLoginFrozen)
+	})
+	r.GET("/ok", func(c *gin.Context) {
+		response.Success(c, gin.H{"locale": i18n.Get(c)})
+	})
+	r.GET("/text", func(c *gin.Context) {
+		c.String(http.StatusOK, "plain")
+	})
+
+	do := func(path, acceptLanguage string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptLanguage != "" {
+			req.Header.Set("Accept-Language", acceptLanguage)
+		}
+		r.ServeHTTP(w, req)
+		result := map[string]interface{}{}
+		_ = json.Unmarshal(w.Body.Bytes(), &result)
+		return w, result
+	}
+
+	_, result := do("/login", "en-US,en;q=0.9")
+	assert.Equal(t, "incorrect username or password", result["msg"])
+	assert.EqualValues(t, ecode.ErrLogin.Code(), result["code"])
+
+	_, result = do("/login", "")
+	assert.Equal(t, ecode.ErrLogin.Msg(), result["msg"])
+
+	_, result = do("/params", "zh-CN")
+	assert.Equal(t, "参数错误, gender must be one of 1(男), 2(女)", result["msg"])
+
+	w, result := do("/internal", "zh")
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "服务器内部错误", result["msg"])
+
+	_, result = do("/preference", "zh-CN")
+	assert.Equal(t, "the account is frozen, please contact the administrator", result["msg"])
+
+	_, result = do("/ok", "en")
+	assert.Equal(t, map[string]interface{}{"locale": "en"}, result["data"])
+
+	w, _ = do("/text", "en")
+	assert.Equal(t, "plain", w.Body.String())
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// AuditLog 审计日志, 记录管理员的运维操作
+type AuditLog struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	AccountID uint64 `gorm:"column:account_id;type:int(11);default:0;NOT NULL" json:"accountID"` // 操作人
+	Action    string `gorm:"column:action;type:varchar(64);NOT NULL" json:"action"`              // 操作, 如 cache.evictKey
+	Target    string `gorm:"column:target;type:varchar(255);NOT NULL" json:"target"`             // 操作对象, 如缓存key
+	Detail    string `gorm:"column:detail;type:text" json:"detail"`                              // 结果或错误信息
+	IP        string `gorm:"column:ip;type:varchar(64);NOT NULL" json:"ip"`                      // 客户端IP
+}
+
+// TableName table name
+func (m *AuditLog) TableName() string {
+	return "t_audit_log"
+}
@@ -1,6 +1,8 @@
 package model
 
 import (
+	"admin/internal/pkg/confval"
+
 	"github.com/go-dev-frame/sponge/pkg/sgorm"
 )
 
@@ -8,10 +10,14 @@ import (
 type Config struct {
 	sgorm.Model `gorm:"embedded"` // embed id and time
 
-	Name        string `gorm:"column:name;type:varchar(32);NOT NULL" json:"name"`                // 配置名称
-	Description string `gorm:"column:description;type:varchar(255);NOT NULL" json:"description"` // 描述
-	Key         string `gorm:"column:key;type:varchar(64);NOT NULL" json:"key"`                  // 配置键
-	Value       string `gorm:"column:value;type:text;NOT NULL" json:"value"`                     // 配置值
+	Name        string               `gorm:"column:name;type:varchar(32);NOT NULL" json:"name"`                // 配置名称
+	Description string               `gorm:"column:description;type:varchar(255);NOT NULL" json:"description"` // 描述
+	Key         string               `gorm:"column:key;type:varchar(64);NOT NULL" json:"key"`                  // 配置键
+	Value       string               `gorm:"column:value;type:text;NOT NULL" json:"value"`                     // 配置值
+	Type        confval.Type         `gorm:"column:type;type:varchar(16);default:string;NOT NULL" json:"type"` // 值类型
+	Constraints *confval.Constraints `gorm:"column:constraints;type:json" json:"constraints"`                  // 值约束
+	Group       string               `gorm:"column:group;type:varchar(32);NOT NULL" json:"group"`              // 分组, 如 site, upload
+	Public      *bool                `gorm:"column:public;type:tinyint(1);default:0;NOT NULL" json:"public"`   // 公开, 未登录也可以通过 /api/v1/config/public 获取
 }
 
 // TableName table name
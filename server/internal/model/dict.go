@@ -0,0 +1,21 @@
+package model
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// Dict 数据字典, 与 constant/enum 下的代码枚举合并后通过 /api/v1/config/dict 提供
+type Dict struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	Name        string `gorm:"column:name;type:varchar(64);NOT NULL" json:"name"`                           // 字典名称
+	Code        string `gorm:"column:code;type:varchar(64);NOT NULL" json:"code"`                           // 字典编码, 即枚举名, 不能与代码枚举重名
+	ValueType   string `gorm:"column:value_type;type:varchar(16);default:string;NOT NULL" json:"valueType"` // 值类型, string 或 int
+	Description string `gorm:"column:description;type:varchar(255);NOT NULL" json:"description"`            // 描述
+	Status      *int   `gorm:"column:status;type:tinyint(4);default:1;NOT NULL" json:"status"`              // 状态, 禁用的字典不合并
+}
+
+// TableName table name
+func (m *Dict) TableName() string {
+	return "t_dict"
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"admin/internal/types"
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// DictItem 数据字典项
+type DictItem struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	DictID  uint64          `gorm:"column:dict_id;type:int(11);default:0;NOT NULL" json:"dictID"`   // 字典ID
+	Label   string          `gorm:"column:label;type:varchar(64);NOT NULL" json:"label"`            // 标签
+	Value   string          `gorm:"column:value;type:varchar(64);NOT NULL" json:"value"`            // 值, 字典值类型为 int 时必须是整数
+	Sort    int             `gorm:"column:sort;type:int(11);default:1;NOT NULL" json:"sort"`        // 排序, 升序
+	Status  *int            `gorm:"column:status;type:tinyint(4);default:1;NOT NULL" json:"status"` // 状态, 禁用的字典项不合并
+	Color   string          `gorm:"column:color;type:varchar(32);NOT NULL" json:"color"`            // 颜色, 如 #409EFF
+	TagType string          `gorm:"column:tag_type;type:varchar(16);NOT NULL" json:"tagType"`       // 标签类型, 如 primary, success, info, warning, danger
+	Labels  types.LocalText `gorm:"column:labels;type:json" json:"labels"`                          // 其他语言的标签, 如 {"en": "Male"}
+}
+
+// TableName table name
+func (m *DictItem) TableName() string {
+	return "t_dict_item"
+}
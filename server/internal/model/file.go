@@ -0,0 +1,41 @@
+package model
+
+import (
+	"admin/internal/types"
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// File 文件
+type File struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	UploaderID uint64                 `gorm:"column:uploader_id;type:int(11);default:0;NOT NULL" json:"uploaderID"`                                          // 上传人
+	Name       string                 `gorm:"column:name;type:varchar(255);NOT NULL" json:"name"`                                                            // 原始文件名
+	Size       int64                  `gorm:"column:size;type:bigint(20);default:0;NOT NULL" json:"size"`                                                    // 文件大小(字节)
+	MimeType   string                 `gorm:"column:mime_type;type:varchar(128);NOT NULL" json:"mimeType"`                                                   // MIME类型
+	Hash       string                 `gorm:"column:hash;type:char(64);uniqueIndex:uk_hash_private_owner,priority:1;NOT NULL" json:"hash"`                   // SHA-256, 公开文件唯一, 私有文件按所有人唯一
+	StorageKey string                 `gorm:"column:storage_key;type:varchar(255);NOT NULL" json:"storageKey"`                                               // 存储key
+	Variants   types.LocalStringArray `gorm:"column:variants;type:json" json:"variants"`                                                                     // 图片变体后缀, 如 small.jpg
+	Private    bool                   `gorm:"column:private;type:tinyint(1);default:0;uniqueIndex:uk_hash_private_owner,priority:2;NOT NULL" json:"private"` // 私有文件, 只能通过签名链接下载
+	OwnerID    uint64                 `gorm:"column:owner_id;type:int(11);default:0;uniqueIndex:uk_hash_private_owner,priority:3;NOT NULL" json:"ownerID"`   // 私有文件的所有人, 公开文件为0
+	Category   string                 `gorm:"column:category;type:varchar(32);NOT NULL" json:"category"`                                                     // 上传类别, avatar或attachment
+}
+
+// TableName table name
+func (m *File) TableName() string {
+	return "t_file"
+}
+
+// FileRef 文件引用, 记录业务字段(如 platform.avatar)使用了哪些文件
+type FileRef struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	FileID uint64 `gorm:"column:file_id;type:int(11);default:0;NOT NULL" json:"fileID"` // 文件ID
+	Biz    string `gorm:"column:biz;type:varchar(64);NOT NULL" json:"biz"`              // 业务字段, 如 platform.avatar
+	BizID  uint64 `gorm:"column:biz_id;type:int(11);default:0;NOT NULL" json:"bizID"`   // 业务记录ID
+}
+
+// TableName table name
+func (m *FileRef) TableName() string {
+	return "t_file_ref"
+}
@@ -22,6 +22,7 @@ type Menu struct {
 	AlwaysShow int             `gorm:"column:always_show;type:tinyint(4);default:0;NOT NULL" json:"alwaysShow"` // 始终显示
 	KeepAlive  int             `gorm:"column:keep_alive;type:tinyint(4);default:1;NOT NULL" json:"keepAlive"`   // 始终显示
 	Params     types.LocalJSON `gorm:"column:params;type:json" json:"params"`                                   // 路由参数
+	Titles     types.LocalText `gorm:"column:titles;type:json" json:"titles"`                                   // 其他语言的菜单名称, 如 {"en": "System"}
 }
 
 // TableName table name
@@ -56,8 +57,10 @@ type ChildrenMeta struct {
 }
 
 type Children struct {
-	Path      string       `json:"path"`      // 路由路径
-	Name      string       `json:"name"`      // 路由名称
-	Component string       `json:"component"` // 组件路径
-	Meta      ChildrenMeta `json:"meta"`      // meta
+	Path      string       `json:"path"`               // 路由路径
+	Name      string       `json:"name"`               // 路由名称
+	Component string       `json:"component"`          // 组件路径
+	Redirect  string       `json:"redirect,omitempty"` // 跳转路径
+	Meta      ChildrenMeta `json:"meta"`               // meta
+	Children  []Children   `json:"children,omitempty"` // 子级, 层级不限
 }
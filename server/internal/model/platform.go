@@ -19,6 +19,7 @@ type Platform struct {
 	RoleID   types.LocalIntArray `gorm:"column:role_id;type:json;NOT NULL" json:"roleID"`                                                                                                    // 角色
 	Status   *int                `gorm:"column:status;type:tinyint(4);NOT NULL" json:"status"`                                                                                               // 状态
 	LastTime *time.Time          `gorm:"column:last_time;type:datetime" json:"lastTime"`                                                                                                     // 上次登录时间
+	Locale   string              `gorm:"column:locale;type:varchar(16);NOT NULL" json:"locale"`                                                                                              // 语言偏好, 为空时按 Accept-Language
 }
 
 // TableName table name
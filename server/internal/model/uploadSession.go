@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/sgorm"
+)
+
+// UploadSession 分片上传会话
+type UploadSession struct {
+	sgorm.Model `gorm:"embedded"` // embed id and time
+
+	UploadID   string    `gorm:"column:upload_id;type:varchar(32);NOT NULL" json:"uploadID"`            // 上传ID
+	UploaderID uint64    `gorm:"column:uploader_id;type:int(11);default:0;NOT NULL" json:"uploaderID"`  // 上传人
+	Category   string    `gorm:"column:category;type:varchar(32);NOT NULL" json:"category"`             // 上传类别
+	Name       string    `gorm:"column:name;type:varchar(255);NOT NULL" json:"name"`                    // 原始文件名
+	Size       int64     `gorm:"column:size;type:bigint(20);default:0;NOT NULL" json:"size"`            // 文件大小(字节)
+	Hash       string    `gorm:"column:hash;type:char(64);NOT NULL" json:"hash"`                        // SHA-256
+	ChunkSize  int64     `gorm:"column:chunk_size;type:bigint(20);default:0;NOT NULL" json:"chunkSize"` // 分片大小(字节)
+	ChunkCount int       `gorm:"column:chunk_count;type:int(11);default:0;NOT NULL" json:"chunkCount"`  // 分片数量
+	ExpiredAt  time.Time `gorm:"column:expired_at;type:datetime;NOT NULL" json:"expiredAt"`             // 过期时间
+	Private    bool      `gorm:"column:private;type:tinyint(1);default:0;NOT NULL" json:"private"`      // 私有文件
+}
+
+// TableName table name
+func (m *UploadSession) TableName() string {
+	return "t_upload_session"
+}
@@ -0,0 +1,194 @@
+// Package confval declares the types and constraints of config values and validates values against them.
+package confval
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Type type of a config value
+type Type string
+
+// config value types
+const (
+	String Type = "string"
+	Int    Type = "int"
+	Bool   Type = "bool"
+	Float  Type = "float"
+	JSON   Type = "json"
+	URL    Type = "url"
+	Enum   Type = "enum"
+	Secret Type = "secret" // a string which is masked when it is read by the api
+)
+
+// SecretMask replaces the value of secret configs in api replies, submitting it back keeps the stored value
+const SecretMask = "******"
+
+// Types all supported types
+var Types = []Type{String, Int, Bool, Float, JSON, URL, Enum, Secret}
+
+// Valid whether the type is supported
+func (t Type) Valid() bool {
+	return slices.Contains(Types, t)
+}
+
+// Constraints optional constraints of a config value
+type Constraints struct {
+	Required  bool     `json:"required,omitempty"`  // value can not be empty
+	Min       *float64 `json:"min,omitempty"`       // minimum of int and float
+	Max       *float64 `json:"max,omitempty"`       // maximum of int and float
+	MinLength int      `json:"minLength,omitempty"` // minimum characters of string and secret
+	MaxLength int      `json:"maxLength,omitempty"` // maximum characters of string and secret
+	Pattern   string   `json:"pattern,omitempty"`   // regular expression of string and secret
+	Options   []string `json:"options,omitempty"`   // allowed values of enum
+}
+
+// Value stored as json
+func (c Constraints) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// Scan read from json
+func (c *Constraints) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*c = Constraints{}
+		return nil
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	}
+	return fmt.Errorf("cannot convert %v to Constraints", src)
+}
+
+// Check whether the constraints make sense for the type
+func (c *Constraints) Check(t Type) error {
+	if !t.Valid() {
+		return fmt.Errorf("unknown type %q", t)
+	}
+	if t == Enum && (c == nil || len(c.Options) == 0) {
+		return errors.New("enum requires options")
+	}
+	if c == nil {
+		return nil
+	}
+	if c.Min != nil && c.Max != nil && *c.Min > *c.Max {
+		return errors.New("min is greater than max")
+	}
+	if c.MaxLength > 0 && c.MinLength > c.MaxLength {
+		return errors.New("minLength is greater than maxLength")
+	}
+	if c.Pattern != "" {
+		if _, err := regexp.Compile(c.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %v", err)
+		}
+	}
+	return nil
+}
+
+// Validate check the value against the type and the constraints, c can be nil,
+// an empty value is valid unless it is required
+func Validate(t Type, c *Constraints, value string) error {
+	if err := c.Check(t); err != nil {
+		return err
+	}
+	if c == nil {
+		c = &Constraints{}
+	}
+	if value == "" {
+		if c.Required {
+			return errors.New("value is required")
+		}
+		return nil
+	}
+
+	switch t {
+	case Int:
+		v, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return errors.New("value is not an integer")
+		}
+		return c.checkRange(float64(v))
+	case Float:
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return errors.New("value is not a number")
+		}
+		return c.checkRange(v)
+	case Bool:
+		if _, err := strconv.ParseBool(strings.TrimSpace(value)); err != nil {
+			return errors.New("value is not a boolean, use true or false")
+		}
+	case JSON:
+		if !json.Valid([]byte(value)) {
+			return errors.New("value is not valid json")
+		}
+	case URL:
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("value is not an http or https url")
+		}
+	case Enum:
+		if !slices.Contains(c.Options, value) {
+			return fmt.Errorf("value must be one of %s", strings.Join(c.Options, ", "))
+		}
+	case String, Secret:
+		n := utf8.RuneCountInString(value)
+		if n < c.MinLength {
+			return fmt.Errorf("value is shorter than %d characters", c.MinLength)
+		}
+		if c.MaxLength > 0 && n > c.MaxLength {
+			return fmt.Errorf("value is longer than %d characters", c.MaxLength)
+		}
+		if c.Pattern != "" && !regexp.MustCompile(c.Pattern).MatchString(value) {
+			return fmt.Errorf("value does not match %s", c.Pattern)
+		}
+	}
+	return nil
+}
+
+// Typed convert a value to its go type for json replies, int64 for int, float64 for float, bool for bool,
+// json.RawMessage for json and string for the others, the value is returned as it is if it can not be converted
+func Typed(t Type, value string) interface{} {
+	if value == "" && t != String && t != Secret {
+		return nil
+	}
+	switch t {
+	case Int:
+		if v, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+			return v
+		}
+	case Float:
+		if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+			return v
+		}
+	case Bool:
+		if v, err := strconv.ParseBool(strings.TrimSpace(value)); err == nil {
+			return v
+		}
+	case JSON:
+		if json.Valid([]byte(value)) {
+			return json.RawMessage(value)
+		}
+	}
+	return value
+}
+
+func (c *Constraints) checkRange(v float64) error {
+	if c.Min != nil && v < *c.Min {
+		return fmt.Errorf("value is less than %v", *c.Min)
+	}
+	if c.Max != nil && v > *c.Max {
+		return fmt.Errorf("value is greater than %v", *c.Max)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package confval
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		t     Type
+		c     *Constraints
+		value string
+		ok    bool
+	}{
+		{String, nil, "", true},
+		{String, &Constraints{Required: true}, "", false},
+		{String, &Constraints{MaxLength: 2}, "中文", true},
+		{String, &Constraints{MaxLength: 2}, "abc", false},
+		{String, &Constraints{MinLength: 2}, "a", false},
+		{String, &Constraints{Pattern: `^[a-z]+$`}, "abc", true},
+		{String, &Constraints{Pattern: `^[a-z]+$`}, "abc1", false},
+		{Int, nil, "12", true},
+		{Int, nil, "abc", false},
+		{Int, nil, "1.5", false},
+		{Int, &Constraints{Min: float(1), Max: float(10)}, "0", false},
+		{Int, &Constraints{Min: float(1), Max: float(10)}, "10", true},
+		{Float, &Constraints{Max: float(1)}, "0.5", true},
+		{Float, nil, "x", false},
+		{Bool, nil, "true", true},
+		{Bool, nil, "yes", false},
+		{JSON, nil, `{"a":1}`, true},
+		{JSON, nil, `{"a":`, false},
+		{URL, nil, "https://cdn.example.com", true},
+		{URL, nil, "cdn.example.com", false},
+		{URL, nil, "ftp://cdn.example.com", false},
+		{Enum, &Constraints{Options: []string{"local", "s3"}}, "s3", true},
+		{Enum, &Constraints{Options: []string{"local", "s3"}}, "oss", false},
+		{Secret, &Constraints{MinLength: 8}, "short", false},
+	}
+	for _, v := range cases {
+		err := Validate(v.t, v.c, v.value)
+		assert.Equal(t, v.ok, err == nil, "%s %+v %q: %v", v.t, v.c, v.value, err)
+	}
+}
+
+func TestConstraints_Check(t *testing.T) {
+	assert.Error(t, (*Constraints)(nil).Check("text"))
+	assert.Error(t, (*Constraints)(nil).Check(Enum))
+	assert.Error(t, (&Constraints{Min: float(2), Max: float(1)}).Check(Int))
+	assert.Error(t, (&Constraints{MinLength: 3, MaxLength: 2}).Check(String))
+	assert.Error(t, (&Constraints{Pattern: "("}).Check(String))
+	assert.NoError(t, (*Constraints)(nil).Check(Int))
+	assert.NoError(t, (&Constraints{Options: []string{"a"}}).Check(Enum))
+}
+
+func TestConstraints_Scan(t *testing.T) {
+	c := &Constraints{}
+	assert.NoError(t, c.Scan([]byte(`{"required":true,"options":["a"]}`)))
+	assert.True(t, c.Required)
+	assert.Equal(t, []string{"a"}, c.Options)
+	assert.NoError(t, c.Scan(nil))
+	assert.False(t, c.Required)
+	assert.Error(t, c.Scan(1))
+
+	v, err := Constraints{Max: float(3)}.Value()
+	assert.NoError(t, err)
+	assert.Equal(t, `{"max":3}`, string(v.([]byte)))
+}
+
+func TestTyped(t *testing.T) {
+	assert.Equal(t, int64(20), Typed(Int, " 20"))
+	assert.Equal(t, 0.5, Typed(Float, "0.5"))
+	assert.Equal(t, true, Typed(Bool, "true"))
+	assert.Equal(t, json.RawMessage(`{"a":1}`), Typed(JSON, `{"a":1}`))
+	assert.Equal(t, "abc", Typed(Int, "abc"))
+	assert.Nil(t, Typed(Int, ""))
+	assert.Equal(t, "", Typed(String, ""))
+}
@@ -0,0 +1,140 @@
+// Package enumgen generates enum.json and the typescript definitions of the frontend from the enums of constant/enum.
+package enumgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"admin/internal/types"
+)
+
+// Enums options of the enums by name, the name is the file name of the enum
+type Enums map[string][]*types.Options
+
+// Names sorted names of the enums, so the output is stable
+func (e Enums) Names() []string {
+	names := make([]string, 0, len(e))
+	for name := range e {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate every option must have a label, the label is the comment of the constant
+func (e Enums) Validate() error {
+	var errs []error
+	for _, name := range e.Names() {
+		for _, option := range e[name] {
+			if strings.TrimSpace(option.Label) == "" {
+				if option.Name != "" {
+					errs = append(errs, fmt.Errorf("%s: %s has no label comment", name, option.Name))
+				} else {
+					errs = append(errs, fmt.Errorf("%s: the constant of value %v has no label comment", name, option.Value))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// JSON the content of enum.json, it is read by the enum cache in prod
+func (e Enums) JSON() ([]byte, error) {
+	return json.MarshalIndent(map[string][]*types.Options(e), "", "  ")
+}
+
+// TypeScript const and type definitions of the enums, e.g. for gender
+//
+//	export const Gender = { Male: 1 } as const;
+//	export const GenderOptions = [{ value: 1, label: "男" }] as const;
+//	export type GenderValue = (typeof GenderOptions)[number]["value"];
+//
+// the keys of the const object are the constant names without the enum name, it is omitted if the
+// options have no constant names, e.g. they are read from enum.json of an old version
+func (e Enums) TypeScript(source string) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "// Code generated by enumgen from %s. DO NOT EDIT.\n", source)
+
+	names := e.Names()
+	for _, name := range names {
+		ident := tsIdent(name)
+		values := make([]string, 0, len(e[name]))
+		options := make([]string, 0, len(e[name]))
+		for _, option := range e[name] {
+			value, err := json.Marshal(option.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			if option.Name != "" {
+				values = append(values, fmt.Sprintf("  /** %s */\n  %s: %s,\n", option.Label, tsKey(ident, option.Name), value))
+			}
+			fields := fmt.Sprintf("value: %s, label: %s", value, strconv.Quote(option.Label))
+			if option.Disabled {
+				fields += ", disabled: true"
+			}
+			if len(option.Labels) > 0 {
+				labels, err := json.Marshal(option.Labels)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+				fields += ", labels: " + string(labels)
+			}
+			if option.Other != nil {
+				other, err := json.Marshal(option.Other)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+				fields += ", other: " + string(other)
+			}
+			options = append(options, "  { "+fields+" },\n")
+		}
+
+		fmt.Fprintf(buf, "\n/** %s */\n", name)
+		if len(values) == len(e[name]) {
+			fmt.Fprintf(buf, "export const %s = {\n%s} as const;\n", ident, strings.Join(values, ""))
+		}
+		fmt.Fprintf(buf, "export const %sOptions = [\n%s] as const;\n", ident, strings.Join(options, ""))
+		fmt.Fprintf(buf, "export type %sValue = (typeof %sOptions)[number][\"value\"];\n", ident, ident)
+	}
+
+	buf.WriteString("\n/** options of all enums by name, the same as /api/v1/config/dict without the database dictionaries */\n")
+	buf.WriteString("export const EnumOptions = {\n")
+	for _, name := range names {
+		fmt.Fprintf(buf, "  %s: %sOptions,\n", name, tsIdent(name))
+	}
+	buf.WriteString("} as const;\nexport type EnumName = keyof typeof EnumOptions;\n")
+	return buf.Bytes(), nil
+}
+
+// WriteFile write data to the file, the directory is created if it does not exist
+func WriteFile(filePath string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+// base_status -> BaseStatus
+func tsIdent(name string) string {
+	var sb strings.Builder
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' || r == '.' }) {
+		sb.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return sb.String()
+}
+
+// GenderMale -> Male, the name is kept if it does not start with the enum name or nothing is left
+func tsKey(ident, name string) string {
+	if key, ok := strings.CutPrefix(name, ident); ok && key != "" && !unicode.IsDigit(rune(key[0])) {
+		return key
+	}
+	return name
+}
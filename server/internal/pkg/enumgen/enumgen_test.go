@@ -0,0 +1,66 @@
+package enumgen
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"admin/internal/types"
+)
+
+func testEnums() Enums {
+	return Enums{
+		"gender": {
+			{Label: "男", Value: 1, Name: "GenderMale", Other: map[string]string{"color": "#409eff"}},
+			{Label: "保密", Value: 0, Name: "GenderUnknown", Disabled: true},
+		},
+		"role_code": {
+			{Label: "管理员", Value: "ADMIN"},
+		},
+	}
+}
+
+func TestEnums_Validate(t *testing.T) {
+	assert.NoError(t, testEnums().Validate())
+
+	enums := testEnums()
+	enums["whether"] = []*types.Options{{Label: "是", Value: 1}, {Label: " ", Value: 0}}
+	enums["level"] = []*types.Options{{Value: 1, Name: "LevelHigh"}}
+	err := enums.Validate()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "whether: the constant of value 0 has no label comment")
+	assert.Contains(t, err.Error(), "level: LevelHigh has no label comment")
+}
+
+func TestEnums_JSON(t *testing.T) {
+	data, err := testEnums().JSON()
+	assert.NoError(t, err)
+	result := map[string][]*types.Options{}
+	assert.NoError(t, json.Unmarshal(data, &result))
+	assert.Equal(t, "男", result["gender"][0].Label)
+}
+
+func TestEnums_TypeScript(t *testing.T) {
+	data, err := testEnums().TypeScript("enum")
+	assert.NoError(t, err)
+	ts := string(data)
+	t.Log(ts)
+	assert.True(t, strings.HasPrefix(ts, "// Code generated by enumgen from enum. DO NOT EDIT."))
+	assert.Contains(t, ts, `export const GenderOptions = [`)
+	assert.Contains(t, ts, "export const Gender = {\n  /** 男 */\n  Male: 1,\n")
+	assert.Contains(t, ts, `  { value: 1, label: "男", other: {"color":"#409eff"} },`)
+	assert.Contains(t, ts, `  { value: 0, label: "保密", disabled: true },`)
+	assert.NotContains(t, ts, "export const RoleCode = {") // no constant names
+	assert.Contains(t, ts, `  { value: "ADMIN", label: "管理员" },`)
+	assert.Contains(t, ts, `export type RoleCodeValue = (typeof RoleCodeOptions)[number]["value"];`)
+	assert.Contains(t, ts, `  role_code: RoleCodeOptions,`)
+	assert.Less(t, strings.Index(ts, "GenderOptions = "), strings.Index(ts, "RoleCodeOptions = "))
+}
+
+func TestWriteFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "a", "enum.json")
+	assert.NoError(t, WriteFile(filePath, []byte("{}")))
+}
@@ -0,0 +1,90 @@
+// Package i18n picks the locale of a request, the texts of the other locales are looked up by the callers
+// in their own catalogs (enum labels, menu titles, error code messages).
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// supported locales
+const (
+	ZhCN = "zh-CN"
+	En   = "en"
+)
+
+// Default the locale of the source texts, e.g. the enum comments and the menu names
+const Default = ZhCN
+
+// ContextKey key of the locale in gin.Context
+const ContextKey = "locale"
+
+// Normalize match a language tag such as zh, zh-Hans-CN, en-US or en_GB to a supported locale,
+// an empty string is returned if it is not supported
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	lang, _, _ := strings.Cut(tag, "-")
+	switch lang {
+	case "zh":
+		return ZhCN
+	case "en":
+		return En
+	}
+	return ""
+}
+
+// ParseAcceptLanguage the supported locale with the highest weight in an Accept-Language header
+func ParseAcceptLanguage(header string) string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		locale := Normalize(tag)
+		if locale == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if q > 0 {
+			tags = append(tags, weighted{locale: locale, q: q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+	if len(tags) == 0 {
+		return ""
+	}
+	return tags[0].locale
+}
+
+// Set the locale of the request, an unsupported locale is ignored
+func Set(c *gin.Context, locale string) {
+	if locale = Normalize(locale); locale != "" {
+		c.Set(ContextKey, locale)
+	}
+}
+
+// Get the locale of the request, empty if neither the client nor the user asked for one
+func Get(c *gin.Context) string {
+	return c.GetString(ContextKey)
+}
+
+// Pick the text of the locale in texts, fallback if there is none
+func Pick(texts map[string]string, locale, fallback string) string {
+	if locale == "" || locale == Default {
+		return fallback
+	}
+	if text := texts[locale]; text != "" {
+		return text
+	}
+	return fallback
+}
@@ -0,0 +1,33 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, ZhCN, Normalize("zh"))
+	assert.Equal(t, ZhCN, Normalize("zh-Hans-CN"))
+	assert.Equal(t, ZhCN, Normalize("zh_TW"))
+	assert.Equal(t, En, Normalize("en-US"))
+	assert.Equal(t, En, Normalize(" EN "))
+	assert.Equal(t, "", Normalize("fr"))
+	assert.Equal(t, "", Normalize(""))
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	assert.Equal(t, En, ParseAcceptLanguage("en-US,en;q=0.9,zh-CN;q=0.8"))
+	assert.Equal(t, ZhCN, ParseAcceptLanguage("fr-FR, en;q=0.5, zh;q=0.7"))
+	assert.Equal(t, ZhCN, ParseAcceptLanguage("en;q=0, zh-CN"))
+	assert.Equal(t, "", ParseAcceptLanguage("fr, de;q=0.8"))
+	assert.Equal(t, "", ParseAcceptLanguage(""))
+}
+
+func TestPick(t *testing.T) {
+	texts := map[string]string{En: "System"}
+	assert.Equal(t, "System", Pick(texts, En, "系统管理"))
+	assert.Equal(t, "系统管理", Pick(texts, ZhCN, "系统管理"))
+	assert.Equal(t, "系统管理", Pick(texts, "", "系统管理"))
+	assert.Equal(t, "系统管理", Pick(nil, En, "系统管理"))
+}
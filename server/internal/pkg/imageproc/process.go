@@ -0,0 +1,239 @@
+// Package imageproc processes uploaded images in pure Go: EXIF stripping and orientation fix,
+// thumbnails, WebP conversion and text watermarks.
+package imageproc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	"github.com/HugoSmits86/nativewebp"
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // register the webp decoder
+)
+
+const (
+	// VariantSeparator separates the original storage key and the variant suffix, e.g. 2024-11-10/a.jpg!small.webp
+	VariantSeparator = "!"
+
+	// FullVariant name of the full size variant, it is only generated in WebP
+	FullVariant = "full"
+
+	// MaxPixels larger images are stored as they are, to avoid decompression bombs
+	MaxPixels = 40_000_000
+)
+
+// ErrUnsupported the image format is not processed
+var ErrUnsupported = errors.New("imageproc: unsupported image")
+
+// Thumbnail a thumbnail size
+type Thumbnail struct {
+	Name   string `json:"name"`   // variant name, e.g. small
+	Width  int    `json:"width"`  // max width
+	Height int    `json:"height"` // max height
+	Crop   bool   `json:"crop"`   // true: fill the size and crop the center, false: fit in the size
+}
+
+// Options image pipeline options, stored as json in t_config, e.g.
+//
+//	{"stripExif": true, "webp": true, "quality": 85,
+//	 "thumbnails": [{"name": "small", "width": 150, "height": 150, "crop": true}, {"name": "medium", "width": 800, "height": 800}],
+//	 "watermark": {"text": "admin", "opacity": 0.5, "minWidth": 300}}
+type Options struct {
+	StripExif  bool        `json:"stripExif"`  // remove EXIF metadata and apply the orientation
+	WebP       bool        `json:"webp"`       // also generate WebP variants
+	Quality    int         `json:"quality"`    // jpeg quality, default 85
+	Thumbnails []Thumbnail `json:"thumbnails"` // thumbnail sizes
+	Watermark  *Watermark  `json:"watermark"`  // optional text watermark
+}
+
+// DefaultOptions options used when t_config has no pipeline configured
+func DefaultOptions() *Options {
+	return &Options{
+		StripExif: true,
+		Quality:   85,
+		Thumbnails: []Thumbnail{
+			{Name: "small", Width: 150, Height: 150, Crop: true},
+			{Name: "medium", Width: 800, Height: 800},
+		},
+	}
+}
+
+// ParseOptions parse the json value of t_config, fall back to the default options if value is empty
+func ParseOptions(value string) (*Options, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultOptions(), nil
+	}
+	o := &Options{}
+	if err := json.Unmarshal([]byte(value), o); err != nil {
+		return nil, err
+	}
+	for _, t := range o.Thumbnails {
+		if t.Name == "" || t.Name == FullVariant || strings.ContainsAny(t.Name, "./!") || t.Width <= 0 || t.Height <= 0 {
+			return nil, errors.New("imageproc: invalid thumbnail " + t.Name)
+		}
+	}
+	return o, nil
+}
+
+// Supported whether the mime type is processed, animated formats such as gif are stored as they are
+func Supported(mime string) bool {
+	return formatOf(mime) != ""
+}
+
+// Output an encoded image
+type Output struct {
+	Suffix      string // variant suffix such as small.jpg, empty for the original
+	ContentType string
+	Data        []byte
+}
+
+// Result processed original and variants
+type Result struct {
+	Original *Output // nil if the original does not need to be changed
+	Variants []*Output
+}
+
+// Process decode the image and produce the processed original and its variants
+func Process(data []byte, mime string, o *Options) (*Result, error) {
+	format := formatOf(mime)
+	if format == "" {
+		return nil, ErrUnsupported
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, ErrUnsupported
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	changed := false
+	if format == "jpeg" {
+		if orientation, hasExif := jpegOrientation(data); hasExif && o.StripExif {
+			// decoding and encoding again drops all metadata
+			img = orient(img, orientation)
+			changed = true
+		}
+	}
+	if o.Watermark.enabled(img.Bounds().Dx()) {
+		img = o.Watermark.draw(img)
+		changed = true
+	}
+	if changed {
+		out, err := encode(img, format, o.Quality)
+		if err != nil {
+			return nil, err
+		}
+		result.Original = &Output{ContentType: out.ContentType, Data: out.Data}
+	}
+
+	if o.WebP && format != "webp" {
+		out, err := encode(img, "webp", o.Quality)
+		if err != nil {
+			return nil, err
+		}
+		out.Suffix = FullVariant + ".webp"
+		result.Variants = append(result.Variants, out)
+	}
+
+	for _, t := range o.Thumbnails {
+		thumb := resize(img, t)
+		formats := []string{format}
+		if o.WebP && format != "webp" {
+			formats = append(formats, "webp")
+		}
+		for _, f := range formats {
+			out, err := encode(thumb, f, o.Quality)
+			if err != nil {
+				return nil, err
+			}
+			out.Suffix = t.Name + extOf(f)
+			result.Variants = append(result.Variants, out)
+		}
+	}
+
+	return result, nil
+}
+
+// resize scale the image into the thumbnail size, small images are not enlarged
+func resize(img image.Image, t Thumbnail) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	src := b
+
+	scale := min(float64(t.Width)/float64(w), float64(t.Height)/float64(h))
+	if t.Crop {
+		scale = max(float64(t.Width)/float64(w), float64(t.Height)/float64(h))
+	}
+	if scale >= 1 {
+		return img
+	}
+	dw, dh := max(int(float64(w)*scale), 1), max(int(float64(h)*scale), 1)
+	if t.Crop {
+		// the part of the source that covers the thumbnail, centered
+		cw, ch := int(float64(t.Width)/scale), int(float64(t.Height)/scale)
+		x0, y0 := b.Min.X+(w-cw)/2, b.Min.Y+(h-ch)/2
+		src = image.Rect(x0, y0, x0+cw, y0+ch)
+		dw, dh = t.Width, t.Height
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, dw, dh))
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), img, src, xdraw.Src, nil)
+	return dst
+}
+
+func encode(img image.Image, format string, quality int) (*Output, error) {
+	buf := &bytes.Buffer{}
+	var err error
+	switch format {
+	case "jpeg":
+		if quality <= 0 || quality > 100 {
+			quality = 85
+		}
+		err = jpeg.Encode(buf, img, &jpeg.Options{Quality: quality})
+	case "png":
+		err = png.Encode(buf, img)
+	case "webp":
+		err = nativewebp.Encode(buf, img, nil)
+	default:
+		err = ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Output{ContentType: "image/" + format, Data: buf.Bytes()}, nil
+}
+
+func formatOf(mime string) string {
+	switch strings.TrimSpace(strings.SplitN(mime, ";", 2)[0]) {
+	case "image/jpeg":
+		return "jpeg"
+	case "image/png":
+		return "png"
+	case "image/webp":
+		return "webp"
+	}
+	return ""
+}
+
+func extOf(format string) string {
+	if format == "jpeg" {
+		return ".jpg"
+	}
+	return "." + format
+}
+
+// VariantKey storage key of a variant
+func VariantKey(key, suffix string) string {
+	return key + VariantSeparator + suffix
+}
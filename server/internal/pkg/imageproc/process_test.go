@@ -0,0 +1,142 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testImage(w, h int) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	// mark the top left corner
+	img.Set(0, 0, color.NRGBA{R: 255, A: 255})
+	return img
+}
+
+// jpeg with an EXIF segment that only contains the orientation tag
+func testJPEGWithOrientation(t *testing.T, w, h, orientation int) []byte {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, jpeg.Encode(buf, testImage(w, h), &jpeg.Options{Quality: 90}))
+
+	tiff := &bytes.Buffer{}
+	tiff.WriteString("MM")
+	_ = binary.Write(tiff, binary.BigEndian, uint16(42))
+	_ = binary.Write(tiff, binary.BigEndian, uint32(8))
+	_ = binary.Write(tiff, binary.BigEndian, uint16(1))
+	_ = binary.Write(tiff, binary.BigEndian, []uint16{0x0112, 3})
+	_ = binary.Write(tiff, binary.BigEndian, uint32(1))
+	_ = binary.Write(tiff, binary.BigEndian, []uint16{uint16(orientation), 0})
+	_ = binary.Write(tiff, binary.BigEndian, uint32(0))
+
+	segment := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	data := buf.Bytes()
+	out := append([]byte{}, data[:2]...)
+	out = append(out, app1...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestJpegOrientation(t *testing.T) {
+	data := testJPEGWithOrientation(t, 4, 2, 6)
+	orientation, hasExif := jpegOrientation(data)
+	assert.True(t, hasExif)
+	assert.Equal(t, 6, orientation)
+
+	buf := &bytes.Buffer{}
+	_ = jpeg.Encode(buf, testImage(4, 2), nil)
+	orientation, hasExif = jpegOrientation(buf.Bytes())
+	assert.False(t, hasExif)
+	assert.Equal(t, 1, orientation)
+}
+
+func TestOrient(t *testing.T) {
+	src := testImage(4, 2)
+	for orientation, want := range map[int]image.Point{
+		2: {3, 0}, 3: {3, 1}, 4: {0, 1}, 5: {0, 0}, 6: {1, 0}, 7: {1, 3}, 8: {0, 3},
+	} {
+		dst := orient(src, orientation)
+		if orientation >= 5 {
+			assert.Equal(t, image.Rect(0, 0, 2, 4), dst.Bounds())
+		} else {
+			assert.Equal(t, image.Rect(0, 0, 4, 2), dst.Bounds())
+		}
+		r, _, _, _ := dst.At(want.X, want.Y).RGBA()
+		assert.Equal(t, uint32(0xffff), r, "orientation %d", orientation)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	o := &Options{
+		StripExif: true,
+		WebP:      true,
+		Thumbnails: []Thumbnail{
+			{Name: "small", Width: 50, Height: 50, Crop: true},
+			{Name: "medium", Width: 200, Height: 200},
+		},
+		Watermark: &Watermark{Text: "admin", MinWidth: 100},
+	}
+	data := testJPEGWithOrientation(t, 400, 200, 6)
+
+	result, err := Process(data, "image/jpeg", o)
+	assert.NoError(t, err)
+	assert.NotNil(t, result.Original)
+	_, hasExif := jpegOrientation(result.Original.Data)
+	assert.False(t, hasExif)
+	cfg, err := jpeg.DecodeConfig(bytes.NewReader(result.Original.Data))
+	assert.NoError(t, err)
+	assert.Equal(t, 200, cfg.Width)
+	assert.Equal(t, 400, cfg.Height)
+
+	sizes := map[string]image.Point{}
+	for _, v := range result.Variants {
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(v.Data))
+		assert.NoError(t, err, v.Suffix)
+		sizes[v.Suffix] = image.Pt(cfg.Width, cfg.Height)
+	}
+	assert.Equal(t, map[string]image.Point{
+		"full.webp":   {200, 400},
+		"small.jpg":   {50, 50},
+		"small.webp":  {50, 50},
+		"medium.jpg":  {100, 200},
+		"medium.webp": {100, 200},
+	}, sizes)
+
+	// png without anything to change keeps the original
+	buf := &bytes.Buffer{}
+	_ = png.Encode(buf, testImage(40, 20))
+	result, err = Process(buf.Bytes(), "image/png", DefaultOptions())
+	assert.NoError(t, err)
+	assert.Nil(t, result.Original)
+	assert.Len(t, result.Variants, 2)
+
+	_, err = Process([]byte("GIF89a"), "image/gif", o)
+	assert.ErrorIs(t, err, ErrUnsupported)
+}
+
+func TestParseOptions(t *testing.T) {
+	o, err := ParseOptions("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultOptions(), o)
+
+	o, err = ParseOptions(`{"webp": true, "thumbnails": [{"name": "s", "width": 10, "height": 10}]}`)
+	assert.NoError(t, err)
+	assert.True(t, o.WebP)
+
+	_, err = ParseOptions(`{"thumbnails": [{"name": "full", "width": 10, "height": 10}]}`)
+	assert.Error(t, err)
+	_, err = ParseOptions(`{"thumbnails": [{"name": "a.b", "width": 10, "height": 10}]}`)
+	assert.Error(t, err)
+}
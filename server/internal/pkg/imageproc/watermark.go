@@ -0,0 +1,70 @@
+package imageproc
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Watermark text watermark drawn at the bottom right corner
+type Watermark struct {
+	Text     string  `json:"text"`     // watermark text, only ASCII characters are supported by the built-in font
+	Opacity  float64 `json:"opacity"`  // 0~1, default 0.5
+	MinWidth int     `json:"minWidth"` // images narrower than this are not watermarked, default 300
+}
+
+func (w *Watermark) enabled(width int) bool {
+	if w == nil || w.Text == "" {
+		return false
+	}
+	minWidth := w.MinWidth
+	if minWidth <= 0 {
+		minWidth = 300
+	}
+	return width >= minWidth
+}
+
+// draw the watermark on a copy of img, the text height is about 1/30 of the image width
+func (w *Watermark) draw(img image.Image) image.Image {
+	b := img.Bounds()
+	face := basicfont.Face7x13
+	textWidth := font.MeasureString(face, w.Text).Ceil()
+	textHeight := face.Metrics().Height.Ceil()
+
+	// render the text with the built-in bitmap font, then scale it to the target size
+	text := image.NewNRGBA(image.Rect(0, 0, textWidth+2, textHeight+2))
+	d := &font.Drawer{
+		Dst:  text,
+		Src:  image.NewUniform(color.White),
+		Face: face,
+		Dot:  fixed.P(1, face.Metrics().Ascent.Ceil()+1),
+	}
+	d.DrawString(w.Text)
+
+	scale := float64(b.Dx()) / 30 / float64(textHeight)
+	if scale < 1 {
+		scale = 1
+	}
+	tw, th := int(float64(text.Bounds().Dx())*scale), int(float64(text.Bounds().Dy())*scale)
+	if tw > b.Dx() || th > b.Dy() {
+		return img
+	}
+	scaled := image.NewNRGBA(image.Rect(0, 0, tw, th))
+	xdraw.ApproxBiLinear.Scale(scaled, scaled.Bounds(), text, text.Bounds(), xdraw.Src, nil)
+
+	opacity := w.Opacity
+	if opacity <= 0 || opacity > 1 {
+		opacity = 0.5
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	margin := th / 2
+	at := image.Rect(b.Dx()-tw-margin, b.Dy()-th-margin, b.Dx()-margin, b.Dy()-margin)
+	draw.DrawMask(dst, at, scaled, image.Point{}, image.NewUniform(color.Alpha{A: uint8(opacity * 255)}), image.Point{}, draw.Over)
+	return dst
+}
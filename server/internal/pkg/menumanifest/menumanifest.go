@@ -0,0 +1,124 @@
+// Package menumanifest reads and writes the menu tree as json or yaml, the menus shipped with
+// the binary are embedded as menus.yml.
+package menumanifest
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"admin/internal/types"
+)
+
+// formats of the manifest
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// menu types, the same as t_menu.type
+var menuTypes = map[string]bool{"CATALOG": true, "MENU": true, "BUTTON": true, "EXTLINK": true}
+
+//go:embed menus.yml
+var embedded []byte
+
+// Embedded the manifest shipped with the binary
+func Embedded() (*types.MenuManifest, error) {
+	return Decode(embedded, FormatYAML)
+}
+
+// Key the key a menu is matched by. the catalogs, menus and external links are matched by their path, which is
+// unique in the routes, the buttons by their perm under the key of the parent because pages may share a perm,
+// e.g. "path:/system/menu > perm:sys:menu:add". a menu without a path falls back to its perm the same way.
+// parentKey is empty for the top level menus, an empty string is returned if the menu has neither a path nor a perm
+func Key(parentKey string, typ string, path string, perm string) string {
+	if typ != "BUTTON" && path != "" {
+		return "path:" + path
+	}
+	if perm == "" {
+		return ""
+	}
+	if parentKey == "" {
+		return "perm:" + perm
+	}
+	return parentKey + " > perm:" + perm
+}
+
+// Decode parse a manifest, the unknown fields are rejected so a typo is not silently ignored
+func Decode(data []byte, format string) (*types.MenuManifest, error) {
+	m := &types.MenuManifest{}
+	switch format {
+	case FormatYAML:
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(m); err != nil {
+			return nil, fmt.Errorf("invalid yaml manifest: %v", err)
+		}
+	case FormatJSON, "":
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(m); err != nil {
+			return nil, fmt.Errorf("invalid json manifest: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported manifest format %q", format)
+	}
+	if err := Validate(m.Menus); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Encode write a manifest
+func Encode(m *types.MenuManifest, format string) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		buf := &bytes.Buffer{}
+		enc := yaml.NewEncoder(buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(m); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case FormatJSON, "":
+		return json.MarshalIndent(m, "", "  ")
+	}
+	return nil, fmt.Errorf("unsupported manifest format %q", format)
+}
+
+// Validate every menu has a name, a known type and a key, and the keys are unique
+func Validate(items []*types.MenuManifestItem) error {
+	return validate(items, map[string]bool{}, "", "")
+}
+
+func validate(items []*types.MenuManifestItem, keys map[string]bool, parent string, parentKey string) error {
+	for i, item := range items {
+		if item == nil {
+			return fmt.Errorf("menu %s[%d] is empty", parent, i)
+		}
+		if item.Name == "" {
+			return fmt.Errorf("menu %s[%d] has no name", parent, i)
+		}
+		if !menuTypes[item.Type] {
+			return fmt.Errorf("menu %q has an unknown type %q", item.Name, item.Type)
+		}
+		key := Key(parentKey, item.Type, item.Path, item.Perm)
+		if key == "" {
+			return fmt.Errorf("menu %q has neither a path nor a perm", item.Name)
+		}
+		if keys[key] {
+			return fmt.Errorf("menu %q has a duplicate key %s", item.Name, key)
+		}
+		keys[key] = true
+		if err := validate(item.Children, keys, item.Name, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
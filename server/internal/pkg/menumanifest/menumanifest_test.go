@@ -0,0 +1,85 @@
+package menumanifest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"admin/internal/types"
+)
+
+func count(items []*types.MenuManifestItem) int {
+	n := len(items)
+	for _, item := range items {
+		n += count(item.Children)
+	}
+	return n
+}
+
+func TestEmbedded(t *testing.T) {
+	m, err := Embedded()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 19, count(m.Menus)) // the menus of admin.sql
+	assert.Equal(t, "System", m.Menus[0].Titles["en"])
+}
+
+func TestEncodeDecode(t *testing.T) {
+	visible := 0
+	m := &types.MenuManifest{Menus: []*types.MenuManifestItem{
+		{Name: "系统管理", Type: "CATALOG", Path: "/system", Visible: &visible,
+			Params: types.LocalJSON{{"key": "a", "value": "1"}},
+			Children: []*types.MenuManifestItem{
+				{Name: "新增", Type: "BUTTON", Perm: "sys:a", Titles: types.LocalText{"en": "Add"}},
+			}},
+	}}
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		data, err := Encode(m, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded, err := Decode(data, format)
+		if err != nil {
+			t.Fatal(format, err)
+		}
+		assert.Equal(t, m, decoded, format)
+	}
+
+	_, err := Encode(m, "xml")
+	assert.Error(t, err)
+}
+
+func TestDecode(t *testing.T) {
+	_, err := Decode([]byte("menus:\n  - {name: a, type: MENU, path: a, titel: b}\n"), FormatYAML)
+	assert.ErrorContains(t, err, "titel")
+	_, err = Decode([]byte(`{"menus": [{"name": "a", "type": "MENU", "path": "a", "titel": "b"}]}`), FormatJSON)
+	assert.ErrorContains(t, err, "titel")
+	_, err = Decode([]byte(`{"menus": []}`), "xml")
+	assert.Error(t, err)
+
+	// keys
+	_, err = Decode([]byte(`{"menus": [{"name": "a", "type": "CATALOG"}]}`), "")
+	assert.ErrorContains(t, err, "neither a path nor a perm")
+	_, err = Decode([]byte("menus:\n  - {name: a, type: MENU, path: a, children: [{name: b, type: MENU, path: a}]}\n"), FormatYAML)
+	assert.ErrorContains(t, err, "duplicate key path:a")
+	// pages may share the perm of a button, but not a page twice
+	_, err = Decode([]byte("menus:\n  - {name: a, type: MENU, path: a, children: [{name: x, type: BUTTON, perm: p}]}\n"+
+		"  - {name: b, type: MENU, path: b, children: [{name: x, type: BUTTON, perm: p}]}\n"), FormatYAML)
+	assert.NoError(t, err)
+	_, err = Decode([]byte("menus:\n  - {name: a, type: MENU, path: a, children: [{name: x, type: BUTTON, perm: p}, {name: y, type: BUTTON, perm: p}]}\n"), FormatYAML)
+	assert.ErrorContains(t, err, "duplicate key path:a > perm:p")
+	_, err = Decode([]byte(`{"menus": [{"name": "a", "type": "PAGE", "path": "a"}]}`), FormatJSON)
+	assert.ErrorContains(t, err, "unknown type")
+	_, err = Decode([]byte(`{"menus": [{"type": "MENU", "path": "a"}]}`), FormatJSON)
+	assert.ErrorContains(t, err, "no name")
+}
+
+func TestKey(t *testing.T) {
+	assert.Equal(t, "path:/system", Key("", "CATALOG", "/system", ""))
+	assert.Equal(t, "path:/system/menu", Key("path:/system", "MENU", "/system/menu", "sys:menu"))
+	assert.Equal(t, "perm:sys:a", Key("", "BUTTON", "ignored", "sys:a"))
+	assert.Equal(t, "path:/system > perm:sys:a", Key("path:/system", "BUTTON", "", "sys:a"))
+	assert.Equal(t, "path:/system > perm:sys:a", Key("path:/system", "MENU", "", "sys:a"))
+	assert.Equal(t, "", Key("path:/system", "MENU", "", ""))
+}
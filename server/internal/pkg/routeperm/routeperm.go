@@ -0,0 +1,194 @@
+// Package routeperm is the registry of the auth mode and the perm required by every route, the routers
+// declare their routes here next to registering them. the perms are the perms of the menus (buttons) in
+// t_menu, e.g. sys:config:add. Report compares the registry with the routes of the engine and the menus.
+package routeperm
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"admin/internal/types"
+)
+
+// auth modes of a route
+const (
+	AuthPublic = "public" // no login required
+	AuthSigned = "signed" // a signed url, the login is only required by the urls bound to an account
+	AuthLogin  = "login"  // any logged in user, no perm is required
+	AuthPerm   = "perm"   // the perm of a menu is required
+)
+
+// Rule the auth mode and the perm of a route
+type Rule struct {
+	Method string
+	Path   string // the path of gin, e.g. /api/v1/menu/:id
+	Auth   string
+	Perm   string
+}
+
+// Public a route without login
+func Public(method string, relativePath string) Rule {
+	return Rule{Method: method, Path: relativePath, Auth: AuthPublic}
+}
+
+// Signed a route with a signed url
+func Signed(method string, relativePath string) Rule {
+	return Rule{Method: method, Path: relativePath, Auth: AuthSigned}
+}
+
+// Login a route for any logged in user
+func Login(method string, relativePath string) Rule {
+	return Rule{Method: method, Path: relativePath, Auth: AuthLogin}
+}
+
+// Perm a route requiring the perm
+func Perm(method string, relativePath string, perm string) Rule {
+	return Rule{Method: method, Path: relativePath, Auth: AuthPerm, Perm: perm}
+}
+
+var (
+	mu     sync.RWMutex
+	rules  = map[string]Rule{}
+	routes gin.RoutesInfo
+)
+
+func ruleKey(method string, fullPath string) string {
+	return method + " " + fullPath
+}
+
+// Declare the rules of the routes of a group, the paths are relative to the group as the routes of gin.
+// a rule declared again replaces the previous one, so the router can be built more than once
+func Declare(group *gin.RouterGroup, groupRules ...Rule) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, rule := range groupRules {
+		rule.Path = joinPaths(group.BasePath(), rule.Path)
+		rules[ruleKey(rule.Method, rule.Path)] = rule
+	}
+}
+
+// joinPaths the same as the paths of the routes of gin, a trailing slash is kept
+func joinPaths(base string, relativePath string) string {
+	if relativePath == "" {
+		return base
+	}
+	p := path.Join(base, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(p, "/") {
+		return p + "/"
+	}
+	return p
+}
+
+// Lookup the rule of a route, fullPath is the path of gin, e.g. c.FullPath()
+func Lookup(method string, fullPath string) (Rule, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	rule, ok := rules[ruleKey(method, fullPath)]
+	return rule, ok
+}
+
+// Rules all the declared rules ordered by path and method
+func Rules() []Rule {
+	mu.RLock()
+	defer mu.RUnlock()
+	list := make([]Rule, 0, len(rules))
+	for _, rule := range rules {
+		list = append(list, rule)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Path != list[j].Path {
+			return list[i].Path < list[j].Path
+		}
+		return list[i].Method < list[j].Method
+	})
+	return list
+}
+
+// SetRoutes keep the routes of the engine after all the routes are registered
+func SetRoutes(r gin.RoutesInfo) {
+	mu.Lock()
+	defer mu.Unlock()
+	routes = r
+}
+
+// Routes the routes of the engine kept by SetRoutes
+func Routes() gin.RoutesInfo {
+	mu.RLock()
+	defer mu.RUnlock()
+	return routes
+}
+
+// Report every route with its auth mode and perm. it flags the routes that are not declared, the routes
+// any logged in user can access, the declared rules without a route, the perms required by the routes
+// that no menu has and the perms of the menus that no route requires
+func Report(engineRoutes gin.RoutesInfo, menuPerms []string) *types.RoutePermReport {
+	report := &types.RoutePermReport{
+		Routes:       []*types.RoutePermItem{},
+		Undeclared:   []*types.RoutePermItem{},
+		WithoutPerm:  []*types.RoutePermItem{},
+		StaleRules:   []*types.RoutePermItem{},
+		MissingPerms: []string{},
+		UnusedPerms:  []string{},
+	}
+	inMenus := make(map[string]bool, len(menuPerms))
+	for _, perm := range menuPerms {
+		if perm != "" {
+			inMenus[perm] = true
+		}
+	}
+
+	required := map[string]bool{}
+	found := map[string]bool{}
+	for _, route := range engineRoutes {
+		item := &types.RoutePermItem{Method: route.Method, Path: route.Path, Handler: route.Handler}
+		rule, ok := Lookup(route.Method, route.Path)
+		if ok {
+			found[ruleKey(rule.Method, rule.Path)] = true
+			item.Auth, item.Perm = rule.Auth, rule.Perm
+			item.PermInMenus = inMenus[rule.Perm]
+		}
+		report.Routes = append(report.Routes, item)
+
+		switch {
+		case !ok:
+			report.Undeclared = append(report.Undeclared, item)
+			report.WithoutPerm = append(report.WithoutPerm, item)
+		case item.Auth == AuthLogin || (item.Auth == AuthPerm && item.Perm == ""):
+			report.WithoutPerm = append(report.WithoutPerm, item)
+		}
+		if item.Perm != "" {
+			required[item.Perm] = true
+		}
+	}
+	sort.Slice(report.Routes, func(i, j int) bool {
+		if report.Routes[i].Path != report.Routes[j].Path {
+			return report.Routes[i].Path < report.Routes[j].Path
+		}
+		return report.Routes[i].Method < report.Routes[j].Method
+	})
+
+	for _, rule := range Rules() {
+		if !found[ruleKey(rule.Method, rule.Path)] {
+			report.StaleRules = append(report.StaleRules, &types.RoutePermItem{
+				Method: rule.Method, Path: rule.Path, Auth: rule.Auth, Perm: rule.Perm, PermInMenus: inMenus[rule.Perm],
+			})
+		}
+	}
+	for perm := range required {
+		if !inMenus[perm] {
+			report.MissingPerms = append(report.MissingPerms, perm)
+		}
+	}
+	for perm := range inMenus {
+		if !required[perm] {
+			report.UnusedPerms = append(report.UnusedPerms, perm)
+		}
+	}
+	sort.Strings(report.MissingPerms)
+	sort.Strings(report.UnusedPerms)
+	return report
+}
@@ -0,0 +1,78 @@
+package routeperm
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeclare(t *testing.T) {
+	r := gin.New()
+	g := r.Group("/api/v1/test")
+	Declare(g,
+		Perm(http.MethodPost, "", "sys:test:add"),
+		Login(http.MethodGet, "/options"),
+		Public(http.MethodGet, "/public/"),
+	)
+
+	rule, ok := Lookup(http.MethodPost, "/api/v1/test")
+	assert.True(t, ok)
+	assert.Equal(t, AuthPerm, rule.Auth)
+	assert.Equal(t, "sys:test:add", rule.Perm)
+
+	rule, ok = Lookup(http.MethodGet, "/api/v1/test/options")
+	assert.True(t, ok)
+	assert.Equal(t, AuthLogin, rule.Auth)
+
+	_, ok = Lookup(http.MethodGet, "/api/v1/test/public/")
+	assert.True(t, ok)
+	_, ok = Lookup(http.MethodGet, "/api/v1/test")
+	assert.False(t, ok)
+
+	// declared again
+	Declare(g, Perm(http.MethodPost, "", "sys:test:create"))
+	rule, _ = Lookup(http.MethodPost, "/api/v1/test")
+	assert.Equal(t, "sys:test:create", rule.Perm)
+}
+
+func TestReport(t *testing.T) {
+	handler := func(c *gin.Context) {}
+	r := gin.New()
+	r.GET("/health", handler)
+	g := r.Group("/api/v1/report")
+	g.POST("", handler)
+	g.DELETE("/:id", handler)
+	g.GET("/options", handler)
+	g.GET("/undeclared", handler)
+
+	Declare(&r.RouterGroup, Public(http.MethodGet, "/health"))
+	Declare(g,
+		Perm(http.MethodPost, "", "sys:report:add"),
+		Perm(http.MethodDelete, "/:id", "sys:report:delete"),
+		Login(http.MethodGet, "/options"),
+		Perm(http.MethodPut, "/:id", "sys:report:edit"), // no route
+	)
+	SetRoutes(r.Routes())
+	assert.Len(t, Routes(), 5)
+
+	report := Report(Routes(), []string{"sys:report:add", "sys:report:unused", ""})
+	assert.Len(t, report.Routes, 5)
+	assert.Equal(t, "/api/v1/report", report.Routes[0].Path)
+	assert.True(t, report.Routes[0].PermInMenus)
+
+	if assert.Len(t, report.Undeclared, 1) {
+		assert.Equal(t, "/api/v1/report/undeclared", report.Undeclared[0].Path)
+	}
+	assert.Len(t, report.WithoutPerm, 2)
+	assert.Equal(t, []string{"sys:report:delete"}, report.MissingPerms)
+	assert.Equal(t, []string{"sys:report:unused"}, report.UnusedPerms)
+
+	var stale []string
+	for _, item := range report.StaleRules {
+		stale = append(stale, item.Method+" "+item.Path)
+	}
+	assert.Contains(t, stale, "PUT /api/v1/report/:id")
+	assert.NotContains(t, stale, "GET /health")
+}
@@ -0,0 +1,84 @@
+// Package signurl signs and verifies expiring download urls of private files with HMAC-SHA256.
+package signurl
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// ParamExpires unix time when the url expires
+	ParamExpires = "expires"
+	// ParamAccount account id the url is bound to, absent if the url is not bound
+	ParamAccount = "account"
+	// ParamSign signature of the url
+	ParamSign = "sign"
+)
+
+var (
+	// ErrInvalidSign the signature is missing or does not match
+	ErrInvalidSign = errors.New("signurl: invalid signature")
+	// ErrExpired the url has expired
+	ErrExpired = errors.New("signurl: url expired")
+	// ErrAccount the url is bound to another account
+	ErrAccount = errors.New("signurl: account mismatch")
+)
+
+// Signer sign and verify urls with a secret key
+type Signer struct {
+	key []byte
+}
+
+// New create a signer, key must be kept secret
+func New(key string) *Signer {
+	return &Signer{key: []byte(key)}
+}
+
+// Sign return the query of a signed url of the file id, accountID 0 means anyone who has the url can use it
+func (s *Signer) Sign(id uint64, accountID uint64, expiredAt time.Time) url.Values {
+	expires := expiredAt.Unix()
+	values := url.Values{}
+	values.Set(ParamExpires, strconv.FormatInt(expires, 10))
+	if accountID > 0 {
+		values.Set(ParamAccount, strconv.FormatUint(accountID, 10))
+	}
+	values.Set(ParamSign, s.sign(id, accountID, expires))
+	return values
+}
+
+// Verify check the query of a signed url, accountID is the account of the requester, 0 if not logged in
+func (s *Signer) Verify(id uint64, values url.Values, accountID uint64, now time.Time) error {
+	expires, err := strconv.ParseInt(values.Get(ParamExpires), 10, 64)
+	if err != nil {
+		return ErrInvalidSign
+	}
+	var boundID uint64
+	if v := values.Get(ParamAccount); v != "" {
+		if boundID, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return ErrInvalidSign
+		}
+	}
+
+	expected := s.sign(id, boundID, expires)
+	if !hmac.Equal([]byte(expected), []byte(values.Get(ParamSign))) {
+		return ErrInvalidSign
+	}
+	if now.Unix() > expires {
+		return ErrExpired
+	}
+	if boundID > 0 && boundID != accountID {
+		return ErrAccount
+	}
+	return nil
+}
+
+func (s *Signer) sign(id uint64, accountID uint64, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(strconv.FormatUint(id, 10) + "\n" + strconv.FormatUint(accountID, 10) + "\n" + strconv.FormatInt(expires, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,37 @@
+package signurl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigner(t *testing.T) {
+	s := New("secret")
+	now := time.Now()
+
+	values := s.Sign(1, 0, now.Add(time.Minute))
+	assert.Empty(t, values.Get(ParamAccount))
+	assert.NoError(t, s.Verify(1, values, 0, now))
+	assert.NoError(t, s.Verify(1, values, 2, now))
+	assert.ErrorIs(t, s.Verify(2, values, 0, now), ErrInvalidSign)
+	assert.ErrorIs(t, s.Verify(1, values, 0, now.Add(2*time.Minute)), ErrExpired)
+	assert.ErrorIs(t, New("other").Verify(1, values, 0, now), ErrInvalidSign)
+
+	// tampered expiry
+	values.Set(ParamExpires, "9999999999")
+	assert.ErrorIs(t, s.Verify(1, values, 0, now), ErrInvalidSign)
+
+	// bound to an account
+	values = s.Sign(1, 7, now.Add(time.Minute))
+	assert.Equal(t, "7", values.Get(ParamAccount))
+	assert.NoError(t, s.Verify(1, values, 7, now))
+	assert.ErrorIs(t, s.Verify(1, values, 0, now), ErrAccount)
+	assert.ErrorIs(t, s.Verify(1, values, 8, now), ErrAccount)
+	values.Del(ParamAccount)
+	assert.ErrorIs(t, s.Verify(1, values, 0, now), ErrInvalidSign)
+
+	values.Del(ParamExpires)
+	assert.ErrorIs(t, s.Verify(1, values, 0, now), ErrInvalidSign)
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var _ Storage = (*localStorage)(nil)
+
+type localStorage struct {
+	root string
+}
+
+// NewLocal create a storage that keeps objects under the root directory
+func NewLocal(root string) Storage {
+	if root == "" {
+		root = "uploads"
+	}
+	return &localStorage{root: root}
+}
+
+func (s *localStorage) path(key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
+
+// Put write the object to a temporary file first, then rename it, readers never see a partial file
+func (s *localStorage) Put(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filePath)
+}
+
+// Open an object
+func (s *localStorage) Open(_ context.Context, key string) (Object, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if stat.IsDir() {
+		_ = f.Close()
+		return nil, ErrNotFound
+	}
+	key, _ = CleanKey(key)
+	return &localObject{File: f, info: fileInfo(key, stat)}, nil
+}
+
+// Stat get object metadata
+func (s *localStorage) Stat(_ context.Context, key string) (*ObjectInfo, error) {
+	filePath, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	if stat.IsDir() {
+		return nil, ErrNotFound
+	}
+	key, _ = CleanKey(key)
+	return fileInfo(key, stat), nil
+}
+
+// Delete an object
+func (s *localStorage) Delete(_ context.Context, key string) error {
+	filePath, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(filePath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List objects by key prefix
+func (s *localStorage) List(ctx context.Context, prefix string, fn func(info *ObjectInfo) error) error {
+	// walk only the deepest directory covered by the prefix
+	dir := s.root
+	if i := strings.LastIndex(prefix, "/"); i > 0 {
+		dir = filepath.Join(s.root, filepath.FromSlash(path.Clean(prefix[:i])))
+	}
+
+	err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, filePath)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, prefix) {
+			return nil
+		}
+		stat, err := d.Info()
+		if err != nil {
+			return err
+		}
+		return fn(fileInfo(key, stat))
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func fileInfo(key string, stat fs.FileInfo) *ObjectInfo {
+	return &ObjectInfo{
+		Key:          key,
+		Size:         stat.Size(),
+		ContentType:  mime.TypeByExtension(path.Ext(key)),
+		LastModified: stat.ModTime(),
+	}
+}
+
+type localObject struct {
+	*os.File
+	info *ObjectInfo
+}
+
+func (o *localObject) Info() *ObjectInfo {
+	return o.info
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCleanKey(t *testing.T) {
+	key, err := CleanKey("/2024-11-10//a.png")
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-11-10/a.png", key)
+
+	for _, v := range []string{"", "/", "../a.png", "a/../../b.png", "a\\..\\b.png"} {
+		_, err = CleanKey(v)
+		assert.ErrorIs(t, err, ErrInvalidKey, v)
+	}
+}
+
+func TestLocalStorage(t *testing.T) {
+	ctx := context.Background()
+	s := NewLocal(t.TempDir())
+	testStorage(t, ctx, s)
+}
+
+// testStorage the behavior every backend must share
+func testStorage(t *testing.T, ctx context.Context, s Storage) {
+	content := "hello storage"
+	err := s.Put(ctx, "2024-11-10/a.txt", strings.NewReader(content), int64(len(content)), "text/plain")
+	assert.NoError(t, err)
+	err = s.Put(ctx, "2024-11-11/b.txt", strings.NewReader("b"), -1, "")
+	assert.NoError(t, err)
+
+	info, err := s.Stat(ctx, "2024-11-10/a.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(content)), info.Size)
+	assert.Equal(t, "2024-11-10/a.txt", info.Key)
+
+	obj, err := s.Open(ctx, "2024-11-10/a.txt")
+	assert.NoError(t, err)
+	_, err = obj.Seek(6, io.SeekStart)
+	assert.NoError(t, err)
+	data, err := io.ReadAll(obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "storage", string(data))
+	_, err = obj.Seek(0, io.SeekStart)
+	assert.NoError(t, err)
+	data, err = io.ReadAll(obj)
+	assert.NoError(t, err)
+	assert.Equal(t, content, string(data))
+	assert.NoError(t, obj.Close())
+
+	var keys []string
+	err = s.List(ctx, "2024-11-1", func(info *ObjectInfo) error {
+		keys = append(keys, info.Key)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"2024-11-10/a.txt", "2024-11-11/b.txt"}, keys)
+
+	keys = nil
+	err = s.List(ctx, "2024-11-11/", func(info *ObjectInfo) error {
+		keys = append(keys, info.Key)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"2024-11-11/b.txt"}, keys)
+
+	assert.NoError(t, s.Delete(ctx, "2024-11-10/a.txt"))
+	assert.NoError(t, s.Delete(ctx, "2024-11-10/a.txt"))
+	_, err = s.Stat(ctx, "2024-11-10/a.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+	_, err = s.Open(ctx, "2024-11-10/a.txt")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = s.Open(ctx, "../etc/passwd")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
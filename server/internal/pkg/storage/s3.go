@@ -0,0 +1,427 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	s3Service       = "s3"
+	s3Algorithm     = "AWS4-HMAC-SHA256"
+	s3TimeFormat    = "20060102T150405Z"
+	s3DateFormat    = "20060102"
+	s3UnsignedBody  = "UNSIGNED-PAYLOAD"
+	s3EmptyBodyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// S3Options S3 compatible storage settings
+type S3Options struct {
+	Endpoint  string // e.g. https://s3.us-east-1.amazonaws.com or http://127.0.0.1:9000
+	Region    string // default us-east-1
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	PathStyle bool   // use endpoint/bucket/key instead of bucket.endpoint/key, required by MinIO
+	Prefix    string // optional key prefix inside the bucket
+
+	HTTPClient *http.Client // optional
+}
+
+var _ Storage = (*s3Storage)(nil)
+
+type s3Storage struct {
+	opts     S3Options
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3 create a S3 compatible storage, requests are signed with AWS signature v4
+func NewS3(opts S3Options) (Storage, error) {
+	if opts.Bucket == "" {
+		return nil, errors.New("storage: s3 bucket is required")
+	}
+	endpoint, err := url.Parse(opts.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("storage: invalid s3 endpoint %q", opts.Endpoint)
+	}
+	if opts.Region == "" {
+		opts.Region = "us-east-1"
+	}
+	opts.Prefix = strings.Trim(opts.Prefix, "/")
+	client := opts.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	return &s3Storage{opts: opts, endpoint: endpoint, client: client, now: time.Now}, nil
+}
+
+func (s *s3Storage) objectKey(key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	if s.opts.Prefix != "" {
+		key = s.opts.Prefix + "/" + key
+	}
+	return key, nil
+}
+
+func (s *s3Storage) trimPrefix(key string) string {
+	if s.opts.Prefix == "" {
+		return key
+	}
+	return strings.TrimPrefix(key, s.opts.Prefix+"/")
+}
+
+func (s *s3Storage) objectURL(objectKey string, query url.Values) *url.URL {
+	u := *s.endpoint
+	basePath := strings.TrimRight(u.Path, "/")
+	if s.opts.PathStyle {
+		u.Path = basePath + "/" + s.opts.Bucket + "/" + objectKey
+	} else {
+		u.Host = s.opts.Bucket + "." + u.Host
+		u.Path = basePath + "/" + objectKey
+	}
+	u.RawPath = uriEncode(u.Path, false) // send exactly the form that is signed
+	u.RawQuery = ""
+	if len(query) > 0 {
+		u.RawQuery = canonicalQuery(query)
+	}
+	return &u
+}
+
+func (s *s3Storage) do(ctx context.Context, method string, u *url.URL, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	payloadHash := s3EmptyBodyHash
+	if body != nil {
+		payloadHash = s3UnsignedBody
+	}
+	signV4(req, s.opts.AccessKey, s.opts.SecretKey, s.opts.Region, payloadHash, s.now())
+	return s.client.Do(req)
+}
+
+// Put upload an object, when size is unknown the body is buffered because S3 requires a content length
+func (s *s3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return err
+	}
+	if size < 0 {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		r, size = bytes.NewReader(data), int64(len(data))
+	}
+
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(ctx, http.MethodPut, s.objectURL(objectKey, nil), header, r, size)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint
+	return checkS3Response(resp)
+}
+
+// Open an object, the content is fetched lazily with ranged GET requests
+func (s *s3Storage) Open(ctx context.Context, key string) (Object, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	objectKey, _ := s.objectKey(key)
+	return &s3Object{ctx: ctx, s: s, objectKey: objectKey, info: info}, nil
+}
+
+// Stat get object metadata with a HEAD request
+func (s *s3Storage) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(ctx, http.MethodHead, s.objectURL(objectKey, nil), nil, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint
+	if err = checkS3Response(resp); err != nil {
+		return nil, err
+	}
+
+	info := &ObjectInfo{
+		Key:         s.trimPrefix(objectKey),
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if t, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = t
+	}
+	return info, nil
+}
+
+// Delete an object
+func (s *s3Storage) Delete(ctx context.Context, key string) error {
+	objectKey, err := s.objectKey(key)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(ctx, http.MethodDelete, s.objectURL(objectKey, nil), nil, nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint
+	err = checkS3Response(resp)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+type s3ListResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+}
+
+// List objects with ListObjectsV2
+func (s *s3Storage) List(ctx context.Context, prefix string, fn func(info *ObjectInfo) error) error {
+	if s.opts.Prefix != "" {
+		prefix = s.opts.Prefix + "/" + prefix
+	}
+
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		u := s.objectURL("", query)
+		resp, err := s.do(ctx, http.MethodGet, u, nil, nil, 0)
+		if err != nil {
+			return err
+		}
+		result := &s3ListResult{}
+		err = checkS3Response(resp)
+		if err == nil {
+			err = xml.NewDecoder(resp.Body).Decode(result)
+		}
+		_ = resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, v := range result.Contents {
+			if strings.HasSuffix(v.Key, "/") {
+				continue
+			}
+			err = fn(&ObjectInfo{Key: s.trimPrefix(v.Key), Size: v.Size, LastModified: v.LastModified})
+			if err != nil {
+				return err
+			}
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+func checkS3Response(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("storage: s3 request failed, status=%d, body=%s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// s3Object implements io.ReadSeekCloser on top of ranged GET requests
+type s3Object struct {
+	ctx       context.Context
+	s         *s3Storage
+	objectKey string
+	info      *ObjectInfo
+
+	offset int64
+	body   io.ReadCloser
+}
+
+func (o *s3Object) Info() *ObjectInfo {
+	return o.info
+}
+
+func (o *s3Object) Read(p []byte) (int, error) {
+	if o.offset >= o.info.Size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		header := http.Header{}
+		header.Set("Range", "bytes="+strconv.FormatInt(o.offset, 10)+"-")
+		resp, err := o.s.do(o.ctx, http.MethodGet, o.s.objectURL(o.objectKey, nil), header, nil, 0)
+		if err != nil {
+			return 0, err
+		}
+		if err = checkS3Response(resp); err != nil {
+			_ = resp.Body.Close()
+			return 0, err
+		}
+		o.body = resp.Body
+	}
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+func (o *s3Object) Seek(offset int64, whence int) (int64, error) {
+	var abs int64
+	switch whence {
+	case io.SeekStart:
+		abs = offset
+	case io.SeekCurrent:
+		abs = o.offset + offset
+	case io.SeekEnd:
+		abs = o.info.Size + offset
+	default:
+		return 0, errors.New("storage: invalid whence")
+	}
+	if abs < 0 {
+		return 0, errors.New("storage: negative position")
+	}
+	if abs != o.offset && o.body != nil {
+		_ = o.body.Close()
+		o.body = nil
+	}
+	o.offset = abs
+	return abs, nil
+}
+
+func (o *s3Object) Close() error {
+	if o.body != nil {
+		err := o.body.Close()
+		o.body = nil
+		return err
+	}
+	return nil
+}
+
+// signV4 sign the request with AWS signature version 4, the host, range and x-amz-* headers are signed
+func signV4(req *http.Request, accessKey, secretKey, region, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(s3TimeFormat)
+	date := now.Format(s3DateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for k, v := range req.Header {
+		lk := strings.ToLower(k)
+		if lk == "range" || strings.HasPrefix(lk, "x-amz-") {
+			headers[lk] = strings.TrimSpace(strings.Join(v, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + s3Service + "/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := s3Algorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, s3Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", s3Algorithm+" Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, k := range keys {
+		values := query[k]
+		sort.Strings(values)
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(k, true)+"="+uriEncode(v, true))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode encode every byte except the unreserved characters, as required by signature v4
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' || (c == '/' && !encodeSlash) {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
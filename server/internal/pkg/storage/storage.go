@@ -0,0 +1,65 @@
+// Package storage provides pluggable object storage backends for uploaded files,
+// currently the local filesystem and S3 compatible services (AWS S3, MinIO, OSS, COS ...).
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound object does not exist
+	ErrNotFound = errors.New("storage: object not found")
+	// ErrInvalidKey object key is empty or escapes the storage root
+	ErrInvalidKey = errors.New("storage: invalid object key")
+)
+
+// ObjectInfo object metadata
+type ObjectInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ContentType  string    `json:"contentType"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// Object an opened object, Seek is supported so that it can be used with http.ServeContent
+type Object interface {
+	io.ReadSeekCloser
+	Info() *ObjectInfo
+}
+
+// Storage object storage interface, keys are slash separated relative paths such as "2024-11-10/a.png"
+type Storage interface {
+	// Put write an object, size can be -1 if unknown
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open an object for reading, return ErrNotFound if the object does not exist
+	Open(ctx context.Context, key string) (Object, error)
+	// Stat get object metadata, return ErrNotFound if the object does not exist
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// Delete an object, deleting an object that does not exist is not an error
+	Delete(ctx context.Context, key string) error
+	// List call fn for every object whose key starts with prefix
+	List(ctx context.Context, prefix string, fn func(info *ObjectInfo) error) error
+}
+
+// CleanKey normalize an object key and reject keys that would escape the storage root
+func CleanKey(key string) (string, error) {
+	key = strings.ReplaceAll(key, "\\", "/")
+	if key == "" {
+		return "", ErrInvalidKey
+	}
+	for _, v := range strings.Split(key, "/") {
+		if v == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	key = strings.TrimPrefix(path.Clean("/"+key), "/")
+	if key == "" || key == "." {
+		return "", ErrInvalidKey
+	}
+	return key, nil
+}
@@ -0,0 +1,154 @@
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"admin/internal/pkg/storage"
+)
+
+const (
+	// ChunkPrefix storage key prefix of uploaded chunks, keys starting with "_" are never served
+	ChunkPrefix = "_chunks/"
+
+	// DefaultChunkSize default chunk size, 5M is also the minimum part size of S3 multipart upload
+	DefaultChunkSize int64 = 5 << 20
+	// MinChunkSize minimum chunk size
+	MinChunkSize int64 = 1 << 20
+	// MaxChunkSize maximum chunk size
+	MaxChunkSize int64 = 50 << 20
+
+	// SessionTTL unfinished upload sessions and their chunks are removed after the time
+	SessionTTL = 24 * time.Hour
+)
+
+// ChunkDir storage key prefix of the chunks of an upload session
+func ChunkDir(uploadID string) string {
+	return ChunkPrefix + uploadID + "/"
+}
+
+// ChunkKey storage key of a chunk, the index is zero padded so that keys are listed in order
+func ChunkKey(uploadID string, index int) string {
+	return fmt.Sprintf("%s%06d", ChunkDir(uploadID), index)
+}
+
+// ChunkIndex parse the chunk index from a chunk key
+func ChunkIndex(key string) (int, bool) {
+	if !strings.HasPrefix(key, ChunkPrefix) {
+		return 0, false
+	}
+	index, err := strconv.Atoi(path.Base(key))
+	if err != nil {
+		return 0, false
+	}
+	return index, true
+}
+
+// ChunkCount number of chunks of a file
+func ChunkCount(size, chunkSize int64) int {
+	if size <= 0 || chunkSize <= 0 {
+		return 0
+	}
+	return int((size + chunkSize - 1) / chunkSize)
+}
+
+// ChunkLen expected length of the chunk at index
+func ChunkLen(size, chunkSize int64, index int) int64 {
+	count := ChunkCount(size, chunkSize)
+	if index < 0 || index >= count {
+		return 0
+	}
+	if index == count-1 {
+		return size - int64(count-1)*chunkSize
+	}
+	return chunkSize
+}
+
+// ChunkReader read the chunks of an upload session in order as one stream,
+// chunks are opened one at a time so that any number of chunks can be assembled
+type ChunkReader struct {
+	ctx      context.Context
+	store    storage.Storage
+	uploadID string
+	count    int
+	index    int
+	cur      storage.Object
+}
+
+// NewChunkReader create a reader of chunks 0 to count-1
+func NewChunkReader(ctx context.Context, store storage.Storage, uploadID string, count int) *ChunkReader {
+	return &ChunkReader{ctx: ctx, store: store, uploadID: uploadID, count: count}
+}
+
+// Read implements io.Reader
+func (r *ChunkReader) Read(p []byte) (int, error) {
+	for {
+		if r.cur == nil {
+			if r.index >= r.count {
+				return 0, io.EOF
+			}
+			obj, err := r.store.Open(r.ctx, ChunkKey(r.uploadID, r.index))
+			if err != nil {
+				return 0, err
+			}
+			r.cur = obj
+			r.index++
+		}
+		n, err := r.cur.Read(p)
+		if errors.Is(err, io.EOF) {
+			_ = r.cur.Close()
+			r.cur = nil
+			if n > 0 {
+				return n, nil
+			}
+			continue
+		}
+		return n, err
+	}
+}
+
+// Close the chunk being read
+func (r *ChunkReader) Close() error {
+	if r.cur != nil {
+		err := r.cur.Close()
+		r.cur = nil
+		return err
+	}
+	return nil
+}
+
+// ListChunks uploaded chunk indexes and sizes of an upload session
+func ListChunks(ctx context.Context, store storage.Storage, uploadID string) (map[int]int64, error) {
+	chunks := map[int]int64{}
+	err := store.List(ctx, ChunkDir(uploadID), func(info *storage.ObjectInfo) error {
+		if index, ok := ChunkIndex(info.Key); ok {
+			chunks[index] = info.Size
+		}
+		return nil
+	})
+	return chunks, err
+}
+
+// RemoveChunks delete all chunks of an upload session
+func RemoveChunks(ctx context.Context, store storage.Storage, uploadID string) error {
+	var keys []string
+	err := store.List(ctx, ChunkDir(uploadID), func(info *storage.ObjectInfo) error {
+		keys = append(keys, info.Key)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err = store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package upload
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"admin/internal/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunk(t *testing.T) {
+	key := ChunkKey("abc", 12)
+	assert.Equal(t, "_chunks/abc/000012", key)
+	index, ok := ChunkIndex(key)
+	assert.True(t, ok)
+	assert.Equal(t, 12, index)
+	_, ok = ChunkIndex("2024-11-10/a.png")
+	assert.False(t, ok)
+
+	assert.Equal(t, 3, ChunkCount(11, 5))
+	assert.Equal(t, 2, ChunkCount(10, 5))
+	assert.Equal(t, 0, ChunkCount(0, 5))
+	assert.Equal(t, int64(5), ChunkLen(11, 5, 0))
+	assert.Equal(t, int64(1), ChunkLen(11, 5, 2))
+	assert.Equal(t, int64(0), ChunkLen(11, 5, 3))
+	assert.Equal(t, int64(5), ChunkLen(10, 5, 1))
+}
+
+func TestChunkReader(t *testing.T) {
+	ctx := context.Background()
+	store := storage.NewLocal(t.TempDir())
+	content := "hello chunked upload"
+	var chunkSize int64 = 6
+	count := ChunkCount(int64(len(content)), chunkSize)
+	for i := 0; i < count; i++ {
+		start := int64(i) * chunkSize
+		chunk := content[start : start+ChunkLen(int64(len(content)), chunkSize, i)]
+		assert.NoError(t, store.Put(ctx, ChunkKey("abc", i), strings.NewReader(chunk), int64(len(chunk)), ""))
+	}
+
+	chunks, err := ListChunks(ctx, store, "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]int64{0: 6, 1: 6, 2: 6, 3: 2}, chunks)
+
+	r := NewChunkReader(ctx, store, "abc", count)
+	data, err := io.ReadAll(r)
+	assert.NoError(t, err)
+	assert.NoError(t, r.Close())
+	assert.Equal(t, content, string(data))
+
+	assert.NoError(t, RemoveChunks(ctx, store, "abc"))
+	chunks, err = ListChunks(ctx, store, "abc")
+	assert.NoError(t, err)
+	assert.Empty(t, chunks)
+
+	// missing chunk
+	r = NewChunkReader(ctx, store, "abc", 1)
+	_, err = io.ReadAll(r)
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
@@ -0,0 +1,164 @@
+// Package upload provides the validation policy of uploaded files.
+package upload
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+const (
+	// CategoryAvatar avatar images
+	CategoryAvatar = "avatar"
+	// CategoryAttachment any other files
+	CategoryAttachment = "attachment"
+
+	// SniffLen number of bytes read to detect the content type
+	SniffLen = 3072
+)
+
+var (
+	// ErrTooLarge file size exceeds the policy
+	ErrTooLarge = errors.New("upload: file too large")
+	// ErrTypeNotAllowed the content type is not allowed by the policy
+	ErrTypeNotAllowed = errors.New("upload: file type not allowed")
+)
+
+// blocked types are rejected regardless of the policy, they could run in the browser or on the server
+var blockedTypes = []string{
+	"text/html",
+	"image/svg+xml",
+	"application/xhtml+xml",
+	"text/xml",
+	"application/xml",
+	"application/javascript",
+	"text/javascript",
+	"application/x-sh",
+	"text/x-shellscript",
+	"text/x-php",
+	"text/x-python",
+	"text/x-perl",
+	"text/x-lua",
+	"text/x-tcl",
+	"application/x-msdownload",
+	"application/vnd.microsoft.portable-executable",
+	"application/x-elf",
+	"application/x-executable",
+	"application/x-mach-binary",
+	"application/java-archive",
+	"application/x-java-applet",
+	"application/wasm",
+}
+
+var blockedExts = map[string]struct{}{
+	".html": {}, ".htm": {}, ".xhtml": {}, ".shtml": {}, ".svg": {}, ".svgz": {}, ".xml": {},
+	".js": {}, ".mjs": {}, ".php": {}, ".jsp": {}, ".asp": {}, ".aspx": {}, ".sh": {}, ".bat": {}, ".cmd": {},
+	".exe": {}, ".dll": {}, ".com": {}, ".msi": {}, ".jar": {}, ".py": {}, ".pl": {}, ".cgi": {},
+}
+
+// Policy upload policy of a category, stored as json in t_config, e.g.
+//
+//	{"maxSize": 2097152, "allowTypes": ["image/png", "image/jpeg", "image/*"]}
+type Policy struct {
+	MaxSize    int64    `json:"maxSize"`    // max file size in bytes, 0 means no limit
+	AllowTypes []string `json:"allowTypes"` // allowed mime types, "image/*" matches all images, empty means all types that are not blocked
+}
+
+// DefaultPolicy policy used when t_config has no policy for the category
+func DefaultPolicy(category string) *Policy {
+	if category == CategoryAvatar {
+		return &Policy{
+			MaxSize:    2 << 20,
+			AllowTypes: []string{"image/png", "image/jpeg", "image/gif", "image/webp"},
+		}
+	}
+	return &Policy{MaxSize: 20 << 20}
+}
+
+// ParsePolicy parse the json value of t_config, fall back to the default policy if value is empty
+func ParsePolicy(category, value string) (*Policy, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultPolicy(category), nil
+	}
+	p := &Policy{}
+	if err := json.Unmarshal([]byte(value), p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// CheckSize check the declared size of the file
+func (p *Policy) CheckSize(size int64) error {
+	if p.MaxSize > 0 && size > p.MaxSize {
+		return ErrTooLarge
+	}
+	return nil
+}
+
+// CheckName reject file names with dangerous extensions such as .html and .exe
+func (p *Policy) CheckName(filename string) error {
+	if _, ok := blockedExts[strings.ToLower(filepath.Ext(filename))]; ok {
+		return ErrTypeNotAllowed
+	}
+	return nil
+}
+
+// Detect sniff the content type from the beginning of r, the extension of the client file name is only
+// used to reject dangerous names, the returned mime decides the stored extension
+func (p *Policy) Detect(r io.Reader, filename string) (*mimetype.MIME, error) {
+	if err := p.CheckName(filename); err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, SniffLen)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	// scripts with a shebang line are not always recognized by the detector
+	if bytes.HasPrefix(head[:n], []byte("#!")) {
+		return nil, ErrTypeNotAllowed
+	}
+	m := mimetype.Detect(head[:n])
+
+	for _, t := range blockedTypes {
+		if m.Is(t) {
+			return nil, ErrTypeNotAllowed
+		}
+	}
+	for parent := m.Parent(); parent != nil; parent = parent.Parent() {
+		for _, t := range blockedTypes {
+			if parent.Is(t) {
+				return nil, ErrTypeNotAllowed
+			}
+		}
+	}
+
+	if !p.allow(m) {
+		return nil, ErrTypeNotAllowed
+	}
+	return m, nil
+}
+
+func (p *Policy) allow(m *mimetype.MIME) bool {
+	if len(p.AllowTypes) == 0 {
+		return true
+	}
+	for _, t := range p.AllowTypes {
+		if strings.HasSuffix(t, "/*") {
+			if strings.HasPrefix(m.String(), strings.TrimSuffix(t, "*")) {
+				return true
+			}
+			continue
+		}
+		if m.Is(t) {
+			return true
+		}
+	}
+	return false
+}
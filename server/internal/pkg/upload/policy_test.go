@@ -0,0 +1,61 @@
+package upload
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var png = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89")
+
+func TestPolicy_Detect(t *testing.T) {
+	p := DefaultPolicy(CategoryAvatar)
+
+	m, err := p.Detect(bytes.NewReader(png), "a.jpg")
+	assert.NoError(t, err)
+	assert.Equal(t, "image/png", m.String())
+	assert.Equal(t, ".png", m.Extension())
+
+	// html pretending to be an image
+	_, err = p.Detect(strings.NewReader("<!DOCTYPE html><html><script>alert(1)</script></html>"), "a.png")
+	assert.ErrorIs(t, err, ErrTypeNotAllowed)
+
+	// svg is blocked even if the policy allows everything
+	p = &Policy{}
+	_, err = p.Detect(strings.NewReader(`<svg xmlns="http://www.w3.org/2000/svg" onload="alert(1)"></svg>`), "a.txt")
+	assert.ErrorIs(t, err, ErrTypeNotAllowed)
+	_, err = p.Detect(strings.NewReader("#!/bin/sh\nrm -rf /"), "a.txt")
+	assert.ErrorIs(t, err, ErrTypeNotAllowed)
+	_, err = p.Detect(strings.NewReader("MZ\x90\x00\x03\x00\x00\x00"), "a.bin")
+	assert.ErrorIs(t, err, ErrTypeNotAllowed)
+	_, err = p.Detect(strings.NewReader("hello"), "a.php")
+	assert.ErrorIs(t, err, ErrTypeNotAllowed)
+
+	m, err = p.Detect(strings.NewReader("hello"), "a.txt")
+	assert.NoError(t, err)
+	assert.True(t, m.Is("text/plain"))
+
+	// wildcard
+	p = &Policy{AllowTypes: []string{"image/*"}}
+	_, err = p.Detect(bytes.NewReader(png), "a.png")
+	assert.NoError(t, err)
+	_, err = p.Detect(strings.NewReader("hello"), "a.txt")
+	assert.ErrorIs(t, err, ErrTypeNotAllowed)
+}
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy(CategoryAttachment, "")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPolicy(CategoryAttachment), p)
+
+	p, err = ParsePolicy(CategoryAttachment, `{"maxSize": 10, "allowTypes": ["application/pdf"]}`)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), p.MaxSize)
+	assert.ErrorIs(t, p.CheckSize(11), ErrTooLarge)
+	assert.NoError(t, p.CheckSize(10))
+
+	_, err = ParsePolicy(CategoryAttachment, "{")
+	assert.Error(t, err)
+}
@@ -1,23 +1,32 @@
 package util
 
 import (
+	"admin/internal/pkg/i18n"
 	"admin/internal/types"
 	"encoding/json"
 	"fmt"
 	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/importer"
 	"go/parser"
 	"go/token"
+	gotypes "go/types"
 	"log"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 
 	"github.com/go-dev-frame/sponge/pkg/logger"
 )
 
+// EnumMarker 常量块注释中的枚举名标记, 如 // @enum order_status, 一个文件可以声明多个枚举, 没有标记的常量块以文件名为枚举名
+const EnumMarker = "@enum"
+
 // EnumChangeDict
-// 扫描目录constant/enum 下的文件，文件名称为 map 的key, 文件内容 以结构体 types.Options 为值， 其中 value 为常量的值，label 为常量的注解 生成 map[string][]types.Options
+// 扫描目录constant/enum 下的文件, 以 go/types 计算常量的精确值(iota 表达式、类型常量、引用其他常量), 生成 map[string][]*types.Options。
+// 枚举名为常量块的 @enum 标记或文件名, value 为常量的值, label 为常量的注释, name 为常量名,
+// 注释中 @key:value 形式的标签放在 other 中(如 @color:#f56c6c @tagType:danger), @disabled 表示选项不可选
 func EnumChangeDict(enumDir string) map[string][]*types.Options {
 	result := make(map[string][]*types.Options)
 
@@ -28,8 +37,14 @@ func EnumChangeDict(enumDir string) map[string][]*types.Options {
 	}
 
 	fset := token.NewFileSet()
+	var astFiles []*ast.File
+	var fileNames []string
 	for _, file := range files {
-		if file.IsDir() || !strings.HasSuffix(file.Name(), ".go") {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".go") || strings.HasSuffix(file.Name(), "_test.go") {
+			continue
+		}
+		// 与编译器一致地处理 build tags, 如 enumembed
+		if ok, _ := build.Default.MatchFile(enumDir, file.Name()); !ok {
 			continue
 		}
 
@@ -39,152 +54,165 @@ func EnumChangeDict(enumDir string) map[string][]*types.Options {
 			log.Printf("Error parsing file %s: %v\n", filePath, err)
 			continue
 		}
+		astFiles = append(astFiles, node)
+		fileNames = append(fileNames, strings.TrimSuffix(file.Name(), ".go"))
+	}
+	if len(astFiles) == 0 {
+		return result
+	}
 
-		fileNameKey := strings.TrimSuffix(file.Name(), ".go")
-		var options []*types.Options
+	info := &gotypes.Info{Defs: make(map[*ast.Ident]gotypes.Object)}
+	conf := gotypes.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error: func(err error) {
+			log.Printf("Warn: type checking %s: %v\n", enumDir, err)
+		},
+	}
+	_, _ = conf.Check(astFiles[0].Name.Name, fset, astFiles, info)
 
-		ast.Inspect(node, func(n ast.Node) bool {
-			decl, ok := n.(*ast.GenDecl)
-			// 检查是否是常量声明
-			if !ok || decl.Tok != token.CONST {
-				return true // 继续遍历子节点
+	for i, node := range astFiles {
+		for _, decl := range node.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+			name := enumMarker(genDecl.Doc)
+			if name == "" {
+				name = fileNames[i]
+			}
+			if options := constOptions(genDecl, info); len(options) > 0 {
+				result[name] = append(result[name], options...)
 			}
+		}
+	}
+
+	for name, options := range result {
+		result[name] = moveZeroToEnd(options)
+	}
+
+	return result
+}
+
+// 常量块的 @enum 标记
+func enumMarker(doc *ast.CommentGroup) string {
+	if doc == nil {
+		return ""
+	}
+	for _, c := range doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if rest, ok := strings.CutPrefix(text, EnumMarker); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			return strings.TrimSpace(rest)
+		}
+	}
+	return ""
+}
+
+// 常量块中导出的整数或字符串常量的选项, 其他常量忽略
+func constOptions(decl *ast.GenDecl, info *gotypes.Info) []*types.Options {
+	var options []*types.Options
+	for _, spec := range decl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		// 行尾注释优先, 其次是上方注释
+		comment := ""
+		if valueSpec.Comment != nil && len(valueSpec.Comment.List) > 0 {
+			comment = valueSpec.Comment.List[0].Text
+		} else if valueSpec.Doc != nil && len(valueSpec.Doc.List) > 0 {
+			comment = valueSpec.Doc.List[0].Text
+		}
 
-			// 实际的 iota 值需要根据 spec 在 decl.Specs 中的索引来确定
-			// currentIota := 0 // 移除简单的 iota 计数器
-
-			// 用于跟踪当前的 iota 值和基础偏移量
-			currentIotaValue := 0
-			iotaOffset := 0
-			hasIotaExpression := false
-
-			for i, spec := range decl.Specs {
-				valueSpec, ok := spec.(*ast.ValueSpec)
-				if !ok {
-					continue
-				}
-
-				var val interface{} // 改为 interface{} 以支持不同类型
-				isIntValue := false
-				isStringValue := false
-
-				// 检查是否有显式赋值
-				if len(valueSpec.Values) > 0 {
-					// 只处理第一个值
-					valueExpr := valueSpec.Values[0]
-					if basicLit, ok := valueExpr.(*ast.BasicLit); ok {
-						if basicLit.Kind == token.INT {
-							// 尝试解析整数值
-							parsedVal, err := strconv.Atoi(basicLit.Value)
-							if err == nil {
-								val = parsedVal
-								isIntValue = true
-								// 重置 iota 跟踪
-								currentIotaValue = parsedVal
-								iotaOffset = 0
-								hasIotaExpression = false
-							} else {
-								log.Printf("Warn: Could not parse integer literal '%s' in %s: %v\n", basicLit.Value, filePath, err)
-							}
-						} else if basicLit.Kind == token.STRING {
-							// 解析字符串值 (去除引号)
-							parsedVal, err := strconv.Unquote(basicLit.Value)
-							if err == nil {
-								val = parsedVal
-								isStringValue = true
-							} else {
-								log.Printf("Warn: Could not parse string literal '%s' in %s: %v\n", basicLit.Value, filePath, err)
-							}
-						}
-						// 其他类型 (float, complex, char) 暂不处理
-					} else if binExpr, ok := valueExpr.(*ast.BinaryExpr); ok {
-						// 处理 iota + 1 这样的表达式
-						if ident, ok := binExpr.X.(*ast.Ident); ok && ident.Name == "iota" {
-							if basicLit, ok := binExpr.Y.(*ast.BasicLit); ok && basicLit.Kind == token.INT {
-								if offset, err := strconv.Atoi(basicLit.Value); err == nil {
-									iotaOffset = offset
-									val = i + iotaOffset
-									isIntValue = true
-									hasIotaExpression = true
-									currentIotaValue = val.(int)
-								}
-							}
-						} else {
-							// 其他二元表达式，使用 iota 近似
-							val = i
-							isIntValue = true
-							currentIotaValue = i
-						}
-					} else {
-						// 如果不是基本字面量 (可能是标识符、调用等)，尝试使用 iota
-						val = i
-						isIntValue = true
-						currentIotaValue = i
-					}
-				} else {
-					// 没有显式赋值，使用前一个值的递增
-					if hasIotaExpression {
-						// 如果前面有 iota 表达式，继续递增
-						currentIotaValue++
-						val = currentIotaValue
-					} else {
-						// 否则使用索引
-						val = i
-						currentIotaValue = i
-					}
-					isIntValue = true
-				}
-
-				// 只有整数或字符串类型的常量才添加到 options
-				if isIntValue || isStringValue {
-					// 尝试获取注释作为 label
-					var label string
-					if valueSpec.Comment != nil && len(valueSpec.Comment.List) > 0 {
-						label = strings.TrimSpace(strings.TrimPrefix(valueSpec.Comment.List[0].Text, "//"))
-					} else if valueSpec.Doc != nil && len(valueSpec.Doc.List) > 0 {
-						label = strings.TrimSpace(strings.TrimPrefix(valueSpec.Doc.List[0].Text, "//"))
-					}
-
-					// 添加到 options 列表 (只添加有名字的常量)
-					if len(valueSpec.Names) > 0 && valueSpec.Names[0].Name != "_" {
-						options = append(options, &types.Options{
-							Value: val, // 使用解析出的或 iota 的值
-							Label: label,
-						})
-					}
-				}
-				// iota 的递增由 Go 编译器处理，我们只需在需要时使用其值 (这里用索引近似)
-				// currentIota++ // 移除简单的 iota 计数器
+		for _, ident := range valueSpec.Names {
+			if !ident.IsExported() {
+				continue // unexported constants are helpers of the values, e.g. a base value
 			}
-			// 退出 const 块的处理
-			return false // 不需要深入遍历 const 块内部的具体表达式等
-		})
-
-		// 将 Value 为 整数 0 的选项移到末尾
-		if len(options) > 1 { // 只有一个元素时无需移动
-			zeroIndex := -1
-			for i, opt := range options {
-				// 检查 Value 是否为整数 0
-				if intVal, ok := opt.Value.(int); ok && intVal == 0 {
-					zeroIndex = i
-					break
-				}
+			obj, ok := info.Defs[ident].(*gotypes.Const)
+			if !ok {
+				continue
 			}
-			if zeroIndex != -1 && zeroIndex != len(options)-1 {
-				zeroOption := options[zeroIndex]
-				// 从原位置删除
-				options = append(options[:zeroIndex], options[zeroIndex+1:]...)
-				// 添加到末尾
-				options = append(options, zeroOption)
+			value, ok := constValue(obj.Val())
+			if !ok {
+				continue
 			}
+			option := &types.Options{Name: ident.Name, Value: value}
+			parseEnumComment(comment, option)
+			options = append(options, option)
 		}
+	}
+	return options
+}
 
-		if len(options) > 0 {
-			result[fileNameKey] = options
+func constValue(v constant.Value) (interface{}, bool) {
+	switch v.Kind() {
+	case constant.Int:
+		if i, ok := constant.Int64Val(v); ok {
+			return int(i), true
 		}
+		log.Printf("Warn: constant %s overflows int64\n", v.ExactString())
+	case constant.String:
+		return constant.StringVal(v), true
 	}
+	return nil, false
+}
 
-	return result
+// parseEnumComment 注释 "男 @en:Male @color:#409eff @disabled" 中 @ 之前为 label, @locale:文本 为其他语言的 label
+// (可含空格, 到下一个 @ 为止), 其他 @key:value 放入 other, @disabled 设置 disabled
+func parseEnumComment(comment string, option *types.Options) {
+	text := strings.TrimSpace(strings.TrimPrefix(comment, "//"))
+	fields := strings.Fields(text)
+	var label []string
+	var other map[string]string
+	locale := "" // the locale whose label the following words belong to
+	for i, field := range fields {
+		if !strings.HasPrefix(field, "@") || len(field) == 1 {
+			if i == len(label) {
+				label = append(label, field)
+			} else if locale != "" {
+				option.Labels[locale] += " " + field
+			} // the words after the other tags are ignored
+			continue
+		}
+		key, value, _ := strings.Cut(field[1:], ":")
+		locale = ""
+		if key == "disabled" && value == "" {
+			option.Disabled = true
+			continue
+		}
+		if l := i18n.Normalize(key); l != "" && value != "" {
+			if option.Labels == nil {
+				option.Labels = make(map[string]string)
+			}
+			option.Labels[l] = value
+			locale = l
+			continue
+		}
+		if other == nil {
+			other = make(map[string]string)
+		}
+		other[key] = value
+	}
+	option.Label = strings.Join(label, " ")
+	if other != nil {
+		option.Other = other
+	}
+}
+
+// 将 Value 为 整数 0 的选项移到末尾
+func moveZeroToEnd(options []*types.Options) []*types.Options {
+	if len(options) < 2 {
+		return options
+	}
+	for i, opt := range options {
+		if intVal, ok := opt.Value.(int); ok && intVal == 0 {
+			if i != len(options)-1 {
+				options = append(options[:i], options[i+1:]...)
+				options = append(options, opt)
+			}
+			break
+		}
+	}
+	return options
 }
 
 func EnumSaveToJSONFile(enumDir, filePath string) error {
@@ -225,7 +253,13 @@ func EnumChangeDictByFile(filePath string) map[string][]*types.Options {
 		return result
 	}
 
-	if err = json.Unmarshal(data, &result); err != nil {
+	return EnumChangeDictByJSON(data)
+}
+
+// EnumChangeDictByJSON 解析 enum.json 的内容, 如嵌入二进制的 enum.json
+func EnumChangeDictByJSON(data []byte) map[string][]*types.Options {
+	result := make(map[string][]*types.Options)
+	if err := json.Unmarshal(data, &result); err != nil {
 		logger.Errorf("解析JSON数据失败: %w", err)
 		return result
 	}
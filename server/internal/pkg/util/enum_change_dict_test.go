@@ -1,10 +1,15 @@
 package util
 
 import (
+	"os"
 	"path"
 	"path/filepath"
 	"runtime"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"admin/internal/types"
 )
 
 func TestEnumChangeDict(t *testing.T) {
@@ -34,3 +39,85 @@ func TestEnumChangeDictByFile(t *testing.T) {
 	result := EnumChangeDictByFile(filePath)
 	t.Log(result)
 }
+
+func TestEnumChangeDictTypes(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("order.go", `package enum
+
+type Flag int
+
+const base = 10
+
+// 订单状态
+// @enum order_status
+const (
+	OrderStatusPaid    = base + iota // 已支付 @color:#67c23a @tagType:success
+	OrderStatusShipped               // 已发货 @en:Shipped out @color:#409eff
+	OrderStatusClosed                // 已关闭 @disabled
+)
+
+// @enum flag
+const (
+	FlagRead  Flag = 1 << iota // 读
+	FlagWrite                  // 写
+	FlagAll   = FlagRead | FlagWrite // 全部
+)
+
+const (
+	OrderTypeNormal = "normal" // 普通 订单
+	// 预售
+	OrderTypePresale = "presale"
+	OrderTypeRatio   = 1.5 // 非整数或字符串常量被忽略
+)
+`)
+	writeFile("tagged.go", `//go:build enumembed
+
+package enum
+
+const Tagged = 1 // 不参与编译
+`)
+
+	result := EnumChangeDict(dir)
+	assert.Len(t, result, 3)
+
+	status := result["order_status"]
+	if assert.Len(t, status, 3) {
+		assert.Equal(t, &types.Options{Label: "已支付", Value: 10, Name: "OrderStatusPaid",
+			Other: map[string]string{"color": "#67c23a", "tagType": "success"}}, status[0])
+		assert.Equal(t, 11, status[1].Value)
+		assert.Equal(t, map[string]string{"en": "Shipped out"}, status[1].Labels)
+		assert.Equal(t, map[string]string{"color": "#409eff"}, status[1].Other)
+		assert.Equal(t, "已关闭", status[2].Label)
+		assert.True(t, status[2].Disabled)
+	}
+
+	flag := result["flag"]
+	if assert.Len(t, flag, 3) {
+		assert.Equal(t, []interface{}{1, 2, 3}, []interface{}{flag[0].Value, flag[1].Value, flag[2].Value})
+		assert.Equal(t, "FlagAll", flag[2].Name)
+	}
+
+	orderType := result["order"]
+	if assert.Len(t, orderType, 2) {
+		assert.Equal(t, "普通 订单", orderType[0].Label)
+		assert.Equal(t, "预售", orderType[1].Label)
+		assert.Equal(t, "presale", orderType[1].Value)
+	}
+}
+
+func TestEnumChangeDictZeroLast(t *testing.T) {
+	_, filename, _, _ := runtime.Caller(0)
+	root := path.Dir(path.Dir(path.Dir(filename)))
+	result := EnumChangeDict(filepath.Join(root, "constant", "enum"))
+	gender := result["gender"]
+	if assert.Len(t, gender, 3) {
+		assert.Equal(t, "GenderUnknown", gender[2].Name)
+		assert.Equal(t, 0, gender[2].Value)
+		assert.Equal(t, "Male", gender[0].Labels["en"])
+	}
+}
@@ -21,3 +21,15 @@ func ImageMakePath(path, host string) string {
 
 	return fmt.Sprintf("%s/%s", strings.TrimRight(host, "\t\n\r\x00\x0B/"), strings.TrimLeft(path, "/"))
 }
+
+// ImagePathKey the part of path after prefix, path can be a full url such as https://cdn/uploads/a.jpg,
+// return "" if path does not start with prefix
+func ImagePathKey(path, prefix string) string {
+	if u, err := url.Parse(path); err == nil && IsValidURL(path) {
+		path = u.Path
+	}
+	if !strings.HasPrefix(path, prefix) {
+		return ""
+	}
+	return strings.TrimPrefix(path, prefix)
+}
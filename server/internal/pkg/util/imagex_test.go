@@ -26,3 +26,10 @@ func TestImageMakePath(t *testing.T) {
 	url = ImageMakePath(str, host)
 	assert.Equal(t, "https://www.baidu.com/a.jpg", url)
 }
+
+func TestImagePathKey(t *testing.T) {
+	assert.Equal(t, "2024-11-10/a.jpg", ImagePathKey("/uploads/2024-11-10/a.jpg", "/uploads/"))
+	assert.Equal(t, "2024-11-10/a.jpg", ImagePathKey("https://www.baidu.com/uploads/2024-11-10/a.jpg", "/uploads/"))
+	assert.Equal(t, "", ImagePathKey("https://www.baidu.com/a.jpg", "/uploads/"))
+	assert.Equal(t, "", ImagePathKey("", "/uploads/"))
+}
@@ -0,0 +1,31 @@
+package routers
+
+import (
+	"net/http"
+
+	"admin/internal/handler"
+	"admin/internal/middlewares"
+	"admin/internal/pkg/routeperm"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		auditLogRouter(group, handler.NewAuditLogHandler())
+	})
+}
+
+func auditLogRouter(group *gin.RouterGroup, h handler.AuditLogHandler) {
+	g := group.Group("/auditLog")
+
+	// All the following routes use jwt authentication, you also can use middleware.Auth(middleware.WithVerify(fn))
+	g.Use(auth.Auth(auth.WithExtraVerify(middlewares.VerifyToken)))
+
+	g.GET("", h.List) // [get] /api/v1/auditLog
+
+	routeperm.Declare(g,
+		routeperm.Perm(http.MethodGet, "", "sys:auditLog:query"),
+	)
+}
@@ -1,8 +1,11 @@
 package routers
 
 import (
+	"net/http"
+
 	"admin/internal/handler"
 	"admin/internal/middlewares"
+	"admin/internal/pkg/routeperm"
 	"github.com/gin-gonic/gin"
 	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
 )
@@ -25,4 +28,10 @@ func authRouter(group *gin.RouterGroup, h handler.AuthHandler) {
 	g.POST("/login", h.Login)                                                               // [post] /api/v1/auth/login
 	g.GET("/captcha", h.Captcha)                                                            // [get] /api/v1/auth/captcha
 	g.DELETE("/logout", auth.Auth(auth.WithExtraVerify(middlewares.VerifyToken)), h.Logout) // [delete] /api/v1/auth/logout
+
+	routeperm.Declare(g,
+		routeperm.Public(http.MethodPost, "/login"),
+		routeperm.Public(http.MethodGet, "/captcha"),
+		routeperm.Login(http.MethodDelete, "/logout"),
+	)
 }
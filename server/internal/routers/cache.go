@@ -0,0 +1,41 @@
+package routers
+
+import (
+	"net/http"
+
+	"admin/internal/handler"
+	"admin/internal/middlewares"
+	"admin/internal/pkg/routeperm"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		cacheRouter(group, handler.NewCacheHandler())
+	})
+}
+
+func cacheRouter(group *gin.RouterGroup, h handler.CacheHandler) {
+	g := group.Group("/cache")
+
+	// All the following routes use jwt authentication, you also can use middleware.Auth(middleware.WithVerify(fn))
+	g.Use(auth.Auth(auth.WithExtraVerify(middlewares.VerifyToken)))
+
+	g.GET("", h.ListNamespaces)               // [get] /api/v1/cache
+	g.GET("/:namespace", h.GetNamespace)      // [get] /api/v1/cache/:namespace
+	g.DELETE("/:namespace", h.EvictNamespace) // [delete] /api/v1/cache/:namespace
+	g.POST("/:namespace/warm", h.Warm)        // [post] /api/v1/cache/:namespace/warm
+	g.GET("/:namespace/key", h.GetKey)        // [get] /api/v1/cache/:namespace/key?key=
+	g.DELETE("/:namespace/key", h.EvictKey)   // [delete] /api/v1/cache/:namespace/key?key=
+
+	routeperm.Declare(g,
+		routeperm.Perm(http.MethodGet, "", "sys:cache:query"),
+		routeperm.Perm(http.MethodGet, "/:namespace", "sys:cache:query"),
+		routeperm.Perm(http.MethodDelete, "/:namespace", "sys:cache:evict"),
+		routeperm.Perm(http.MethodPost, "/:namespace/warm", "sys:cache:warm"),
+		routeperm.Perm(http.MethodGet, "/:namespace/key", "sys:cache:query"),
+		routeperm.Perm(http.MethodDelete, "/:namespace/key", "sys:cache:evict"),
+	)
+}
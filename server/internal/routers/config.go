@@ -1,8 +1,11 @@
 package routers
 
 import (
+	"net/http"
+
 	"admin/internal/handler"
 	"admin/internal/middlewares"
+	"admin/internal/pkg/routeperm"
 	"github.com/gin-gonic/gin"
 	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
 )
@@ -14,6 +17,8 @@ func init() {
 }
 
 func configRouter(group *gin.RouterGroup, h handler.ConfigHandler) {
+	group.GET("/config/public", h.Public) // [get] /api/v1/config/public, no login required
+
 	g := group.Group("/config")
 
 	// All the following routes use jwt authentication, you also can use middleware.Auth(middleware.WithVerify(fn))
@@ -22,10 +27,24 @@ func configRouter(group *gin.RouterGroup, h handler.ConfigHandler) {
 	// If jwt authentication is not required for all routes, authentication middleware can be added
 	// separately for only certain routes. In this case, g.Use(middleware.Auth()) above should not be used.
 
-	g.POST("", h.Create)           // [post] /api/v1/config
-	g.DELETE("/:id", h.DeleteByID) // [delete] /api/v1/config/:id
-	g.PUT("/:id", h.UpdateByID)    // [put] /api/v1/config/:id
-	g.GET("/:id", h.GetByID)       // [get] /api/v1/config/:id
-	g.GET("", h.List)              // [get] /api/v1/config
-	g.GET("/dict", h.Dict)         // [get] /api/v1/config/dict
+	g.POST("", h.Create)               // [post] /api/v1/config
+	g.DELETE("/:id", h.DeleteByID)     // [delete] /api/v1/config/:id
+	g.PUT("/:id", h.UpdateByID)        // [put] /api/v1/config/:id
+	g.GET("/:id", h.GetByID)           // [get] /api/v1/config/:id
+	g.GET("", h.List)                  // [get] /api/v1/config
+	g.GET("/dict", h.Dict)             // [get] /api/v1/config/dict
+	g.GET("/group/:group", h.GetGroup) // [get] /api/v1/config/group/:group
+
+	routeperm.Declare(group,
+		routeperm.Public(http.MethodGet, "/config/public"),
+	)
+	routeperm.Declare(g,
+		routeperm.Perm(http.MethodPost, "", "sys:config:add"),
+		routeperm.Perm(http.MethodDelete, "/:id", "sys:config:delete"),
+		routeperm.Perm(http.MethodPut, "/:id", "sys:config:edit"),
+		routeperm.Perm(http.MethodGet, "/:id", "sys:config:query"),
+		routeperm.Perm(http.MethodGet, "", "sys:config:query"),
+		routeperm.Login(http.MethodGet, "/dict"),
+		routeperm.Login(http.MethodGet, "/group/:group"),
+	)
 }
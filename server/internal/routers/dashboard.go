@@ -1,8 +1,11 @@
 package routers
 
 import (
+	"net/http"
+
 	"admin/internal/handler"
 	"admin/internal/middlewares"
+	"admin/internal/pkg/routeperm"
 	"github.com/gin-gonic/gin"
 	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
 )
@@ -24,4 +27,9 @@ func dashboardRouter(group *gin.RouterGroup, h handler.DashboardHandler) {
 
 	g.GET("/statistics", h.Statistics) // [get] /api/v1/dashboard/statistics
 	g.GET("/echarts", h.Echarts)       // [get] /api/v1/dashboard/echarts
+
+	routeperm.Declare(g,
+		routeperm.Login(http.MethodGet, "/statistics"),
+		routeperm.Login(http.MethodGet, "/echarts"),
+	)
 }
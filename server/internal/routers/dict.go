@@ -0,0 +1,37 @@
+package routers
+
+import (
+	"net/http"
+
+	"admin/internal/handler"
+	"admin/internal/middlewares"
+	"admin/internal/pkg/routeperm"
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		dictRouter(group, handler.NewDictHandler())
+	})
+}
+
+func dictRouter(group *gin.RouterGroup, h handler.DictHandler) {
+	g := group.Group("/dict")
+
+	g.Use(auth.Auth(auth.WithExtraVerify(middlewares.VerifyToken)))
+
+	g.POST("", h.Create)           // [post] /api/v1/dict
+	g.DELETE("/:id", h.DeleteByID) // [delete] /api/v1/dict/:id
+	g.PUT("/:id", h.UpdateByID)    // [put] /api/v1/dict/:id
+	g.GET("/:id", h.GetByID)       // [get] /api/v1/dict/:id
+	g.GET("", h.List)              // [get] /api/v1/dict
+
+	routeperm.Declare(g,
+		routeperm.Perm(http.MethodPost, "", "sys:dict:add"),
+		routeperm.Perm(http.MethodDelete, "/:id", "sys:dict:delete"),
+		routeperm.Perm(http.MethodPut, "/:id", "sys:dict:edit"),
+		routeperm.Perm(http.MethodGet, "/:id", "sys:dict:query"),
+		routeperm.Perm(http.MethodGet, "", "sys:dict:query"),
+	)
+}
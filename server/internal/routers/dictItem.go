@@ -0,0 +1,37 @@
+package routers
+
+import (
+	"net/http"
+
+	"admin/internal/handler"
+	"admin/internal/middlewares"
+	"admin/internal/pkg/routeperm"
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		dictItemRouter(group, handler.NewDictItemHandler())
+	})
+}
+
+func dictItemRouter(group *gin.RouterGroup, h handler.DictItemHandler) {
+	g := group.Group("/dictItem")
+
+	g.Use(auth.Auth(auth.WithExtraVerify(middlewares.VerifyToken)))
+
+	g.POST("", h.Create)           // [post] /api/v1/dictItem
+	g.DELETE("/:id", h.DeleteByID) // [delete] /api/v1/dictItem/:id
+	g.PUT("/:id", h.UpdateByID)    // [put] /api/v1/dictItem/:id
+	g.GET("/:id", h.GetByID)       // [get] /api/v1/dictItem/:id
+	g.GET("", h.List)              // [get] /api/v1/dictItem?dictID=1
+
+	routeperm.Declare(g,
+		routeperm.Perm(http.MethodPost, "", "sys:dictItem:add"),
+		routeperm.Perm(http.MethodDelete, "/:id", "sys:dictItem:delete"),
+		routeperm.Perm(http.MethodPut, "/:id", "sys:dictItem:edit"),
+		routeperm.Perm(http.MethodGet, "/:id", "sys:dictItem:query"),
+		routeperm.Perm(http.MethodGet, "", "sys:dictItem:query"),
+	)
+}
@@ -0,0 +1,52 @@
+package routers
+
+import (
+	"net/http"
+
+	"admin/internal/handler"
+	"admin/internal/middlewares"
+	"admin/internal/pkg/routeperm"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		fileRouter(group, handler.NewFileHandler())
+	})
+}
+
+func fileRouter(group *gin.RouterGroup, h handler.FileHandler) {
+	g := group.Group("/file")
+
+	// All the following routes use jwt authentication, you also can use middleware.Auth(middleware.WithVerify(fn))
+	g.Use(auth.Auth(auth.WithExtraVerify(middlewares.VerifyToken)))
+
+	// If jwt authentication is not required for all routes, authentication middleware can be added
+	// separately for only certain routes. In this case, g.Use(middleware.Auth()) above should not be used.
+
+	g.DELETE("/:id", h.DeleteByID) // [delete] /api/v1/file/:id
+	g.GET("/:id", h.GetByID)       // [get] /api/v1/file/:id
+	g.GET("", h.List)              // [get] /api/v1/file
+	g.GET("/:id/sign", h.SignURL)  // [get] /api/v1/file/:id/sign
+
+	// orphaned upload garbage collection
+	g.POST("/gc", h.RunGC)      // [post] /api/v1/file/gc
+	g.GET("/gc", h.GetGCReport) // [get] /api/v1/file/gc
+
+	// signed download, the signature is the credential, the token is only required by urls bound to an account
+	group.GET("/download/:id", middlewares.AuthBoundAccount(auth.WithExtraVerify(middlewares.VerifyToken)), h.Download) // [get] /api/v1/download/:id
+
+	routeperm.Declare(g,
+		routeperm.Perm(http.MethodDelete, "/:id", "sys:file:delete"),
+		routeperm.Perm(http.MethodGet, "/:id", "sys:file:query"),
+		routeperm.Perm(http.MethodGet, "", "sys:file:query"),
+		routeperm.Perm(http.MethodGet, "/:id/sign", "sys:file:query"),
+		routeperm.Perm(http.MethodPost, "/gc", "sys:file:gc"),
+		routeperm.Perm(http.MethodGet, "/gc", "sys:file:gc"),
+	)
+	routeperm.Declare(group,
+		routeperm.Signed(http.MethodGet, "/download/:id"),
+	)
+}
@@ -1,8 +1,11 @@
 package routers
 
 import (
+	"net/http"
+
 	"admin/internal/handler"
 	"admin/internal/middlewares"
+	"admin/internal/pkg/routeperm"
 	"github.com/gin-gonic/gin"
 	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
 )
@@ -25,8 +28,24 @@ func menuRouter(group *gin.RouterGroup, h handler.MenuHandler) {
 	g.POST("", h.Create)           // [post] /api/v1/menu
 	g.DELETE("/:id", h.DeleteByID) // [delete] /api/v1/menu/:id
 	g.PUT("/:id", h.UpdateByID)    // [put] /api/v1/menu/:id
+	g.PUT("/move", h.Move)         // [put] /api/v1/menu/move
+	g.GET("/export", h.Export)     // [get] /api/v1/menu/export
+	g.POST("/import", h.Import)    // [post] /api/v1/menu/import
 	g.GET("/:id", h.GetByID)       // [get] /api/v1/menu/:id
 	g.GET("", h.List)              // [get] /api/v1/menu
 	g.GET("/routes", h.Routes)     // [get] /api/v1/menu/routes
 	g.GET("/options", h.Options)   // [get] /api/v1/menu/options
+
+	routeperm.Declare(g,
+		routeperm.Perm(http.MethodPost, "", "sys:menu:add"),
+		routeperm.Perm(http.MethodDelete, "/:id", "sys:menu:delete"),
+		routeperm.Perm(http.MethodPut, "/:id", "sys:menu:edit"),
+		routeperm.Perm(http.MethodPut, "/move", "sys:menu:edit"),
+		routeperm.Perm(http.MethodGet, "/export", "sys:menu:export"),
+		routeperm.Perm(http.MethodPost, "/import", "sys:menu:import"),
+		routeperm.Perm(http.MethodGet, "/:id", "sys:menu:query"),
+		routeperm.Perm(http.MethodGet, "", "sys:menu:query"),
+		routeperm.Login(http.MethodGet, "/routes"),
+		routeperm.Login(http.MethodGet, "/options"),
+	)
 }
@@ -0,0 +1,33 @@
+package routers
+
+import (
+	"net/http"
+
+	"admin/internal/handler"
+	"admin/internal/middlewares"
+	"admin/internal/pkg/routeperm"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
+)
+
+func init() {
+	apiV1RouterFns = append(apiV1RouterFns, func(group *gin.RouterGroup) {
+		permissionRouter(group, handler.NewPermissionHandler())
+	})
+}
+
+func permissionRouter(group *gin.RouterGroup, h handler.PermissionHandler) {
+	g := group.Group("/permission")
+
+	// All the following routes use jwt authentication, you also can use middleware.Auth(middleware.WithVerify(fn))
+	g.Use(auth.Auth(auth.WithExtraVerify(middlewares.VerifyToken)))
+
+	g.GET("/routes", h.Routes)   // [get] /api/v1/permission/routes
+	g.GET("/explain", h.Explain) // [get] /api/v1/permission/explain?platformId=1
+
+	routeperm.Declare(g,
+		routeperm.Perm(http.MethodGet, "/routes", "sys:permission:query"),
+		routeperm.Perm(http.MethodGet, "/explain", "sys:permission:query"),
+	)
+}
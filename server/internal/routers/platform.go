@@ -1,8 +1,11 @@
 package routers
 
 import (
+	"net/http"
+
 	"admin/internal/handler"
 	"admin/internal/middlewares"
+	"admin/internal/pkg/routeperm"
 	"github.com/gin-gonic/gin"
 	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
 )
@@ -31,4 +34,17 @@ func platformRouter(group *gin.RouterGroup, h handler.PlatformHandler) {
 	g.PUT("/profile", h.UpdateProfile)        // [put] /api/v1/platform/profile
 	g.PUT("/password", h.ChangePassword)      // [put] /api/v1/platform/password
 	g.PUT("/password/reset", h.ResetPassword) // [put] /api/v1/platform/password/password/reset
+
+	routeperm.Declare(g,
+		routeperm.Perm(http.MethodPost, "", "sys:platform:add"),
+		routeperm.Perm(http.MethodDelete, "/:id", "sys:platform:delete"),
+		routeperm.Perm(http.MethodPut, "/:id", "sys:platform:edit"),
+		routeperm.Perm(http.MethodGet, "/:id", "sys:platform:query"),
+		routeperm.Perm(http.MethodGet, "", "sys:platform:query"),
+		routeperm.Login(http.MethodGet, "/me"),
+		routeperm.Login(http.MethodGet, "/profile"),
+		routeperm.Login(http.MethodPut, "/profile"),
+		routeperm.Login(http.MethodPut, "/password"),
+		routeperm.Perm(http.MethodPut, "/password/reset", "sys:platform:password:reset"),
+	)
 }
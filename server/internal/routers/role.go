@@ -1,8 +1,11 @@
 package routers
 
 import (
+	"net/http"
+
 	"admin/internal/handler"
 	"admin/internal/middlewares"
+	"admin/internal/pkg/routeperm"
 	"github.com/gin-gonic/gin"
 	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
 )
@@ -30,4 +33,15 @@ func roleRouter(group *gin.RouterGroup, h handler.RoleHandler) {
 	g.GET("/options", h.Options)     // [get] /api/v1/role/options
 	g.GET("/:id/menuIds", h.MenuIds) // [get] /api/v1/role/:id/menuIds
 	g.PUT("/:id/menus", h.Menus)     // [put] /api/v1/role/:id/menus
+
+	routeperm.Declare(g,
+		routeperm.Perm(http.MethodPost, "", "sys:role:add"),
+		routeperm.Perm(http.MethodDelete, "/:id", "sys:role:delete"),
+		routeperm.Perm(http.MethodPut, "/:id", "sys:role:edit"),
+		routeperm.Perm(http.MethodGet, "/:id", "sys:role:query"),
+		routeperm.Perm(http.MethodGet, "", "sys:role:query"),
+		routeperm.Login(http.MethodGet, "/options"),
+		routeperm.Perm(http.MethodGet, "/:id/menuIds", "sys:role:permission"),
+		routeperm.Perm(http.MethodPut, "/:id/menus", "sys:role:permission"),
+	)
 }
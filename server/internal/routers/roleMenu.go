@@ -1,8 +1,11 @@
 package routers
 
 import (
+	"net/http"
+
 	"admin/internal/handler"
 	"admin/internal/middlewares"
+	"admin/internal/pkg/routeperm"
 	"github.com/gin-gonic/gin"
 	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
 )
@@ -27,4 +30,12 @@ func roleMenuRouter(group *gin.RouterGroup, h handler.RoleMenuHandler) {
 	g.PUT("/:id", h.UpdateByID)    // [put] /api/v1/roleMenu/:id
 	g.GET("/:id", h.GetByID)       // [get] /api/v1/roleMenu/:id
 	g.GET("", h.List)              // [get] /api/v1/roleMenu
+
+	routeperm.Declare(g,
+		routeperm.Perm(http.MethodPost, "", "sys:role:permission"),
+		routeperm.Perm(http.MethodDelete, "/:id", "sys:role:permission"),
+		routeperm.Perm(http.MethodPut, "/:id", "sys:role:permission"),
+		routeperm.Perm(http.MethodGet, "/:id", "sys:role:permission"),
+		routeperm.Perm(http.MethodGet, "", "sys:role:permission"),
+	)
 }
@@ -6,6 +6,7 @@ import (
 	"admin/internal/middlewares"
 	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -22,7 +23,11 @@ import (
 	"github.com/go-dev-frame/sponge/pkg/logger"
 
 	"admin/docs"
+	"admin/internal/cache"
 	"admin/internal/config"
+	"admin/internal/handler"
+	"admin/internal/pkg/routeperm"
+	"admin/internal/validation"
 )
 
 var (
@@ -54,12 +59,16 @@ func NewRouter() *gin.Engine {
 		middleware.WithIgnoreRoutes("/metrics"), // ignore path
 	))
 
+	// locale middleware, the messages of the error codes are replied in the locale of the request
+	r.Use(middlewares.Locale())
+
 	// metrics middleware
 	if config.Get().App.EnableMetrics {
 		r.Use(metrics.Metrics(r,
 			//metrics.WithMetricsPath("/metrics"),                // default is /metrics
 			metrics.WithIgnoreStatusCodes(http.StatusNotFound), // ignore 404 status codes
 		))
+		routeperm.Declare(&r.RouterGroup, routeperm.Public(http.MethodGet, "/metrics"))
 	}
 
 	// limit middleware
@@ -80,10 +89,19 @@ func NewRouter() *gin.Engine {
 	// profile performance analysis
 	if config.Get().App.EnableHTTPProfile {
 		prof.Register(r, prof.WithIOWaitTime())
+		for _, route := range r.Routes() {
+			if strings.HasPrefix(route.Path, "/debug/pprof") {
+				routeperm.Declare(&r.RouterGroup, routeperm.Public(route.Method, route.Path))
+			}
+		}
 	}
 
-	// validator
-	binding.Validator = validator.Init()
+	// validator, with the custom tags such as enum=gender
+	v := validator.Init()
+	if err := validation.Register(v.Validate, cache.NewEnumCache()); err != nil {
+		panic("validation.Register error: " + err.Error())
+	}
+	binding.Validator = v
 
 	// jwt
 	auth.InitAuth([]byte(middlewares.JwtSignKey), time.Hour*2)
@@ -91,23 +109,43 @@ func NewRouter() *gin.Engine {
 	r.GET("/health", handlerfunc.CheckHealth)
 	r.GET("/ping", handlerfunc.Ping)
 	r.GET("/codes", handlerfunc.ListCodes)
+	routeperm.Declare(&r.RouterGroup,
+		routeperm.Public(http.MethodGet, "/health"),
+		routeperm.Public(http.MethodGet, "/ping"),
+		routeperm.Public(http.MethodGet, "/codes"),
+	)
 
 	if config.Get().App.Env != "prod" {
-		r.GET("/config", gin.WrapF(errcode.ShowConfig([]byte(config.Show()))))
+		// the keys of the signed urls and the storage are hidden
+		r.GET("/config", gin.WrapF(errcode.ShowConfig([]byte(config.Show(`"signKey"`, `"secretKey"`)))))
 		// register swagger routes, generate code via swag init
 		docs.SwaggerInfo.BasePath = ""
 		// access path /swagger/index.html
 		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
-		// static upload router
-		r.Static("/uploads", "./uploads")
+		routeperm.Declare(&r.RouterGroup,
+			routeperm.Public(http.MethodGet, "/config"),
+			routeperm.Public(http.MethodGet, "/swagger/*any"),
+		)
 	}
 
+	// uploaded files, served from the configured storage backend
+	uploadHandler := handler.NewUploadHandler()
+	r.GET("/uploads/*key", uploadHandler.Serve)
+	r.HEAD("/uploads/*key", uploadHandler.Serve)
+	routeperm.Declare(&r.RouterGroup,
+		routeperm.Public(http.MethodGet, "/uploads/*key"),
+		routeperm.Public(http.MethodHead, "/uploads/*key"),
+	)
+
 	// register routers, middleware support
 	registerRouters(r, "/api/v1", apiV1RouterFns)
 	// if you have other group routes you can add them here
 	// example:
 	//    registerRouters(r, "/api/v2", apiV2RouteFns, middleware.Auth())
 
+	// the routes with their perms are listed by GET /api/v1/permission/routes and cmd/routeperm
+	routeperm.SetRoutes(r.Routes())
+
 	return r
 }
 
@@ -1,8 +1,11 @@
 package routers
 
 import (
+	"net/http"
+
 	"admin/internal/handler"
 	"admin/internal/middlewares"
+	"admin/internal/pkg/routeperm"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-dev-frame/sponge/pkg/gin/middleware/auth"
@@ -24,4 +27,20 @@ func uploadRouter(group *gin.RouterGroup, h handler.UploadHandler) {
 	// separately for only certain routes. In this case, g.Use(middleware.Auth()) above should not be used.
 
 	g.POST("/local", h.Local) // [post] /api/v1/upload/local
+
+	// chunked and resumable upload
+	g.POST("/session", h.InitiateSession)                    // [post] /api/v1/upload/session
+	g.GET("/session/:uploadID", h.GetSession)                // [get] /api/v1/upload/session/:uploadID
+	g.PUT("/session/:uploadID/chunk/:index", h.UploadChunk)  // [put] /api/v1/upload/session/:uploadID/chunk/:index
+	g.POST("/session/:uploadID/complete", h.CompleteSession) // [post] /api/v1/upload/session/:uploadID/complete
+	g.DELETE("/session/:uploadID", h.AbortSession)           // [delete] /api/v1/upload/session/:uploadID
+
+	routeperm.Declare(g,
+		routeperm.Login(http.MethodPost, "/local"),
+		routeperm.Login(http.MethodPost, "/session"),
+		routeperm.Login(http.MethodGet, "/session/:uploadID"),
+		routeperm.Login(http.MethodPut, "/session/:uploadID/chunk/:index"),
+		routeperm.Login(http.MethodPost, "/session/:uploadID/complete"),
+		routeperm.Login(http.MethodDelete, "/session/:uploadID"),
+	)
 }
@@ -0,0 +1,384 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/gocron"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"admin/internal/cache"
+	"admin/internal/constant"
+	"admin/internal/dao"
+	"admin/internal/database"
+	"admin/internal/model"
+	"admin/internal/pkg/imageproc"
+	"admin/internal/pkg/storage"
+	"admin/internal/pkg/upload"
+	"admin/internal/types"
+)
+
+const (
+	// fileGCBatch objects checked against the database at a time
+	fileGCBatch = 500
+	// fileGCMaxItems orphans listed in the report
+	fileGCMaxItems = 1000
+	// fileGCLockTTL the lock shared by the instances expires after it in case the instance dies
+	fileGCLockTTL = 2 * time.Hour
+)
+
+// reasons of orphaned objects
+const (
+	FileGCUnregistered = "unregistered" // not in t_file and not held by any model field
+	FileGCUnreferenced = "unreferenced" // in t_file, but neither t_file_ref nor any model field references it
+)
+
+// ErrFileGCRunning another collection is running in this or another instance
+var ErrFileGCRunning = errors.New("file gc is running")
+
+var fileGCMu sync.Mutex
+
+func init() {
+	tasks = append(tasks, &gocron.Task{
+		Name:     "collectOrphanFiles",
+		TimeSpec: gocron.Everyday(1),
+		Fn: func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+			defer cancel()
+			report, err := RunFileGC(ctx, newFileGCDeps(), nil)
+			if err != nil {
+				logger.Error("RunFileGC error", logger.Err(err))
+				return
+			}
+			logger.Info("orphaned upload gc finished", logger.Bool("dryRun", report.DryRun),
+				logger.Int("scanned", report.Scanned), logger.Int("orphans", report.OrphanCount), logger.Int("deleted", report.Deleted))
+		},
+	})
+}
+
+// FileGCOptions options of the orphaned upload garbage collection, configured by uploadGC in t_config
+type FileGCOptions struct {
+	GraceHours         int      `json:"graceHours"`         // objects newer than this are never collected, default 24
+	Categories         []string `json:"categories"`         // registered files of these upload categories are collected when unreferenced
+	DryRun             bool     `json:"dryRun"`             // the scheduled run only reports unless it is false, default true, the admin endpoint decides by itself
+	DeleteUnregistered bool     `json:"deleteUnregistered"` // objects not in t_file are only reported unless it is true, default false
+}
+
+// DefaultFileGCOptions options used when t_config has no uploadGC configured, nothing is deleted
+func DefaultFileGCOptions() *FileGCOptions {
+	return &FileGCOptions{
+		GraceHours: 24,
+		Categories: []string{upload.CategoryAvatar},
+		DryRun:     true,
+	}
+}
+
+// ParseFileGCOptions parse the json value of t_config, the options absent from value keep the default
+func ParseFileGCOptions(value string) (*FileGCOptions, error) {
+	if strings.TrimSpace(value) == "" {
+		return DefaultFileGCOptions(), nil
+	}
+	o := DefaultFileGCOptions()
+	if err := json.Unmarshal([]byte(value), o); err != nil {
+		return nil, err
+	}
+	if o.GraceHours < 1 {
+		return nil, errors.New("graceHours must be at least 1")
+	}
+	return o, nil
+}
+
+// FileGCDao the dao methods used by the collection
+type FileGCDao interface {
+	GetByStorageKeys(ctx context.Context, keys []string) ([]*model.File, error)
+	CountRefs(ctx context.Context, fileIDs []uint64) (map[uint64]int64, error)
+	GetFieldPaths(ctx context.Context) ([]string, error)
+	GetFieldPathsOf(ctx context.Context, key string) ([]string, error)
+	DeleteByIDs(ctx context.Context, ids []uint64) error
+}
+
+// FileGCDeps dependencies of RunFileGC
+type FileGCDeps struct {
+	ConfigDao dao.ConfigDao
+	FileDao   FileGCDao
+	Storage   storage.Storage
+	Cache     cache.FileGCCache
+}
+
+func newFileGCDeps() *FileGCDeps {
+	return &FileGCDeps{
+		ConfigDao: dao.NewConfigDao(database.GetDB(), cache.NewConfigCache(database.GetCacheType())),
+		FileDao:   dao.NewFileDao(database.GetDB(), cache.NewFileCache(database.GetCacheType())),
+		Storage:   database.GetStorage(),
+		Cache:     cache.NewFileGCCache(database.GetCacheType()),
+	}
+}
+
+// RunFileGC collect orphaned uploads with the options in t_config and save the report as the last report,
+// dryRun overrides the configured dryRun if it is not nil. only one collection runs at a time, the instances
+// share the lock by redis, without redis the collection must be run by a single instance
+func RunFileGC(ctx context.Context, deps *FileGCDeps, dryRun *bool) (*types.FileGCReport, error) {
+	if !fileGCMu.TryLock() {
+		return nil, ErrFileGCRunning
+	}
+	defer fileGCMu.Unlock()
+	token, ok, err := deps.Cache.TryLock(ctx, fileGCLockTTL)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrFileGCRunning
+	}
+	defer func() {
+		if err := deps.Cache.Unlock(context.Background(), token); err != nil {
+			logger.Warn("unlock file gc error", logger.Err(err))
+		}
+	}()
+
+	value := deps.ConfigDao.GetString(ctx, constant.ConfigKeyUploadGC, "")
+	o, err := ParseFileGCOptions(value)
+	if err != nil {
+		logger.Warn("invalid upload gc options, use the default", logger.Err(err))
+		o = DefaultFileGCOptions()
+	}
+	if dryRun != nil {
+		o.DryRun = *dryRun
+	}
+
+	report, err := CollectOrphanFiles(ctx, deps.FileDao, deps.Storage, o, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if err = deps.Cache.SetReport(ctx, report); err != nil {
+		logger.Warn("SetReport error", logger.Err(err))
+	}
+	return report, nil
+}
+
+type fileGCObject struct {
+	key     string
+	baseKey string // key of the original file, variants share the fate of it
+	size    int64
+}
+
+// CollectOrphanFiles find the uploaded objects of the storage which are older than the grace period and no longer
+// referenced, and delete them together with their t_file records unless o.DryRun is true, the unregistered ones
+// only if o.DeleteUnregistered is true. a registered file is in the grace period since it was last reused, and
+// every object is checked again right before it is deleted. only the keys of uploads are scanned, internal
+// objects such as chunks are left to the upload session cleanup and the other objects sharing the storage are
+// never touched
+func CollectOrphanFiles(ctx context.Context, iDao FileGCDao, store storage.Storage, o *FileGCOptions, now time.Time) (*types.FileGCReport, error) {
+	report := &types.FileGCReport{
+		DryRun:     o.DryRun,
+		GraceHours: o.GraceHours,
+		Categories: o.Categories,
+		StartedAt:  now,
+		Orphans:    []types.FileGCItem{},
+		Errors:     []string{},
+	}
+	deadline := now.Add(-time.Duration(o.GraceHours) * time.Hour)
+
+	// keys held by model fields, e.g. the avatar of platforms saved before the file registry existed
+	paths, err := iDao.GetFieldPaths(ctx)
+	if err != nil {
+		return nil, err
+	}
+	fieldKeys := map[string]bool{}
+	for _, p := range paths {
+		for _, key := range uploadKeysIn(p) {
+			fieldKeys[key] = true
+		}
+	}
+
+	var batch []*fileGCObject
+	flush := func() error {
+		err := collectBatch(ctx, iDao, store, o, deadline, fieldKeys, batch, report)
+		batch = batch[:0]
+		return err
+	}
+	err = store.List(ctx, "", func(info *storage.ObjectInfo) error {
+		if !isUploadKey(info.Key) {
+			return nil
+		}
+		report.Scanned++
+		if info.LastModified.After(deadline) {
+			return nil
+		}
+		baseKey, _, _ := strings.Cut(info.Key, imageproc.VariantSeparator)
+		batch = append(batch, &fileGCObject{key: info.Key, baseKey: baseKey, size: info.Size})
+		if len(batch) >= fileGCBatch {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil && len(batch) > 0 {
+		err = flush()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report.FinishedAt = time.Now()
+	return report, nil
+}
+
+// uploadPathRegexp the upload paths in a text, the key ends at a quote, a space, a query or a fragment
+var uploadPathRegexp = regexp.MustCompile(regexp.QuoteMeta(constant.UploadPathPrefix) + `([^\s"'?#()<>,]+)`)
+
+// uploadKeysIn the keys of the upload paths or urls in a field value, e.g. /uploads/2024-11-10/a.png or
+// {"logo":"https://cdn/uploads/2024-11-10/a.png!small.png"}, a variant protects its original
+func uploadKeysIn(value string) []string {
+	var keys []string
+	for _, m := range uploadPathRegexp.FindAllStringSubmatch(strings.ReplaceAll(value, `\/`, "/"), -1) {
+		key, _, _ := strings.Cut(m[1], imageproc.VariantSeparator)
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// isUploadKey whether the key is in the layout of the uploads, <date>/<file> or private/[<owner>/]<date>/<file>,
+// variants are <file>!<suffix>
+func isUploadKey(key string) bool {
+	if rest, ok := strings.CutPrefix(key, constant.PrivateKeyPrefix); ok {
+		key = rest
+		if owner, rest, ok := strings.Cut(key, "/"); ok && owner != "" && strings.Trim(owner, "0123456789") == "" {
+			key = rest
+		}
+	}
+	date, name, ok := strings.Cut(key, "/")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		return false
+	}
+	_, err := time.Parse("2006-01-02", date)
+	return err == nil
+}
+
+func collectBatch(ctx context.Context, iDao FileGCDao, store storage.Storage, o *FileGCOptions, deadline time.Time,
+	fieldKeys map[string]bool, batch []*fileGCObject, report *types.FileGCReport) error {
+	var baseKeys []string
+	for _, obj := range batch {
+		if !fieldKeys[obj.baseKey] && !slices.Contains(baseKeys, obj.baseKey) {
+			baseKeys = append(baseKeys, obj.baseKey)
+		}
+	}
+	if len(baseKeys) == 0 {
+		return nil
+	}
+
+	records, err := iDao.GetByStorageKeys(ctx, baseKeys)
+	if err != nil {
+		return err
+	}
+	files := map[string]*model.File{}
+	var ids []uint64
+	for _, record := range records {
+		files[record.StorageKey] = record
+		ids = append(ids, record.ID)
+	}
+	refs := map[uint64]int64{}
+	if len(ids) > 0 {
+		if refs, err = iDao.CountRefs(ctx, ids); err != nil {
+			return err
+		}
+	}
+
+	var deleteIDs []uint64
+	rechecked := map[string]bool{} // whether the base key is still an orphan, variants share the result
+	for _, obj := range batch {
+		if fieldKeys[obj.baseKey] {
+			continue
+		}
+		item := types.FileGCItem{Key: obj.key, Size: obj.size, Reason: FileGCUnregistered}
+		if file, ok := files[obj.baseKey]; ok {
+			if refs[file.ID] > 0 || !slices.Contains(o.Categories, file.Category) || lastUsed(file).After(deadline) {
+				continue
+			}
+			item.FileID = file.ID
+			item.Reason = FileGCUnreferenced
+		}
+		if !o.DryRun && (item.FileID > 0 || o.DeleteUnregistered) {
+			orphan, ok := rechecked[obj.baseKey]
+			if !ok {
+				if orphan, err = stillOrphan(ctx, iDao, obj.baseKey, item.FileID, deadline); err != nil {
+					report.Errors = append(report.Errors, obj.key+": "+err.Error())
+					continue
+				}
+				rechecked[obj.baseKey] = orphan
+			}
+			if !orphan {
+				continue
+			}
+		}
+
+		report.OrphanCount++
+		report.OrphanBytes += obj.size
+		if len(report.Orphans) < fileGCMaxItems {
+			report.Orphans = append(report.Orphans, item)
+		}
+		if o.DryRun || (item.FileID == 0 && !o.DeleteUnregistered) {
+			continue
+		}
+		if err = store.Delete(ctx, obj.key); err != nil {
+			report.Errors = append(report.Errors, obj.key+": "+err.Error())
+			continue
+		}
+		report.Deleted++
+		if item.FileID > 0 && !slices.Contains(deleteIDs, item.FileID) {
+			deleteIDs = append(deleteIDs, item.FileID)
+		}
+	}
+
+	if len(deleteIDs) > 0 {
+		if err = iDao.DeleteByIDs(ctx, deleteIDs); err != nil {
+			report.Errors = append(report.Errors, "delete t_file records: "+err.Error())
+		}
+	}
+	return nil
+}
+
+// lastUsed the file was created or reused by an upload of the same content at that time
+func lastUsed(file *model.File) time.Time {
+	if file.UpdatedAt.After(file.CreatedAt) {
+		return file.UpdatedAt
+	}
+	return file.CreatedAt
+}
+
+// stillOrphan check the object again right before it is deleted, a form may have been saved with it or the
+// file reused by an upload since the batch was read
+func stillOrphan(ctx context.Context, iDao FileGCDao, baseKey string, fileID uint64, deadline time.Time) (bool, error) {
+	values, err := iDao.GetFieldPathsOf(ctx, baseKey)
+	if err != nil {
+		return false, err
+	}
+	for _, value := range values {
+		if slices.Contains(uploadKeysIn(value), baseKey) {
+			return false, nil
+		}
+	}
+
+	records, err := iDao.GetByStorageKeys(ctx, []string{baseKey})
+	if err != nil {
+		return false, err
+	}
+	if fileID == 0 {
+		// registered by an upload in the meantime
+		return len(records) == 0, nil
+	}
+	for _, record := range records {
+		if record.ID == fileID && lastUsed(record).After(deadline) {
+			return false, nil
+		}
+	}
+	refs, err := iDao.CountRefs(ctx, []uint64{fileID})
+	if err != nil {
+		return false, err
+	}
+	return refs[fileID] == 0, nil
+}
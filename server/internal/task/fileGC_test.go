@@ -0,0 +1,270 @@
+package task
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"admin/internal/model"
+	"admin/internal/pkg/storage"
+	"admin/internal/pkg/upload"
+)
+
+type memFileGCDao struct {
+	files   []*model.File
+	refs    map[uint64]int64
+	paths   []string
+	deleted []uint64
+
+	afterRead func(d *memFileGCDao) // called once after the first read of the records, e.g. a concurrent save
+}
+
+func (d *memFileGCDao) GetByStorageKeys(_ context.Context, keys []string) ([]*model.File, error) {
+	if fn := d.afterRead; fn != nil {
+		d.afterRead = nil
+		defer fn(d)
+	}
+	var records []*model.File
+	for _, f := range d.files {
+		for _, key := range keys {
+			if f.StorageKey == key {
+				records = append(records, f)
+			}
+		}
+	}
+	return records, nil
+}
+
+func (d *memFileGCDao) CountRefs(_ context.Context, fileIDs []uint64) (map[uint64]int64, error) {
+	counts := map[uint64]int64{}
+	for _, id := range fileIDs {
+		if d.refs[id] > 0 {
+			counts[id] = d.refs[id]
+		}
+	}
+	return counts, nil
+}
+
+func (d *memFileGCDao) GetFieldPaths(_ context.Context) ([]string, error) {
+	return d.paths, nil
+}
+
+func (d *memFileGCDao) GetFieldPathsOf(_ context.Context, key string) ([]string, error) {
+	var paths []string
+	for _, p := range d.paths {
+		if strings.Contains(strings.ReplaceAll(p, `\/`, "/"), path.Base(key)) {
+			paths = append(paths, p)
+		}
+	}
+	return paths, nil
+}
+
+func (d *memFileGCDao) DeleteByIDs(_ context.Context, ids []uint64) error {
+	d.deleted = append(d.deleted, ids...)
+	return nil
+}
+
+func newGCFile(id uint64, key string, category string, createdAt time.Time) *model.File {
+	f := &model.File{StorageKey: key, Category: category}
+	f.ID = id
+	f.CreatedAt = createdAt
+	return f
+}
+
+func TestCollectOrphanFiles(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := storage.NewLocal(root)
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+
+	keys := []string{
+		"2024-11-10/legacy.png",   // unregistered, held by platform.avatar
+		"2024-11-10/logo.png",     // unregistered, held by a json value of t_config
+		"2024-11-10/banner.png",   // unregistered, its variant is held by siteLogo
+		"2024-11-10/lost.png",     // unregistered
+		"2024-11-10/avatar.png",   // registered avatar, referenced
+		"2024-11-10/replaced.png", // registered avatar, unreferenced
+		"2024-11-10/replaced.png!small.png",
+		"2024-11-10/contract.pdf", // registered attachment, unreferenced
+		"2024-11-10/new.png",      // unregistered, in the grace period
+		upload.ChunkKey("abc", 0), // chunks are left to the session cleanup
+		"backup/db.sql",           // not an upload, never touched
+	}
+	for _, key := range keys {
+		err := store.Put(ctx, key, strings.NewReader("data"), 4, "")
+		assert.NoError(t, err)
+		if key != "2024-11-10/new.png" {
+			assert.NoError(t, os.Chtimes(filepath.Join(root, key), old, old))
+		}
+	}
+
+	iDao := &memFileGCDao{
+		files: []*model.File{
+			newGCFile(1, "2024-11-10/avatar.png", upload.CategoryAvatar, old),
+			newGCFile(2, "2024-11-10/replaced.png", upload.CategoryAvatar, old),
+			newGCFile(3, "2024-11-10/contract.pdf", upload.CategoryAttachment, old),
+		},
+		refs: map[uint64]int64{1: 1},
+		paths: []string{
+			"http://localhost:8080/uploads/2024-11-10/legacy.png",
+			"https://cdn.example.com/a.png",
+			`{"watermark":{"image":"\/uploads\/2024-11-10\/logo.png"}}`,
+			"https://cdn.example.com/uploads/2024-11-10/banner.png!small.png",
+		},
+	}
+
+	o := DefaultFileGCOptions()
+	o.DryRun = true
+	report, err := CollectOrphanFiles(ctx, iDao, store, o, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, report.Scanned)
+	assert.Equal(t, 3, report.OrphanCount)
+	assert.Equal(t, int64(12), report.OrphanBytes)
+	assert.Equal(t, 0, report.Deleted)
+	var orphans []string
+	for _, item := range report.Orphans {
+		orphans = append(orphans, item.Key+" "+item.Reason)
+	}
+	assert.ElementsMatch(t, []string{
+		"2024-11-10/lost.png " + FileGCUnregistered,
+		"2024-11-10/replaced.png " + FileGCUnreferenced,
+		"2024-11-10/replaced.png!small.png " + FileGCUnreferenced,
+	}, orphans)
+	_, err = store.Stat(ctx, "2024-11-10/lost.png")
+	assert.NoError(t, err)
+
+	// unregistered objects are only reported by default
+	o.DryRun = false
+	report, err = CollectOrphanFiles(ctx, iDao, store, o, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.OrphanCount)
+	assert.Equal(t, 2, report.Deleted)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, []uint64{2}, iDao.deleted)
+	_, err = store.Stat(ctx, "2024-11-10/lost.png")
+	assert.NoError(t, err)
+
+	o.DeleteUnregistered = true
+	report, err = CollectOrphanFiles(ctx, iDao, store, o, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, report.Deleted)
+	for _, key := range keys {
+		_, err = store.Stat(ctx, key)
+		switch key {
+		case "2024-11-10/lost.png", "2024-11-10/replaced.png", "2024-11-10/replaced.png!small.png":
+			assert.ErrorIs(t, err, storage.ErrNotFound, key)
+		default:
+			assert.NoError(t, err, key)
+		}
+	}
+}
+
+func TestCollectOrphanFiles_recheck(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := storage.NewLocal(root)
+	now := time.Now()
+	old := now.Add(-7 * 24 * time.Hour)
+
+	keys := []string{
+		"2024-11-10/reused.png", // an abandoned avatar reused by an upload an hour ago
+		"2024-11-10/saved.png",  // its form is saved while the collection runs
+		"2024-11-10/ref.png",    // referenced while the collection runs
+		"2024-11-10/lost.png",
+	}
+	for _, key := range keys {
+		assert.NoError(t, store.Put(ctx, key, strings.NewReader("data"), 4, ""))
+		assert.NoError(t, os.Chtimes(filepath.Join(root, key), old, old))
+	}
+	reused := newGCFile(1, "2024-11-10/reused.png", upload.CategoryAvatar, old)
+	reused.UpdatedAt = now.Add(-time.Hour)
+	iDao := &memFileGCDao{
+		files: []*model.File{
+			reused,
+			newGCFile(2, "2024-11-10/saved.png", upload.CategoryAvatar, old),
+			newGCFile(3, "2024-11-10/ref.png", upload.CategoryAvatar, old),
+			newGCFile(4, "2024-11-10/lost.png", upload.CategoryAvatar, old),
+		},
+		refs: map[uint64]int64{},
+		afterRead: func(d *memFileGCDao) {
+			d.paths = append(d.paths, "/uploads/2024-11-10/saved.png")
+			d.refs[3] = 1
+		},
+	}
+
+	o := DefaultFileGCOptions()
+	o.DryRun = false
+	report, err := CollectOrphanFiles(ctx, iDao, store, o, now)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	assert.Equal(t, 1, report.OrphanCount)
+	assert.Equal(t, 1, report.Deleted)
+	assert.Equal(t, []uint64{4}, iDao.deleted)
+	for _, key := range keys {
+		_, err = store.Stat(ctx, key)
+		if key == "2024-11-10/lost.png" {
+			assert.ErrorIs(t, err, storage.ErrNotFound, key)
+		} else {
+			assert.NoError(t, err, key)
+		}
+	}
+}
+
+func TestParseFileGCOptions(t *testing.T) {
+	o, err := ParseFileGCOptions("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultFileGCOptions(), o)
+
+	assert.True(t, o.DryRun)
+	assert.False(t, o.DeleteUnregistered)
+
+	o, err = ParseFileGCOptions(`{"graceHours":72,"categories":["avatar","attachment"],"dryRun":false,"deleteUnregistered":true}`)
+	assert.NoError(t, err)
+	assert.Equal(t, 72, o.GraceHours)
+	assert.False(t, o.DryRun)
+	assert.True(t, o.DeleteUnregistered)
+
+	// the absent options keep the default
+	o, err = ParseFileGCOptions(`{"graceHours":48}`)
+	assert.NoError(t, err)
+	assert.True(t, o.DryRun)
+	assert.Equal(t, []string{upload.CategoryAvatar}, o.Categories)
+
+	_, err = ParseFileGCOptions(`{"graceHours":0}`)
+	assert.Error(t, err)
+	_, err = ParseFileGCOptions(`{`)
+	assert.Error(t, err)
+}
+
+func TestIsUploadKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"2024-11-10/a.png":             true,
+		"2024-11-10/a.png!small.png":   true,
+		"private/2024-11-10/a.pdf":     true,
+		"private/7/2024-11-10/a.pdf":   true,
+		"a.png":                        false,
+		"backup/db.sql":                false,
+		"2024-11-10/":                  false,
+		"2024-11-10/dir/a.png":         false,
+		"private/x/2024-11-10/a.pdf":   false,
+		upload.ChunkKey("abc", 0):      false,
+		"private/7/2024-11-10/a/b.pdf": false,
+	} {
+		assert.Equal(t, want, isUploadKey(key), key)
+	}
+}
+
+func TestUploadKeysIn(t *testing.T) {
+	assert.Equal(t, []string{"2024-11-10/a.png"}, uploadKeysIn("/uploads/2024-11-10/a.png"))
+	assert.Equal(t, []string{"2024-11-10/a.png"}, uploadKeysIn("https://cdn.example.com/uploads/2024-11-10/a.png!small.png?v=1"))
+	assert.Equal(t, []string{"2024-11-10/a.png", "2024-11-10/b.jpg"},
+		uploadKeysIn(`{"logo":"/uploads/2024-11-10/a.png","images":["\/uploads\/2024-11-10\/b.jpg"]}`))
+	assert.Empty(t, uploadKeysIn("https://cdn.example.com/a.png"))
+}
@@ -0,0 +1,25 @@
+// Package task runs the scheduled background jobs, every instance runs them so jobs must be idempotent.
+package task
+
+import (
+	"github.com/go-dev-frame/sponge/pkg/gocron"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+)
+
+// tasks registered by the files of this package
+var tasks []*gocron.Task
+
+// Run start all scheduled tasks
+func Run() error {
+	err := gocron.Init(gocron.WithLog(logger.Get(), true))
+	if err != nil {
+		return err
+	}
+	return gocron.Run(tasks...)
+}
+
+// Stop all scheduled tasks
+func Stop() error {
+	gocron.Stop()
+	return nil
+}
@@ -0,0 +1,57 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-dev-frame/sponge/pkg/gocron"
+	"github.com/go-dev-frame/sponge/pkg/logger"
+
+	"admin/internal/dao"
+	"admin/internal/database"
+	"admin/internal/pkg/storage"
+	"admin/internal/pkg/upload"
+)
+
+func init() {
+	tasks = append(tasks, &gocron.Task{
+		Name:     "cleanUploadSessions",
+		TimeSpec: gocron.EveryMinute(10),
+		Fn: func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+			defer cancel()
+			n, err := CleanUploadSessions(ctx, dao.NewUploadSessionDao(database.GetDB()), database.GetStorage(), time.Now())
+			if err != nil {
+				logger.Error("CleanUploadSessions error", logger.Err(err), logger.Int("cleaned", n))
+				return
+			}
+			if n > 0 {
+				logger.Info("expired upload sessions were cleaned", logger.Int("cleaned", n))
+			}
+		},
+	})
+}
+
+// CleanUploadSessions delete upload sessions that expired before now together with their chunks,
+// return the number of sessions that were deleted
+func CleanUploadSessions(ctx context.Context, iDao dao.UploadSessionDao, store storage.Storage, now time.Time) (int, error) {
+	cleaned := 0
+	for {
+		sessions, err := iDao.GetExpired(ctx, now, 100)
+		if err != nil {
+			return cleaned, err
+		}
+		for _, session := range sessions {
+			if err = upload.RemoveChunks(ctx, store, session.UploadID); err != nil {
+				return cleaned, err
+			}
+			if err = iDao.DeleteByUploadID(ctx, session.UploadID); err != nil {
+				return cleaned, err
+			}
+			cleaned++
+		}
+		if len(sessions) < 100 {
+			return cleaned, nil
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package task
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"admin/internal/model"
+	"admin/internal/pkg/storage"
+	"admin/internal/pkg/upload"
+)
+
+type memUploadSessionDao struct {
+	sessions map[string]*model.UploadSession
+}
+
+func (d *memUploadSessionDao) Create(_ context.Context, table *model.UploadSession) error {
+	d.sessions[table.UploadID] = table
+	return nil
+}
+
+func (d *memUploadSessionDao) GetByUploadID(_ context.Context, uploadID string) (*model.UploadSession, error) {
+	return d.sessions[uploadID], nil
+}
+
+func (d *memUploadSessionDao) DeleteByUploadID(_ context.Context, uploadID string) error {
+	delete(d.sessions, uploadID)
+	return nil
+}
+
+func (d *memUploadSessionDao) GetExpired(_ context.Context, before time.Time, limit int) ([]*model.UploadSession, error) {
+	var records []*model.UploadSession
+	for _, v := range d.sessions {
+		if v.ExpiredAt.Before(before) && len(records) < limit {
+			records = append(records, v)
+		}
+	}
+	return records, nil
+}
+
+func TestCleanUploadSessions(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	store := storage.NewLocal(t.TempDir())
+	iDao := &memUploadSessionDao{sessions: map[string]*model.UploadSession{}}
+
+	_ = iDao.Create(ctx, &model.UploadSession{UploadID: "expired", ExpiredAt: now.Add(-time.Minute)})
+	_ = iDao.Create(ctx, &model.UploadSession{UploadID: "active", ExpiredAt: now.Add(time.Hour)})
+	for _, id := range []string{"expired", "active"} {
+		err := store.Put(ctx, upload.ChunkKey(id, 0), strings.NewReader("chunk"), 5, "")
+		assert.NoError(t, err)
+	}
+
+	n, err := CleanUploadSessions(ctx, iDao, store, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Len(t, iDao.sessions, 1)
+
+	_, err = store.Stat(ctx, upload.ChunkKey("expired", 0))
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	_, err = store.Stat(ctx, upload.ChunkKey("active", 0))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,39 @@
+package types
+
+import (
+	"time"
+)
+
+// AuditLogObjDetail detail
+type AuditLogObjDetail struct {
+	ID uint64 `json:"id"` // convert to uint64 id
+
+	CreatedAt time.Time `json:"createdAt"` // 操作时间
+	AccountID uint64    `json:"accountID"` // 操作人
+	Action    string    `json:"action"`    // 操作
+	Target    string    `json:"target"`    // 操作对象
+	Detail    string    `json:"detail"`    // 结果或错误信息
+	IP        string    `json:"ip"`        // 客户端IP
+}
+
+// ListAuditLogsRequest request params
+type ListAuditLogsRequest struct {
+	Page     int    `json:"page,omitempty" form:"page" binding:""`         // 分页
+	PageSize int    `json:"pageSize,omitempty" form:"pageSize" binding:""` // 分页大小
+	Sort     string `json:"sort,omitempty" form:"sort" binding:""`         // 排序, 默认 -id
+
+	StartTime string `json:"startTime,omitempty" form:"startTime" binding:""` // 开始时间
+	EndTime   string `json:"endTime,omitempty" form:"endTime" binding:""`     // 结束时间
+	Action    string `json:"action,omitempty" form:"action" binding:""`       // 操作前缀, 如 cache.
+	AccountID uint64 `json:"accountID,omitempty" form:"accountID" binding:""` // 操作人
+}
+
+// ListAuditLogsReply only for api docs
+type ListAuditLogsReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		List  []AuditLogObjDetail `json:"list"`
+		Total int                 `json:"total"`
+	} `json:"data"` // return data
+}
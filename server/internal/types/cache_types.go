@@ -0,0 +1,75 @@
+package types
+
+import (
+	"encoding/json"
+)
+
+// CacheEntry a key of the cache
+type CacheEntry struct {
+	Key         string          `json:"key"`                   // 缓存key
+	TTL         int64           `json:"ttl"`                   // 剩余有效期(秒), 0表示不过期或未知
+	Placeholder bool            `json:"placeholder,omitempty"` // 记录不存在的占位值
+	Value       json.RawMessage `json:"value,omitempty"`       // 缓存值
+}
+
+// CacheNamespace a namespace of the cache
+type CacheNamespace struct {
+	Name     string `json:"name"`     // 名称
+	Prefix   string `json:"prefix"`   // key前缀
+	Listable bool   `json:"listable"` // key是否可以列出, 内存缓存不能列出
+	Count    int64  `json:"count"`    // key数量, -1表示未知
+}
+
+// CacheNamespaceDetail a namespace and its sample entries
+type CacheNamespaceDetail struct {
+	CacheNamespace
+	Samples []*CacheEntry `json:"samples"` // 示例
+}
+
+// GetCacheNamespaceRequest request params
+type GetCacheNamespaceRequest struct {
+	Limit int `json:"limit,omitempty" form:"limit" binding:"gte=0,lte=100"` // 示例数量, 默认10
+}
+
+// EvictCacheKeyRequest request params
+type EvictCacheKeyRequest struct {
+	Key string `json:"key" form:"key" binding:"required"` // 缓存key, 必须属于该命名空间
+}
+
+// EvictCacheReply result of an eviction
+type EvictCacheReply struct {
+	Deleted int64 `json:"deleted"` // 删除的key数量, -1表示清空了整个内存缓存
+}
+
+// WarmCacheReply result of warming
+type WarmCacheReply struct {
+	Loaded int `json:"loaded"` // 从数据库加载的记录数
+}
+
+// ListCacheNamespacesReply only for api docs
+type ListCacheNamespacesReply struct {
+	Code int               `json:"code"` // return code
+	Msg  string            `json:"msg"`  // return information description
+	Data []*CacheNamespace `json:"data"` // return data
+}
+
+// GetCacheNamespaceReply only for api docs
+type GetCacheNamespaceReply struct {
+	Code int                  `json:"code"` // return code
+	Msg  string               `json:"msg"`  // return information description
+	Data CacheNamespaceDetail `json:"data"` // return data
+}
+
+// EvictCacheReplyDoc only for api docs
+type EvictCacheReplyDoc struct {
+	Code int             `json:"code"` // return code
+	Msg  string          `json:"msg"`  // return information description
+	Data EvictCacheReply `json:"data"` // return data
+}
+
+// WarmCacheReplyDoc only for api docs
+type WarmCacheReplyDoc struct {
+	Code int            `json:"code"` // return code
+	Msg  string         `json:"msg"`  // return information description
+	Data WarmCacheReply `json:"data"` // return data
+}
@@ -2,6 +2,8 @@ package types
 
 import (
 	"time"
+
+	"admin/internal/pkg/confval"
 )
 
 var _ time.Time
@@ -10,32 +12,44 @@ var _ time.Time
 
 // CreateConfigRequest request params
 type CreateConfigRequest struct {
-	Name        string `json:"name" binding:""`        // 配置名称
-	Description string `json:"description" binding:""` // 描述
-	Key         string `json:"key" binding:""`         // 配置键
-	Value       string `json:"value" binding:""`       // 配置值
+	Name        string               `json:"name" binding:""`                                                           // 配置名称
+	Description string               `json:"description" binding:""`                                                    // 描述
+	Key         string               `json:"key" binding:""`                                                            // 配置键
+	Value       string               `json:"value" binding:""`                                                          // 配置值
+	Type        confval.Type         `json:"type" binding:"omitempty,oneof=string int bool float json url enum secret"` // 值类型, 默认string
+	Constraints *confval.Constraints `json:"constraints" binding:""`                                                    // 值约束
+	Group       string               `json:"group" binding:"max=32"`                                                    // 分组
+	Public      *bool                `json:"public" binding:""`                                                         // 公开, secret类型不能公开
 }
 
 // UpdateConfigByIDRequest request params
 type UpdateConfigByIDRequest struct {
 	ID uint64 `json:"id" binding:""` // uint64 id
 
-	Name        string `json:"name" binding:""`        // 配置名称
-	Description string `json:"description" binding:""` // 描述
-	Key         string `json:"key" binding:""`         // 配置键
-	Value       string `json:"value" binding:""`       // 配置值
+	Name        string               `json:"name" binding:""`                                                           // 配置名称
+	Description string               `json:"description" binding:""`                                                    // 描述
+	Key         string               `json:"key" binding:""`                                                            // 配置键
+	Value       string               `json:"value" binding:""`                                                          // 配置值, secret类型提交******表示不修改
+	Type        confval.Type         `json:"type" binding:"omitempty,oneof=string int bool float json url enum secret"` // 值类型
+	Constraints *confval.Constraints `json:"constraints" binding:""`                                                    // 值约束
+	Group       string               `json:"group" binding:"max=32"`                                                    // 分组
+	Public      *bool                `json:"public" binding:""`                                                         // 公开, secret类型不能公开
 }
 
 // ConfigObjDetail detail
 type ConfigObjDetail struct {
 	ID uint64 `json:"id"` // convert to uint64 id
 
-	CreatedAt   time.Time `json:"createdAt"`   // 创建时间
-	UpdatedAt   time.Time `json:"updatedAt"`   // 更新时间
-	Name        string    `json:"name"`        // 配置名称
-	Description string    `json:"description"` // 描述
-	Key         string    `json:"key"`         // 配置键
-	Value       string    `json:"value"`       // 配置值
+	CreatedAt   time.Time            `json:"createdAt"`   // 创建时间
+	UpdatedAt   time.Time            `json:"updatedAt"`   // 更新时间
+	Name        string               `json:"name"`        // 配置名称
+	Description string               `json:"description"` // 描述
+	Key         string               `json:"key"`         // 配置键
+	Value       string               `json:"value"`       // 配置值, secret类型返回******
+	Type        confval.Type         `json:"type"`        // 值类型
+	Constraints *confval.Constraints `json:"constraints"` // 值约束
+	Group       string               `json:"group"`       // 分组
+	Public      bool                 `json:"public"`      // 公开
 }
 
 // CreateConfigReply only for api docs
@@ -73,6 +87,7 @@ type ListConfigsRequest struct {
 	StartTime string `json:"startTime,omitempty" form:"startTime" binding:""` // 开始时间
 	EndTime   string `json:"endTime,omitempty" form:"endTime" binding:""`     // 结束时间
 	Name      string `json:"name,omitempty" form:"name" binding:""`           // 关键字
+	Group     string `json:"group,omitempty" form:"group" binding:""`         // 分组
 }
 
 // ListConfigsReply only for api docs
@@ -92,3 +107,19 @@ type ListDictReply struct {
 		Dict map[string][]Options `json:"dict"`
 	} `json:"data"` // return data
 }
+
+// ConfigValues config values by key, values are converted by their types, e.g. int to number, json to object
+type ConfigValues map[string]interface{}
+
+// GetConfigGroupReply only for api docs
+type GetConfigGroupReply struct {
+	Code int          `json:"code"` // return code
+	Msg  string       `json:"msg"`  // return information description
+	Data ConfigValues `json:"data"` // return data
+}
+
+// PublicConfigs public configs and the ETag of them, cached until a config changes
+type PublicConfigs struct {
+	ETag    string       `json:"etag"`
+	Configs ConfigValues `json:"configs"`
+}
@@ -0,0 +1,90 @@
+package types
+
+import (
+	"time"
+)
+
+var _ time.Time
+
+// Tip: suggested filling in the binding rules https://github.com/go-playground/validator in request struct fields tag.
+
+// CreateDictItemRequest request params
+type CreateDictItemRequest struct {
+	DictID  uint64    `json:"dictID" binding:"required"`                                             // 字典ID
+	Label   string    `json:"label" binding:"required,max=64"`                                       // 标签
+	Value   string    `json:"value" binding:"required,max=64"`                                       // 值, 字典值类型为int时必须是整数
+	Sort    int       `json:"sort" binding:""`                                                       // 排序, 升序
+	Status  *int      `json:"status" binding:"omitempty,enum=base_status"`                           // 状态, 默认正常
+	Color   string    `json:"color" binding:"max=32"`                                                // 颜色
+	TagType string    `json:"tagType" binding:"omitempty,oneof=primary success info warning danger"` // 标签类型
+	Labels  LocalText `json:"labels" binding:"omitempty,dive,keys,oneof=zh-CN en,endkeys,max=64"`    // 其他语言的标签
+}
+
+// UpdateDictItemByIDRequest request params
+type UpdateDictItemByIDRequest struct {
+	ID uint64 `json:"id" binding:""` // uint64 id
+
+	Label   string    `json:"label" binding:"max=64"`                                                // 标签
+	Value   string    `json:"value" binding:"max=64"`                                                // 值, 字典值类型为int时必须是整数
+	Sort    int       `json:"sort" binding:""`                                                       // 排序
+	Status  *int      `json:"status" binding:"omitempty,enum=base_status"`                           // 状态
+	Color   string    `json:"color" binding:"max=32"`                                                // 颜色
+	TagType string    `json:"tagType" binding:"omitempty,oneof=primary success info warning danger"` // 标签类型
+	Labels  LocalText `json:"labels" binding:"omitempty,dive,keys,oneof=zh-CN en,endkeys,max=64"`    // 其他语言的标签
+}
+
+// DictItemObjDetail detail
+type DictItemObjDetail struct {
+	ID uint64 `json:"id"` // convert to uint64 id
+
+	CreatedAt time.Time `json:"createdAt"` // 创建时间
+	UpdatedAt time.Time `json:"updatedAt"` // 更新时间
+	DictID    uint64    `json:"dictID"`    // 字典ID
+	Label     string    `json:"label"`     // 标签
+	Value     string    `json:"value"`     // 值
+	Sort      int       `json:"sort"`      // 排序
+	Status    int       `json:"status"`    // 状态
+	Color     string    `json:"color"`     // 颜色
+	TagType   string    `json:"tagType"`   // 标签类型
+	Labels    LocalText `json:"labels"`    // 其他语言的标签
+}
+
+// CreateDictItemReply only for api docs
+type CreateDictItemReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		ID uint64 `json:"id"` // id
+	} `json:"data"` // return data
+}
+
+// DeleteDictItemByIDReply only for api docs
+type DeleteDictItemByIDReply struct {
+	Result
+}
+
+// UpdateDictItemByIDReply only for api docs
+type UpdateDictItemByIDReply struct {
+	Result
+}
+
+// GetDictItemByIDReply only for api docs
+type GetDictItemByIDReply struct {
+	Code int               `json:"code"` // return code
+	Msg  string            `json:"msg"`  // return information description
+	Data DictItemObjDetail `json:"data"` // return data
+}
+
+// ListDictItemsRequest request params
+type ListDictItemsRequest struct {
+	DictID uint64 `json:"dictID" form:"dictID" binding:"required"` // 字典ID
+}
+
+// ListDictItemsReply only for api docs
+type ListDictItemsReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		List []DictItemObjDetail `json:"list"`
+	} `json:"data"` // return data
+}
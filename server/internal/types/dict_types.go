@@ -0,0 +1,95 @@
+package types
+
+import (
+	"time"
+)
+
+var _ time.Time
+
+// Tip: suggested filling in the binding rules https://github.com/go-playground/validator in request struct fields tag.
+
+// CreateDictRequest request params
+type CreateDictRequest struct {
+	Name        string `json:"name" binding:"required,max=64"`                 // 字典名称
+	Code        string `json:"code" binding:"required,max=64"`                 // 字典编码, 不能与代码枚举重名
+	ValueType   string `json:"valueType" binding:"omitempty,oneof=string int"` // 值类型, 默认string
+	Description string `json:"description" binding:"max=255"`                  // 描述
+	Status      *int   `json:"status" binding:"omitempty,enum=base_status"`    // 状态, 默认正常
+}
+
+// UpdateDictByIDRequest request params
+type UpdateDictByIDRequest struct {
+	ID uint64 `json:"id" binding:""` // uint64 id
+
+	Name        string `json:"name" binding:"max=64"`                          // 字典名称
+	Code        string `json:"code" binding:"max=64"`                          // 字典编码, 不能与代码枚举重名
+	ValueType   string `json:"valueType" binding:"omitempty,oneof=string int"` // 值类型
+	Description string `json:"description" binding:"max=255"`                  // 描述
+	Status      *int   `json:"status" binding:"omitempty,enum=base_status"`    // 状态
+}
+
+// DictObjDetail detail
+type DictObjDetail struct {
+	ID uint64 `json:"id"` // convert to uint64 id
+
+	CreatedAt   time.Time            `json:"createdAt"`       // 创建时间
+	UpdatedAt   time.Time            `json:"updatedAt"`       // 更新时间
+	Name        string               `json:"name"`            // 字典名称
+	Code        string               `json:"code"`            // 字典编码
+	ValueType   string               `json:"valueType"`       // 值类型
+	Description string               `json:"description"`     // 描述
+	Status      int                  `json:"status"`          // 状态
+	Items       []*DictItemObjDetail `json:"items,omitempty"` // 字典项, 只有详情返回
+}
+
+// CreateDictReply only for api docs
+type CreateDictReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		ID uint64 `json:"id"` // id
+	} `json:"data"` // return data
+}
+
+// DeleteDictByIDReply only for api docs
+type DeleteDictByIDReply struct {
+	Result
+}
+
+// UpdateDictByIDReply only for api docs
+type UpdateDictByIDReply struct {
+	Result
+}
+
+// GetDictByIDReply only for api docs
+type GetDictByIDReply struct {
+	Code int           `json:"code"` // return code
+	Msg  string        `json:"msg"`  // return information description
+	Data DictObjDetail `json:"data"` // return data
+}
+
+// ListDictsRequest request params
+type ListDictsRequest struct {
+	Page     int    `json:"page,omitempty" form:"page" binding:""`         // 分页
+	PageSize int    `json:"pageSize,omitempty" form:"pageSize" binding:""` // 分页大小
+	Sort     string `json:"sort,omitempty" form:"sort" binding:""`         // 排序
+
+	Name   string `json:"name,omitempty" form:"name" binding:""`     // 关键字, 匹配名称或编码
+	Status *int   `json:"status,omitempty" form:"status" binding:""` // 状态
+}
+
+// ListDictsReply only for api docs
+type ListDictsReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		List  []DictObjDetail `json:"list"`
+		Total int64           `json:"total"`
+	} `json:"data"` // return data
+}
+
+// DictItemOther the other of the options of a database dictionary item
+type DictItemOther struct {
+	Color   string `json:"color,omitempty"`   // 颜色
+	TagType string `json:"tagType,omitempty"` // 标签类型
+}
@@ -0,0 +1,118 @@
+package types
+
+import (
+	"time"
+)
+
+var _ time.Time
+
+// Tip: suggested filling in the binding rules https://github.com/go-playground/validator in request struct fields tag.
+
+// FileObjDetail detail
+type FileObjDetail struct {
+	ID uint64 `json:"id"` // convert to uint64 id
+
+	CreatedAt  time.Time `json:"createdAt"`  // 创建时间
+	UpdatedAt  time.Time `json:"updatedAt"`  // 更新时间
+	UploaderID uint64    `json:"uploaderID"` // 上传人
+	Name       string    `json:"name"`       // 原始文件名
+	Size       int64     `json:"size"`       // 文件大小(字节)
+	MimeType   string    `json:"mimeType"`   // MIME类型
+	Hash       string    `json:"hash"`       // SHA-256
+	StorageKey string    `json:"storageKey"` // 存储key
+	Path       string    `json:"path"`       // path
+	Url        string    `json:"url"`        // url
+	RefCount   int64     `json:"refCount"`   // 引用次数
+	Private    bool      `json:"private"`    // 私有文件, url为当前账号的签名链接
+
+	Variants map[string]string `json:"variants,omitempty"` // 图片变体url, key为变体后缀
+}
+
+// DeleteFileByIDReply only for api docs
+type DeleteFileByIDReply struct {
+	Result
+}
+
+// GetFileByIDReply only for api docs
+type GetFileByIDReply struct {
+	Code int           `json:"code"` // return code
+	Msg  string        `json:"msg"`  // return information description
+	Data FileObjDetail `json:"data"` // return data
+}
+
+// ListFilesRequest request params
+type ListFilesRequest struct {
+	Page     int    `json:"page,omitempty" form:"page" binding:""`         // 分页
+	PageSize int    `json:"pageSize,omitempty" form:"pageSize" binding:""` // 分页大小
+	Sort     string `json:"sort,omitempty" form:"sort" binding:""`         // 排序
+
+	StartTime  string `json:"startTime,omitempty" form:"startTime" binding:""`   // 开始时间
+	EndTime    string `json:"endTime,omitempty" form:"endTime" binding:""`       // 结束时间
+	Name       string `json:"name,omitempty" form:"name" binding:""`             // 文件名关键字
+	MimeType   string `json:"mimeType,omitempty" form:"mimeType" binding:""`     // MIME类型前缀, 如 image/
+	UploaderID uint64 `json:"uploaderID,omitempty" form:"uploaderID" binding:""` // 上传人
+}
+
+// ListFilesReply only for api docs
+type ListFilesReply struct {
+	Code int    `json:"code"` // return code
+	Msg  string `json:"msg"`  // return information description
+	Data struct {
+		List  []FileObjDetail `json:"list"`
+		Total int             `json:"total"`
+	} `json:"data"` // return data
+}
+
+// SignFileURLRequest request params
+type SignFileURLRequest struct {
+	Expires   int    `json:"expires,omitempty" form:"expires" binding:"gte=0"` // 有效期(秒), 默认1800, 最长7天
+	AccountID uint64 `json:"accountID,omitempty" form:"accountID" binding:""`  // 绑定的账号ID, 只有该账号登录后才能下载, 0表示不绑定
+}
+
+// SignedURL signed download url
+type SignedURL struct {
+	Url       string    `json:"url"`       // 签名下载链接
+	ExpiredAt time.Time `json:"expiredAt"` // 过期时间
+}
+
+// SignFileURLReply only for api docs
+type SignFileURLReply struct {
+	Code int       `json:"code"` // return code
+	Msg  string    `json:"msg"`  // return information description
+	Data SignedURL `json:"data"` // return data
+}
+
+// RunFileGCRequest request params
+type RunFileGCRequest struct {
+	DryRun *bool `json:"dryRun" form:"dryRun" binding:""` // 只生成报告不删除, 默认true
+}
+
+// FileGCItem an orphaned object
+type FileGCItem struct {
+	Key    string `json:"key"`              // 存储key
+	Size   int64  `json:"size"`             // 大小(字节)
+	FileID uint64 `json:"fileID,omitempty"` // 文件ID, 未登记的文件为空
+	Reason string `json:"reason"`           // unregistered: 未登记且没有字段引用, unreferenced: 已登记但没有引用
+}
+
+// FileGCReport report of the orphaned upload garbage collection
+type FileGCReport struct {
+	DryRun      bool         `json:"dryRun"`      // 只生成报告不删除
+	GraceHours  int          `json:"graceHours"`  // 宽限期(小时)
+	Categories  []string     `json:"categories"`  // 回收的上传类别
+	StartedAt   time.Time    `json:"startedAt"`   // 开始时间
+	FinishedAt  time.Time    `json:"finishedAt"`  // 结束时间
+	Scanned     int          `json:"scanned"`     // 扫描的对象数
+	OrphanCount int          `json:"orphanCount"` // 孤立对象数
+	OrphanBytes int64        `json:"orphanBytes"` // 孤立对象大小(字节)
+	Deleted     int          `json:"deleted"`     // 已删除的对象数
+	Orphans     []FileGCItem `json:"orphans"`     // 孤立对象, 最多列出1000个
+	Errors      []string     `json:"errors"`      // 删除失败的错误信息
+}
+
+// FileGCReportReply only for api docs
+type FileGCReportReply struct {
+	Code int          `json:"code"` // return code
+	Msg  string       `json:"msg"`  // return information description
+	Data FileGCReport `json:"data"` // return data
+}
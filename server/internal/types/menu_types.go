@@ -10,38 +10,44 @@ var _ time.Time
 
 // CreateMenuRequest request params
 type CreateMenuRequest struct {
-	ParentID   int       `json:"parentId" binding:""`   // 父级
-	Name       string    `json:"name" binding:""`       // 菜单名称
-	Type       string    `json:"type" binding:""`       // 菜单类型(CATALOG-菜单；MENU-目录；BUTTON-按钮；EXTLINK-外链)
-	Path       string    `json:"routePath" binding:""`  // 路由路径
-	Component  string    `json:"component" binding:""`  // 组件路径(vue页面完整路径，省略.vue后缀)
-	Perm       string    `json:"perm" binding:""`       // 权限标识
-	Sort       int       `json:"sort" binding:""`       // 排序
-	Visible    int       `json:"visible" binding:""`    // 显示状态
-	Icon       string    `json:"icon" binding:""`       // 菜单图标
-	Redirect   string    `json:"redirect" binding:""`   // 跳转路径
-	AlwaysShow int       `json:"alwaysShow" binding:""` // 始终显示
-	KeepAlive  int       `json:"keepAlive" binding:""`  // 始终显示
-	Params     LocalJSON `json:"params" binding:""`     // 路由参数
+	ParentID   int       `json:"parentId" binding:""`                                                // 父级
+	Name       string    `json:"name" binding:""`                                                    // 菜单名称
+	Type       string    `json:"type" binding:""`                                                    // 菜单类型(CATALOG-菜单；MENU-目录；BUTTON-按钮；EXTLINK-外链)
+	Path       string    `json:"routePath" binding:""`                                               // 路由路径
+	Component  string    `json:"component" binding:""`                                               // 组件路径(vue页面完整路径，省略.vue后缀)
+	Perm       string    `json:"perm" binding:""`                                                    // 权限标识
+	Sort       int       `json:"sort" binding:""`                                                    // 排序
+	Visible    int       `json:"visible" binding:""`                                                 // 显示状态
+	Icon       string    `json:"icon" binding:""`                                                    // 菜单图标
+	Redirect   string    `json:"redirect" binding:""`                                                // 跳转路径
+	AlwaysShow int       `json:"alwaysShow" binding:""`                                              // 始终显示
+	KeepAlive  int       `json:"keepAlive" binding:""`                                               // 始终显示
+	Params     LocalJSON `json:"params" binding:""`                                                  // 路由参数
+	Titles     LocalText `json:"titles" binding:"omitempty,dive,keys,oneof=zh-CN en,endkeys,max=32"` // 其他语言的菜单名称
+
+	ButtonPermPrefix string   `json:"buttonPermPrefix" binding:"omitempty,max=200"`                        // 生成按钮的权限标识前缀, 如 sys:config 或 sys:config:*, 只用于MENU
+	Buttons          []string `json:"buttons" binding:"omitempty,unique,dive,oneof=add edit delete query"` // 生成的按钮, 为空时生成全部: add, edit, delete, query
+	GrantRoleIDs     []uint64 `json:"grantRoleIds" binding:"omitempty,unique,dive,min=1"`                  // 把菜单和生成的按钮分配给这些角色
 }
 
 // UpdateMenuByIDRequest request params
 type UpdateMenuByIDRequest struct {
 	ID uint64 `json:"id" binding:""` // uint64 id
 
-	ParentID   int       `json:"parentId" binding:""`   // 父级
-	Name       string    `json:"name" binding:""`       // 菜单名称
-	Type       string    `json:"type" binding:""`       // 菜单类型(CATALOG-菜单；MENU-目录；BUTTON-按钮；EXTLINK-外链)
-	Path       string    `json:"routePath" binding:""`  // 路由路径
-	Component  string    `json:"component" binding:""`  // 组件路径(vue页面完整路径，省略.vue后缀)
-	Perm       string    `json:"perm" binding:""`       // 权限标识
-	Sort       int       `json:"sort" binding:""`       // 排序
-	Visible    *int      `json:"visible" binding:""`    // 显示状态
-	Icon       string    `json:"icon" binding:""`       // 菜单图标
-	Redirect   string    `json:"redirect" binding:""`   // 跳转路径
-	AlwaysShow int       `json:"alwaysShow" binding:""` // 始终显示
-	KeepAlive  int       `json:"keepAlive" binding:""`  // 始终显示
-	Params     LocalJSON `json:"params" binding:""`     // 路由参数
+	ParentID   int       `json:"parentId" binding:""`                                                // 父级
+	Name       string    `json:"name" binding:""`                                                    // 菜单名称
+	Type       string    `json:"type" binding:""`                                                    // 菜单类型(CATALOG-菜单；MENU-目录；BUTTON-按钮；EXTLINK-外链)
+	Path       string    `json:"routePath" binding:""`                                               // 路由路径
+	Component  string    `json:"component" binding:""`                                               // 组件路径(vue页面完整路径，省略.vue后缀)
+	Perm       string    `json:"perm" binding:""`                                                    // 权限标识
+	Sort       int       `json:"sort" binding:""`                                                    // 排序
+	Visible    *int      `json:"visible" binding:""`                                                 // 显示状态
+	Icon       string    `json:"icon" binding:""`                                                    // 菜单图标
+	Redirect   string    `json:"redirect" binding:""`                                                // 跳转路径
+	AlwaysShow int       `json:"alwaysShow" binding:""`                                              // 始终显示
+	KeepAlive  int       `json:"keepAlive" binding:""`                                               // 始终显示
+	Params     LocalJSON `json:"params" binding:""`                                                  // 路由参数
+	Titles     LocalText `json:"titles" binding:"omitempty,dive,keys,oneof=zh-CN en,endkeys,max=32"` // 其他语言的菜单名称
 }
 
 // MenuObjDetail detail
@@ -64,6 +70,7 @@ type MenuObjDetail struct {
 	AlwaysShow int       `json:"alwaysShow"` // 始终显示
 	KeepAlive  int       `json:"keepAlive"`  // 始终显示
 	Params     LocalJSON `json:"params"`     // 路由参数
+	Titles     LocalText `json:"titles"`     // 其他语言的菜单名称
 }
 
 // MenuObjPage page
@@ -89,10 +96,42 @@ type CreateMenuReply struct {
 	Code int    `json:"code"` // return code
 	Msg  string `json:"msg"`  // return information description
 	Data struct {
-		ID uint64 `json:"id"` // id
+		ID        uint64   `json:"id"`                  // id
+		ButtonIDs []uint64 `json:"buttonIds,omitempty"` // 生成的按钮的id
 	} `json:"data"` // return data
 }
 
+// DeleteMenuByIDRequest request params
+type DeleteMenuByIDRequest struct {
+	Cascade bool `json:"cascade" form:"cascade" binding:""` // 同时删除子孙菜单和角色的分配, 否则有依赖时拒绝删除
+}
+
+// MenuDependent a descendant of the deleted menus
+type MenuDependent struct {
+	ID       uint64 `json:"id"`       // 菜单ID
+	ParentID uint64 `json:"parentId"` // 父级
+	Name     string `json:"name"`     // 菜单名称
+	Type     string `json:"type"`     // 菜单类型
+}
+
+// MenuRoleDependent a role the deleted menus or their descendants are assigned to
+type MenuRoleDependent struct {
+	RoleID   uint64   `json:"roleId"`   // 角色ID
+	RoleName string   `json:"roleName"` // 角色名称
+	MenuIDs  []uint64 `json:"menuIds"`  // 分配给角色的菜单ID
+}
+
+// MenuDependents the descendants and the role assignments of the deleted menus
+type MenuDependents struct {
+	Descendants []*MenuDependent     `json:"descendants"` // 子孙菜单
+	Roles       []*MenuRoleDependent `json:"roles"`       // 分配了这些菜单的角色
+}
+
+// Empty no descendants and no role assignments
+func (d *MenuDependents) Empty() bool {
+	return len(d.Descendants) == 0 && len(d.Roles) == 0
+}
+
 // DeleteMenuByIDReply only for api docs
 type DeleteMenuByIDReply struct {
 	Result
@@ -123,6 +162,77 @@ type ListMenusRequest struct {
 	EndTime   string `json:"endTime,omitempty" form:"endTime" binding:""`     // 结束时间
 }
 
+// MoveMenuItem a menu moved with its subtree to a position
+type MoveMenuItem struct {
+	ID       uint64 `json:"id" binding:"required"` // 菜单ID
+	ParentID uint64 `json:"parentId"`              // 新的父级, 0 为顶级
+	Index    int    `json:"index" binding:"min=0"` // 在新父级的子级中的位置, 从 0 开始, 超出时放到最后
+}
+
+// MoveMenusRequest request params, the moves are applied in order
+type MoveMenusRequest struct {
+	Moves []*MoveMenuItem `json:"moves" binding:"required,min=1,dive"` // 移动
+}
+
+// MenuManifestItem a menu of the manifest, the children follow their parent. ids are not exported,
+// the catalogs, menus and external links are matched by path and the buttons by perm under their parent when imported
+type MenuManifestItem struct {
+	Name       string              `json:"name" yaml:"name"`                                 // 菜单名称
+	Type       string              `json:"type" yaml:"type"`                                 // 菜单类型(CATALOG/MENU/BUTTON/EXTLINK)
+	Path       string              `json:"path,omitempty" yaml:"path,omitempty"`             // 路由路径
+	Component  string              `json:"component,omitempty" yaml:"component,omitempty"`   // 组件路径
+	Perm       string              `json:"perm,omitempty" yaml:"perm,omitempty"`             // 权限标识
+	Sort       int                 `json:"sort,omitempty" yaml:"sort,omitempty"`             // 排序, 为空时按在父级中的位置
+	Visible    *int                `json:"visible,omitempty" yaml:"visible,omitempty"`       // 显示状态, 默认1
+	Icon       string              `json:"icon,omitempty" yaml:"icon,omitempty"`             // 菜单图标
+	Redirect   string              `json:"redirect,omitempty" yaml:"redirect,omitempty"`     // 跳转路径
+	AlwaysShow int                 `json:"alwaysShow,omitempty" yaml:"alwaysShow,omitempty"` // 始终显示
+	KeepAlive  *int                `json:"keepAlive,omitempty" yaml:"keepAlive,omitempty"`   // 缓存页面, 默认1
+	Params     LocalJSON           `json:"params,omitempty" yaml:"params,omitempty"`         // 路由参数
+	Titles     LocalText           `json:"titles,omitempty" yaml:"titles,omitempty"`         // 其他语言的菜单名称
+	Children   []*MenuManifestItem `json:"children,omitempty" yaml:"children,omitempty"`     // 子级
+}
+
+// MenuManifest the exported menu tree
+type MenuManifest struct {
+	Menus   []*MenuManifestItem `json:"menus" yaml:"menus"`                         // 顶级菜单
+	Skipped []string            `json:"skipped,omitempty" yaml:"skipped,omitempty"` // 无法匹配而未导出的菜单及原因, 导入时忽略
+}
+
+// ExportMenusRequest request params
+type ExportMenusRequest struct {
+	Format string `json:"format" form:"format" binding:"omitempty,oneof=json yaml"` // 文件格式, json 或 yaml, 默认json
+}
+
+// ImportMenusRequest request params, the manifest is the request body
+type ImportMenusRequest struct {
+	Format string `json:"format" form:"format" binding:"omitempty,oneof=json yaml"` // 文件格式, json 或 yaml, 默认json
+	DryRun *bool  `json:"dryRun" form:"dryRun" binding:""`                          // 只生成差异不写入, 默认true
+}
+
+// MenuImportChange a menu created or updated by the import
+type MenuImportChange struct {
+	Key    string   `json:"key"`              // 匹配的键, path:<路由路径> 或 <父级的键> > perm:<权限标识>
+	Name   string   `json:"name"`             // 菜单名称
+	ID     uint64   `json:"id,omitempty"`     // 菜单ID, 试运行时新建的菜单为空
+	Fields []string `json:"fields,omitempty"` // 变更的字段, 只有更新的菜单有
+}
+
+// MenuImportReport the differences between the manifest and the menus, the menus not in the manifest are kept
+type MenuImportReport struct {
+	DryRun    bool                `json:"dryRun"`    // 只生成差异不写入
+	Created   []*MenuImportChange `json:"created"`   // 新建的菜单
+	Updated   []*MenuImportChange `json:"updated"`   // 更新的菜单
+	Unchanged int                 `json:"unchanged"` // 没有变化的菜单数
+}
+
+// ImportMenusReply only for api docs
+type ImportMenusReply struct {
+	Code int              `json:"code"` // return code
+	Msg  string           `json:"msg"`  // return information description
+	Data MenuImportReport `json:"data"` // return data
+}
+
 // OptionMenusRequest request params
 type OptionMenusRequest struct {
 	OnlyParent bool `json:"onlyParent,omitempty" form:"onlyParent" binding:""`
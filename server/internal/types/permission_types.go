@@ -0,0 +1,81 @@
+package types
+
+// RoutePermItem a route and the perm it requires
+type RoutePermItem struct {
+	Method      string `json:"method"`                // 请求方法
+	Path        string `json:"path"`                  // 路由路径
+	Handler     string `json:"handler,omitempty"`     // 处理函数
+	Auth        string `json:"auth"`                  // 认证方式, public: 无需登录, signed: 签名链接, login: 登录即可, perm: 需要权限, 空表示未声明
+	Perm        string `json:"perm,omitempty"`        // 需要的权限标识
+	PermInMenus bool   `json:"permInMenus,omitempty"` // 权限标识是否存在于菜单中
+}
+
+// RoutePermReport the perm coverage of the routes
+type RoutePermReport struct {
+	Routes       []*RoutePermItem `json:"routes"`       // 所有路由
+	Undeclared   []*RoutePermItem `json:"undeclared"`   // 未声明认证方式的路由
+	WithoutPerm  []*RoutePermItem `json:"withoutPerm"`  // 登录即可访问或未声明的路由
+	StaleRules   []*RoutePermItem `json:"staleRules"`   // 已声明但不存在的路由
+	MissingPerms []string         `json:"missingPerms"` // 路由需要但菜单中不存在的权限标识
+	UnusedPerms  []string         `json:"unusedPerms"`  // 菜单中存在但没有路由使用的权限标识
+}
+
+// RoutePermReportReply the perm coverage of the routes
+type RoutePermReportReply struct {
+	Code int              `json:"code"` // return code
+	Msg  string           `json:"msg"`  // return information description
+	Data *RoutePermReport `json:"data"` // return data
+}
+
+// ExplainPermissionRequest request params
+type ExplainPermissionRequest struct {
+	PlatformID uint64 `json:"platformId" form:"platformId" binding:"required,gt=0"` // 管理员ID
+	Perm       string `json:"perm,omitempty" form:"perm" binding:"max=200"`         // 只解释该权限标识
+	MenuID     uint64 `json:"menuId,omitempty" form:"menuId" binding:""`            // 只解释该菜单, 优先于perm
+}
+
+// PermissionRole a role of the platform
+type PermissionRole struct {
+	ID     uint64 `json:"id"`     // 角色ID
+	Name   string `json:"name"`   // 角色名称
+	Code   string `json:"code"`   // 角色编码
+	Status int    `json:"status"` // 状态
+}
+
+// PermissionGrant a role assigned a menu with the perm
+type PermissionGrant struct {
+	RoleID      uint64 `json:"roleId"`      // 角色ID
+	RoleName    string `json:"roleName"`    // 角色名称
+	RoleStatus  int    `json:"roleStatus"`  // 角色状态, 停用的角色同样授予权限
+	MenuID      uint64 `json:"menuId"`      // 菜单ID
+	MenuName    string `json:"menuName"`    // 菜单名称
+	MenuVisible int    `json:"menuVisible"` // 菜单显示状态, 隐藏的菜单同样授予权限
+	MenuDeleted bool   `json:"menuDeleted"` // 菜单已删除, 未解除分配前同样授予权限
+}
+
+// PermissionExplanation whether the platform has a perm and why
+type PermissionExplanation struct {
+	Perm      string             `json:"perm"`             // 权限标识, 按菜单解释且菜单没有权限标识时为空
+	MenuIDs   []uint64           `json:"menuIds"`          // 拥有该权限标识的菜单ID
+	Granted   bool               `json:"granted"`          // 是否拥有
+	GrantedBy []*PermissionGrant `json:"grantedBy"`        // 授予该权限的角色和菜单, 与角色状态和菜单是否显示无关
+	Reason    string             `json:"reason,omitempty"` // 未拥有的原因, not_found: 菜单不存在, not_assigned: 未分配
+}
+
+// PermissionExplanationReport the effective perms of a platform and the explanations
+type PermissionExplanationReport struct {
+	PlatformID     uint64                   `json:"platformId"`     // 管理员ID
+	Username       string                   `json:"username"`       // 账号
+	Status         int                      `json:"status"`         // 管理员状态, 停用时无法登录
+	Roles          []*PermissionRole        `json:"roles"`          // 管理员的角色
+	MissingRoleIDs []uint64                 `json:"missingRoleIds"` // 管理员引用但不存在的角色ID
+	Perms          []string                 `json:"perms"`          // 生效的权限标识
+	Explanations   []*PermissionExplanation `json:"explanations"`   // 权限解释, 未指定权限或菜单时为所有菜单的权限标识
+}
+
+// ExplainPermissionReply only for api docs
+type ExplainPermissionReply struct {
+	Code int                         `json:"code"` // return code
+	Msg  string                      `json:"msg"`  // return information description
+	Data PermissionExplanationReport `json:"data"` // return data
+}
@@ -10,28 +10,29 @@ var _ time.Time
 
 // CreatePlatformRequest request params
 type CreatePlatformRequest struct {
-	Username string   `json:"username" binding:""` // 账号
-	Password string   `json:"password" binding:""` // 密码
-	Nickname string   `json:"nickname" binding:""` // 昵称
-	Mobile   string   `json:"mobile" binding:""`   // 手机号
-	Avatar   string   `json:"avatar" binding:""`   // 头像
-	RoleID   []uint64 `json:"roleId" binding:""`   // 角色
-	Status   *int     `json:"status" binding:""`   // 状态
-	Gender   *int     `json:"gender" binding:""`   // 性别
+	Username string   `json:"username" binding:""`                         // 账号
+	Password string   `json:"password" binding:""`                         // 密码
+	Nickname string   `json:"nickname" binding:""`                         // 昵称
+	Mobile   string   `json:"mobile" binding:""`                           // 手机号
+	Avatar   string   `json:"avatar" binding:""`                           // 头像
+	RoleID   []uint64 `json:"roleId" binding:""`                           // 角色
+	Status   *int     `json:"status" binding:"omitempty,enum=base_status"` // 状态
+	Gender   *int     `json:"gender" binding:"omitempty,enum=gender"`      // 性别
 }
 
 // UpdatePlatformByIDRequest request params
 type UpdatePlatformByIDRequest struct {
 	ID uint64 `json:"id" binding:""` // uint64 id
 
-	Username string   `json:"username" binding:""` // 账号
-	Password string   `json:"password" binding:""` // 密码
-	Nickname string   `json:"nickname" binding:""` // 昵称
-	Mobile   string   `json:"mobile" binding:""`   // 手机号
-	Avatar   string   `json:"avatar" binding:""`   // 头像
-	RoleID   []uint64 `json:"roleId" binding:""`   // 角色
-	Status   *int     `json:"status" binding:""`   // 状态
-	Gender   *int     `json:"gender" binding:""`   // 性别
+	Username string   `json:"username" binding:""`                         // 账号
+	Password string   `json:"password" binding:""`                         // 密码
+	Nickname string   `json:"nickname" binding:""`                         // 昵称
+	Mobile   string   `json:"mobile" binding:""`                           // 手机号
+	Avatar   string   `json:"avatar" binding:""`                           // 头像
+	RoleID   []uint64 `json:"roleId" binding:""`                           // 角色
+	Status   *int     `json:"status" binding:"omitempty,enum=base_status"` // 状态
+	Gender   *int     `json:"gender" binding:"omitempty,enum=gender"`      // 性别
+	Locale   string   `json:"locale" binding:"omitempty,oneof=zh-CN en"`   // 语言偏好
 }
 
 type LoginRequest struct {
@@ -68,6 +69,7 @@ type PlatformObjDetail struct {
 	Status    int           `json:"status"`    // 状态
 	LastTime  LocalDateTime `json:"lastTime"`  // 上次登录时间
 	Gender    int           `json:"gender" `   // 性别
+	Locale    string        `json:"locale"`    // 语言偏好
 }
 
 type Operator struct {
@@ -182,5 +184,6 @@ type ProfileItem struct {
 	Mobile    string        `json:"mobile"`    // 手机号
 	Avatar    string        `json:"avatar"`    // 头像
 	Roles     string        `json:"roleNames"` // 角色组
+	Locale    string        `json:"locale"`    // 语言偏好
 	CreatedAt LocalDateTime `json:"createdAt"` // 创建时间
 }
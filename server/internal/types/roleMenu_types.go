@@ -77,3 +77,23 @@ type ListRoleMenusReply struct {
 		RoleMenus []RoleMenuObjDetail `json:"roleMenus"`
 	} `json:"data"` // return data
 }
+
+// RoleMenuChanges the menus added to and removed from a role by a sync
+type RoleMenuChanges struct {
+	RoleID       uint64   `json:"roleID"`       // 角色ID
+	Added        []uint64 `json:"added"`        // 新增的菜单ID
+	Removed      []uint64 `json:"removed"`      // 移除的菜单ID
+	Deduplicated int      `json:"deduplicated"` // 清理的重复关联数量
+}
+
+// Changed whether the links of the role are changed
+func (c *RoleMenuChanges) Changed() bool {
+	return len(c.Added) > 0 || len(c.Removed) > 0 || c.Deduplicated > 0
+}
+
+// UpdateRoleMenusReply only for api docs
+type UpdateRoleMenusReply struct {
+	Code int             `json:"code"` // return code
+	Msg  string          `json:"msg"`  // return information description
+	Data RoleMenuChanges `json:"data"` // return data
+}
@@ -10,20 +10,20 @@ var _ time.Time
 
 // CreateRoleRequest request params
 type CreateRoleRequest struct {
-	Name   string `json:"name" binding:""`   // 角色名称
-	Code   string `json:"code" binding:""`   // 角色编码
-	Sort   int    `json:"sort" binding:""`   // 排序
-	Status int    `json:"status" binding:""` // 状态
+	Name   string `json:"name" binding:""`                   // 角色名称
+	Code   string `json:"code" binding:""`                   // 角色编码
+	Sort   int    `json:"sort" binding:""`                   // 排序
+	Status int    `json:"status" binding:"enum=base_status"` // 状态
 }
 
 // UpdateRoleByIDRequest request params
 type UpdateRoleByIDRequest struct {
 	ID uint64 `json:"id" binding:""` // uint64 id
 
-	Name   string `json:"name" binding:""`   // 角色名称
-	Code   string `json:"code" binding:""`   // 角色编码
-	Sort   int    `json:"sort" binding:""`   // 排序
-	Status int    `json:"status" binding:""` // 状态
+	Name   string `json:"name" binding:""`                   // 角色名称
+	Code   string `json:"code" binding:""`                   // 角色编码
+	Sort   int    `json:"sort" binding:""`                   // 排序
+	Status int    `json:"status" binding:"enum=base_status"` // 状态
 }
 
 // RoleObjDetail detail
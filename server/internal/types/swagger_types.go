@@ -35,10 +35,26 @@ type Conditions struct {
 }
 
 type Options struct {
-	Label    string      `json:"label"` // 标签
-	Value    interface{} `json:"value"` // 值
-	Other    interface{} `json:"other"`
-	Children []Options   `json:"children"`
+	Label    string            `json:"label"`              // 标签
+	Value    interface{}       `json:"value"`              // 值
+	Name     string            `json:"name,omitempty"`     // 代码枚举的常量名
+	Disabled bool              `json:"disabled,omitempty"` // 不可选
+	Labels   map[string]string `json:"labels,omitempty"`   // 其他语言的标签, key 为 locale
+	Other    interface{}       `json:"other"`
+	Children []Options         `json:"children"`
+}
+
+// LocalizeOptions copies of the options with the labels in the locale, the label is kept if it has no translation
+func LocalizeOptions(options []*Options, locale string) []*Options {
+	items := make([]*Options, 0, len(options))
+	for _, option := range options {
+		item := *option
+		if label := option.Labels[locale]; label != "" {
+			item.Label = label
+		}
+		items = append(items, &item)
+	}
+	return items
 }
 
 // OptionsReply only for api docs
@@ -1,5 +1,9 @@
 package types
 
+import (
+	"time"
+)
+
 type UploadLocalReply struct {
 	Code int        `json:"code"` // return code
 	Msg  string     `json:"msg"`  // return information description
@@ -7,7 +11,39 @@ type UploadLocalReply struct {
 }
 
 type UploadItem struct {
+	ID   uint64 `json:"id"`   // 文件ID
 	Name string `json:"name"` // 文件名称
 	Url  string `json:"url"`  // url
 	Path string `json:"path"` // path
+
+	Variants map[string]string `json:"variants,omitempty"` // 图片变体url, key为变体后缀, 如 small.jpg, small.webp, full.webp
+}
+
+// InitiateUploadRequest request params
+type InitiateUploadRequest struct {
+	Name      string `json:"name" binding:"required"`                    // 原始文件名
+	Size      int64  `json:"size" binding:"gt=0"`                        // 文件大小(字节)
+	Hash      string `json:"hash" binding:"required,len=64,hexadecimal"` // SHA-256
+	ChunkSize int64  `json:"chunkSize" binding:""`                       // 分片大小(字节), 默认5M
+	Category  string `json:"category" binding:""`                        // 上传类别, avatar或attachment, 默认attachment
+	Private   bool   `json:"private" binding:""`                         // 私有文件, 只能通过签名链接下载
+}
+
+// UploadSessionItem upload session
+type UploadSessionItem struct {
+	UploadID   string      `json:"uploadID"`       // 上传ID
+	Name       string      `json:"name"`           // 原始文件名
+	Size       int64       `json:"size"`           // 文件大小(字节)
+	ChunkSize  int64       `json:"chunkSize"`      // 分片大小(字节)
+	ChunkCount int         `json:"chunkCount"`     // 分片数量
+	ExpiredAt  time.Time   `json:"expiredAt"`      // 过期时间
+	Uploaded   []int       `json:"uploaded"`       // 已上传的分片序号
+	File       *UploadItem `json:"file,omitempty"` // 相同内容的文件已存在时直接返回, 无需上传分片
+}
+
+// UploadSessionReply only for api docs
+type UploadSessionReply struct {
+	Code int               `json:"code"` // return code
+	Msg  string            `json:"msg"`  // return information description
+	Data UploadSessionItem `json:"data"` // return data
 }
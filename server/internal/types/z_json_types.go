@@ -26,6 +26,10 @@ func (s LocalStringArray) Value() (driver.Value, error) {
 
 // Scan 从数据库读取数据并解码为 StringArray
 func (s *LocalStringArray) Scan(src interface{}) error {
+	if src == nil {
+		*s = nil
+		return nil
+	}
 	if bytes, ok := src.([]byte); ok {
 		return json.Unmarshal(bytes, s)
 	}
@@ -113,6 +117,10 @@ func (m LocalJSON) Value() (driver.Value, error) {
 
 // Scan 从数据库读取数据并解码为 JSONMap
 func (m *LocalJSON) Scan(src interface{}) error {
+	if src == nil {
+		*m = nil
+		return nil
+	}
 	if bytes, ok := src.([]byte); ok {
 		return json.Unmarshal(bytes, m)
 	}
@@ -121,3 +129,28 @@ func (m *LocalJSON) Scan(src interface{}) error {
 	}
 	return fmt.Errorf("cannot convert %v to JSONMap", src)
 }
+
+// LocalText 多语言文本, key 为 locale, 如 {"en": "Male"}
+type LocalText map[string]string
+
+// Value 将 LocalText 转换为数据库驱动值（存储为 JSON）
+func (t LocalText) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return json.Marshal(map[string]string(t))
+}
+
+// Scan 从数据库读取数据并解码为 LocalText, NULL 为 nil
+func (t *LocalText) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, (*map[string]string)(t))
+	case string:
+		return json.Unmarshal([]byte(v), (*map[string]string)(t))
+	}
+	return fmt.Errorf("cannot convert %v to LocalText", src)
+}
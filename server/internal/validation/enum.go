@@ -0,0 +1,123 @@
+// Package validation registers the custom validation tags of the request params.
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"admin/internal/cache"
+	"admin/internal/types"
+)
+
+// EnumTag check a value against the options of an enum, e.g. binding:"omitempty,enum=gender", both the code enums
+// and the database dictionaries can be used, an unknown enum rejects every value.
+// disabled options are accepted, they are only hidden in the selections of the frontend
+const EnumTag = "enum"
+
+var enumCache cache.EnumCache
+
+// Register the custom tags to the validator, the errors name the fields by their json names
+func Register(v *validator.Validate, enums cache.EnumCache) error {
+	enumCache = enums
+	v.RegisterTagNameFunc(jsonName)
+	return v.RegisterValidation(EnumTag, validateEnum)
+}
+
+func validateEnum(fl validator.FieldLevel) bool {
+	value, ok := fieldString(fl.Field())
+	if !ok {
+		return false
+	}
+	for _, option := range enumOptions(fl.Param()) {
+		if optionString(option.Value) == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Translate the error of binding into a message naming the invalid fields, the allowed values of
+// the enum fields are listed, e.g. gender must be one of 1(男), 2(女), 0(保密)
+func Translate(err error) string {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err.Error()
+	}
+	messages := make([]string, 0, len(errs))
+	for _, fe := range errs {
+		if fe.Tag() != EnumTag {
+			messages = append(messages, fmt.Sprintf("%s failed on %s", fe.Field(), strings.TrimSuffix(fe.Tag()+"="+fe.Param(), "=")))
+			continue
+		}
+		options := enumOptions(fe.Param())
+		if len(options) == 0 {
+			messages = append(messages, fmt.Sprintf("%s: unknown enum %s", fe.Field(), fe.Param()))
+			continue
+		}
+		allowed := make([]string, 0, len(options))
+		for _, option := range options {
+			allowed = append(allowed, fmt.Sprintf("%s(%s)", optionString(option.Value), option.Label))
+		}
+		messages = append(messages, fmt.Sprintf("%s must be one of %s", fe.Field(), strings.Join(allowed, ", ")))
+	}
+	return strings.Join(messages, "; ")
+}
+
+func enumOptions(name string) []*types.Options {
+	if enumCache == nil {
+		return nil
+	}
+	options, err := enumCache.Get(context.Background(), name)
+	if err != nil {
+		return nil
+	}
+	return options
+}
+
+// the values are compared as strings, numbers of the options are float64 after they are decoded from the cache
+func fieldString(field reflect.Value) (string, bool) {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(field.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'f', -1, 64), true
+	case reflect.String:
+		return field.String(), true
+	}
+	return "", false
+}
+
+func optionString(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	}
+	if s, ok := fieldString(reflect.ValueOf(value)); ok {
+		return s
+	}
+	return fmt.Sprint(value)
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		name, _, _ = strings.Cut(field.Tag.Get("form"), ",")
+	}
+	if name == "" {
+		return field.Name
+	}
+	return name
+}
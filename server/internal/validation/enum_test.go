@@ -0,0 +1,82 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-dev-frame/sponge/pkg/gin/validator"
+	"github.com/stretchr/testify/assert"
+
+	"admin/internal/cache"
+	"admin/internal/config"
+	"admin/internal/types"
+)
+
+type enumRequest struct {
+	Gender  *int    `json:"gender" binding:"omitempty,enum=gender"`
+	Status  int     `json:"status" binding:"enum=base_status"`
+	Role    string  `json:"role" binding:"omitempty,enum=role_code"`
+	Channel string  `json:"channel" binding:"omitempty,enum=channel"`
+	Levels  []int64 `json:"levels" binding:"omitempty,dive,enum=level"`
+	Unknown int     `form:"unknown" binding:"omitempty,enum=not_exist"`
+}
+
+func newValidator(t *testing.T) *validator.CustomValidator {
+	_ = config.Init("")
+	cache.SetEnumDictLoader(func(ctx context.Context) (map[string][]*types.Options, error) {
+		return map[string][]*types.Options{
+			"channel": {{Label: "官网", Value: "web"}},
+			"level":   {{Label: "高", Value: 1}, {Label: "低", Value: 2}},
+		}, nil
+	})
+	t.Cleanup(func() { cache.SetEnumDictLoader(nil) })
+
+	v := validator.Init()
+	assert.NoError(t, Register(v.Validate, cache.NewEnumCache()))
+	return v
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestEnum(t *testing.T) {
+	v := newValidator(t)
+
+	valid := []*enumRequest{
+		{},
+		{Gender: intPtr(0), Status: 1, Role: "ADMIN"},
+		{Gender: intPtr(2), Channel: "web", Levels: []int64{1, 2}},
+	}
+	for _, req := range valid {
+		assert.NoError(t, v.ValidateStruct(req), "%+v", req)
+	}
+
+	invalid := []*enumRequest{
+		{Gender: intPtr(3)},
+		{Status: 2},
+		{Role: "admin"},
+		{Channel: "app"},
+		{Levels: []int64{1, 3}},
+		{Unknown: 1},
+	}
+	for _, req := range invalid {
+		assert.Error(t, v.ValidateStruct(req), "%+v", req)
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	v := newValidator(t)
+
+	err := v.ValidateStruct(&enumRequest{Gender: intPtr(3), Status: 5, Unknown: 1})
+	msg := Translate(err)
+	assert.Contains(t, msg, "gender must be one of 1(男), 2(女), 0(保密)")
+	assert.Contains(t, msg, "status must be one of 1(正常), 0(禁用)")
+	assert.Contains(t, msg, "unknown: unknown enum not_exist")
+
+	type required struct {
+		Name string `json:"name" binding:"required,max=3"`
+	}
+	assert.Equal(t, "name failed on required", Translate(v.ValidateStruct(&required{})))
+	assert.Equal(t, "name failed on max=3", Translate(v.ValidateStruct(&required{Name: "abcd"})))
+}
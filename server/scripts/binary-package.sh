@@ -13,9 +13,8 @@ chmod +x ${serviceName}-binary/deploy.sh
 cp -f cmd/${serviceName}/${serviceName} ${serviceName}-binary
 cp -f configs/${serviceName}.yml ${serviceName}-binary/configs
 
-# 复制枚举目录到二进制包中
-go test -v ./internal/pkg/util -run TestEnumSave
-mv enum.json ${serviceName}-binary/
+# 生成枚举文件到二进制包中, 有常量缺少注释时失败
+go run ./cmd/enumgen -json ${serviceName}-binary/enum.json || exit 1
 
 # Clean macOS metadata files and extended attributes
 find ${serviceName}-binary -name "._*" -delete 2>/dev/null || true
@@ -89,12 +89,14 @@ const MenuAPI = {
    * 删除菜单
    *
    * @param id 菜单ID
+   * @param cascade 同时删除子孙菜单和角色的分配, 否则有依赖时拒绝删除
    * @returns 请求结果
    */
-  deleteById(id: string) {
+  deleteById(id: string, cascade?: boolean) {
     return request({
       url: `${MENU_BASE_URL}/${id}`,
       method: "delete",
+      params: { cascade: cascade },
     });
   },
 };
@@ -171,6 +173,12 @@ export interface MenuForm {
   alwaysShow?: number;
   /** 参数 */
   params?: KeyValue[];
+  /** 【菜单】新增时生成按钮的权限标识前缀, 如 sys:config */
+  buttonPermPrefix?: string;
+  /** 【菜单】生成的按钮, 为空时生成全部 */
+  buttons?: ("add" | "edit" | "delete" | "query")[];
+  /** 新增时把菜单和生成的按钮分配给这些角色 */
+  grantRoleIds?: number[];
 }
 
 interface KeyValue {
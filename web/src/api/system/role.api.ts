@@ -37,9 +37,10 @@ const RoleAPI = {
    *
    * @param roleId 角色ID
    * @param data 菜单ID集合
+   * @returns 新增和移除的菜单ID
    */
   updateRoleMenus(roleId: string, data: number[]) {
-    return request({
+    return request<any, RoleMenuChanges>({
       url: `${ROLE_BASE_URL}/${roleId}/menus`,
       method: "put",
       data: data,
@@ -134,3 +135,15 @@ export interface RoleForm {
   /** 角色状态(1-正常；0-停用) */
   status?: number;
 }
+
+/** 角色菜单变更 */
+export interface RoleMenuChanges {
+  /** 角色ID */
+  roleID: number;
+  /** 新增的菜单ID */
+  added: number[];
+  /** 移除的菜单ID */
+  removed: number[];
+  /** 清理的重复关联数量 */
+  deduplicated: number;
+}
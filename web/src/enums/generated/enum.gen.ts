@@ -0,0 +1,62 @@
+// Code generated by enumgen from server/internal/constant/enum. DO NOT EDIT.
+
+/** base_status */
+export const BaseStatus = {
+  /** 正常 */
+  Normal: 1,
+  /** 禁用 */
+  Disable: 0,
+} as const;
+export const BaseStatusOptions = [
+  { value: 1, label: "正常", labels: {"en":"Normal"} },
+  { value: 0, label: "禁用", labels: {"en":"Disabled"} },
+] as const;
+export type BaseStatusValue = (typeof BaseStatusOptions)[number]["value"];
+
+/** gender */
+export const Gender = {
+  /** 男 */
+  Male: 1,
+  /** 女 */
+  Female: 2,
+  /** 保密 */
+  Unknown: 0,
+} as const;
+export const GenderOptions = [
+  { value: 1, label: "男", labels: {"en":"Male"} },
+  { value: 2, label: "女", labels: {"en":"Female"} },
+  { value: 0, label: "保密", labels: {"en":"Secret"} },
+] as const;
+export type GenderValue = (typeof GenderOptions)[number]["value"];
+
+/** role_code */
+export const RoleCode = {
+  /** 管理员 */
+  Admin: "ADMIN",
+} as const;
+export const RoleCodeOptions = [
+  { value: "ADMIN", label: "管理员", labels: {"en":"Administrator"} },
+] as const;
+export type RoleCodeValue = (typeof RoleCodeOptions)[number]["value"];
+
+/** whether */
+export const Whether = {
+  /** 是 */
+  Yes: 1,
+  /** 否 */
+  No: 0,
+} as const;
+export const WhetherOptions = [
+  { value: 1, label: "是", labels: {"en":"Yes"} },
+  { value: 0, label: "否", labels: {"en":"No"} },
+] as const;
+export type WhetherValue = (typeof WhetherOptions)[number]["value"];
+
+/** options of all enums by name, the same as /api/v1/config/dict without the database dictionaries */
+export const EnumOptions = {
+  base_status: BaseStatusOptions,
+  gender: GenderOptions,
+  role_code: RoleCodeOptions,
+  whether: WhetherOptions,
+} as const;
+export type EnumName = keyof typeof EnumOptions;
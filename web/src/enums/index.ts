@@ -9,3 +9,5 @@ export * from "./settings/locale.enum";
 export * from "./settings/device.enum";
 
 export * from "./system/menu.enum";
+
+export * from "./generated/enum.gen";
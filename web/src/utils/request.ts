@@ -1,6 +1,7 @@
 import axios, { type InternalAxiosRequestConfig, type AxiosResponse } from "axios";
 import qs from "qs";
 import { useUserStoreHook } from "@/store/modules/user.store";
+import { useAppStoreHook } from "@/store/modules/app.store";
 import { ResultEnum } from "@/enums/api/result.enum";
 import { getAccessToken, setAccessToken } from "@/utils/auth";
 import router from "@/router";
@@ -22,6 +23,8 @@ service.interceptors.request.use(
     } else {
       delete config.headers.Authorization;
     }
+    // 服务端按该语言返回错误信息、枚举标签和菜单标题
+    config.headers["Accept-Language"] = useAppStoreHook().language;
     return config;
   },
   (error) => Promise.reject(error)